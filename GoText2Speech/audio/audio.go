@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+)
+
+// IsUncompressedAudioFormat returns true if the given AudioFormat contains uncompressed audio samples
+// that can be stored in a WAV container (pcm, linear16, mulaw and alaw).
+func IsUncompressedAudioFormat(audioFormat AudioFormat) bool {
+	_, ok := WAVFormatOf(audioFormat, 0)
+	return ok
+}
+
+// WAVFormatOf returns the format of the samples that the providers return for the given AudioFormat.
+// If sampleRate is 0, DefaultPCMSampleRate is used.
+// The second return value is false if the given AudioFormat is not an uncompressed audio format.
+func WAVFormatOf(audioFormat AudioFormat, sampleRate int32) (Format, bool) {
+	rate := uint32(DefaultPCMSampleRate)
+	if sampleRate > 0 {
+		rate = uint32(sampleRate)
+	}
+	switch audioFormat {
+	case AudioFormatPcm:
+		fallthrough
+	case AudioFormatLinear16:
+		return PCM16Format(rate), true
+	case AudioFormatMulaw:
+		return MuLawFormat(rate), true
+	case AudioFormatAlaw:
+		return ALawFormat(rate), true
+	default:
+		return Format{}, false
+	}
+}
+
+// resolveFormat determines the format of the given segments.
+// If no sample rate is specified, the format of the first segment that has a WAV header is used, because
+// the providers use different default sample rates.
+func resolveFormat(audioFormat AudioFormat, sampleRate int32, segments [][]byte) (Format, error) {
+	format, ok := WAVFormatOf(audioFormat, sampleRate)
+	if !ok {
+		return Format{}, errors.New(fmt.Sprintf("the audio format %s is not an uncompressed audio format", audioFormat))
+	}
+	if sampleRate > 0 {
+		return format, nil
+	}
+	for _, segment := range segments {
+		if HasWAVHeader(segment) {
+			segmentFormat, _, err := ParseWAV(segment)
+			if err != nil {
+				return Format{}, err
+			}
+			return segmentFormat, nil
+		}
+	}
+	return format, nil
+}
+
+// AddContainer makes sure that uncompressed audio data is stored in a WAV container.
+// If the given audio data already has a WAV header, it is returned unchanged.
+// Audio data of other audio formats (e.g. mp3 or ogg) is returned unchanged as well.
+// If sampleRate is 0, DefaultPCMSampleRate is used.
+func AddContainer(audioFormat AudioFormat, sampleRate int32, data []byte) ([]byte, error) {
+	if !IsUncompressedAudioFormat(audioFormat) || HasWAVHeader(data) {
+		return data, nil
+	}
+	format, _ := WAVFormatOf(audioFormat, sampleRate)
+	buf := new(bytes.Buffer)
+	buf.Grow(format.HeaderSize() + len(data))
+	if err := EncodeWAV(buf, format, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RemoveContainer removes the WAV header from uncompressed audio data and returns the raw audio samples.
+// If the given audio data doesn't have a WAV header, it is returned unchanged.
+func RemoveContainer(data []byte) ([]byte, error) {
+	if !HasWAVHeader(data) {
+		return data, nil
+	}
+	_, samples, err := ParseWAV(data)
+	return samples, err
+}
+
+// Concat concatenates the given audio segments, which all need to have the given AudioFormat.
+// Uncompressed audio segments (see IsUncompressedAudioFormat) are joined into a single WAV file, regardless of
+// whether the segments have a WAV header or not. If sampleRate is 0, the sample rate is taken from the first segment
+// that has a WAV header, or DefaultPCMSampleRate if no segment has a WAV header.
+// Segments of other audio formats are concatenated byte by byte. This works for mp3 (which consists of independent
+// frames) and ogg (which results in a chained ogg stream).
+func Concat(audioFormat AudioFormat, sampleRate int32, segments ...[]byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if !IsUncompressedAudioFormat(audioFormat) {
+		for _, segment := range segments {
+			buf.Write(segment)
+		}
+		return buf.Bytes(), nil
+	}
+
+	format, err := resolveFormat(audioFormat, sampleRate, segments)
+	if err != nil {
+		return nil, err
+	}
+	if err = ConcatWAV(buf, format, segments...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,214 @@
+// This file implements reading and writing of RIFF/WAVE containers for uncompressed audio data.
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Encoding is the format tag of the WAVE "fmt " chunk.
+type Encoding uint16
+
+const (
+	// EncodingPCM linear PCM samples (WAVE_FORMAT_PCM)
+	EncodingPCM Encoding = 1
+	// EncodingALaw G.711 A-law samples (WAVE_FORMAT_ALAW)
+	EncodingALaw Encoding = 6
+	// EncodingMuLaw G.711 mu-law samples (WAVE_FORMAT_MULAW)
+	EncodingMuLaw Encoding = 7
+)
+
+func (encoding Encoding) String() string {
+	switch encoding {
+	case EncodingPCM:
+		return "PCM"
+	case EncodingALaw:
+		return "A-law"
+	case EncodingMuLaw:
+		return "mu-law"
+	default:
+		return fmt.Sprintf("Encoding(%d)", uint16(encoding))
+	}
+}
+
+// DefaultPCMSampleRate is the sample rate in Hz that AWS Polly uses for pcm output if no sample rate is specified.
+const DefaultPCMSampleRate = 16000
+
+// Format describes the layout of uncompressed audio samples.
+type Format struct {
+	Encoding      Encoding
+	SampleRate    uint32
+	Channels      uint16
+	BitsPerSample uint16
+}
+
+// PCM16Format returns the format of 16-bit signed little-endian mono samples (AWS pcm and GCP LINEAR16).
+func PCM16Format(sampleRate uint32) Format {
+	return Format{Encoding: EncodingPCM, SampleRate: sampleRate, Channels: 1, BitsPerSample: 16}
+}
+
+// MuLawFormat returns the format of 8-bit mu-law mono samples (GCP MULAW).
+func MuLawFormat(sampleRate uint32) Format {
+	return Format{Encoding: EncodingMuLaw, SampleRate: sampleRate, Channels: 1, BitsPerSample: 8}
+}
+
+// ALawFormat returns the format of 8-bit A-law mono samples (GCP ALAW).
+func ALawFormat(sampleRate uint32) Format {
+	return Format{Encoding: EncodingALaw, SampleRate: sampleRate, Channels: 1, BitsPerSample: 8}
+}
+
+// BlockAlign returns the number of bytes of one sample frame (i.e. one sample for all channels).
+func (format Format) BlockAlign() uint16 {
+	return format.Channels * ((format.BitsPerSample + 7) / 8)
+}
+
+// ByteRate returns the number of bytes per second.
+func (format Format) ByteRate() uint32 {
+	return format.SampleRate * uint32(format.BlockAlign())
+}
+
+// HeaderSize returns the size of the WAV header in bytes that WriteWAVHeader writes for this format.
+func (format Format) HeaderSize() int {
+	if format.Encoding == EncodingPCM {
+		return 44
+	}
+	// non-PCM formats have a 2 byte larger "fmt " chunk and an additional "fact" chunk
+	return 58
+}
+
+// WriteWAVHeader writes a RIFF/WAVE header for the given format and number of data bytes.
+// The audio samples need to be written directly after the header.
+func WriteWAVHeader(w io.Writer, format Format, dataSize uint32) error {
+	if (format.Channels == 0) || (format.BitsPerSample == 0) || (format.SampleRate == 0) {
+		return errors.New(fmt.Sprintf("invalid audio format: %+v", format))
+	}
+
+	header := new(bytes.Buffer)
+	fmtChunkSize := uint32(16)
+	if format.Encoding != EncodingPCM {
+		fmtChunkSize = 18
+	}
+	riffSize := uint32(format.HeaderSize()-8) + dataSize
+
+	header.WriteString("RIFF")
+	_ = binary.Write(header, binary.LittleEndian, riffSize)
+	header.WriteString("WAVE")
+
+	header.WriteString("fmt ")
+	_ = binary.Write(header, binary.LittleEndian, fmtChunkSize)
+	_ = binary.Write(header, binary.LittleEndian, uint16(format.Encoding))
+	_ = binary.Write(header, binary.LittleEndian, format.Channels)
+	_ = binary.Write(header, binary.LittleEndian, format.SampleRate)
+	_ = binary.Write(header, binary.LittleEndian, format.ByteRate())
+	_ = binary.Write(header, binary.LittleEndian, format.BlockAlign())
+	_ = binary.Write(header, binary.LittleEndian, format.BitsPerSample)
+
+	if format.Encoding != EncodingPCM {
+		_ = binary.Write(header, binary.LittleEndian, uint16(0)) // size of the extension
+		header.WriteString("fact")
+		_ = binary.Write(header, binary.LittleEndian, uint32(4))
+		_ = binary.Write(header, binary.LittleEndian, dataSize/uint32(format.BlockAlign()))
+	}
+
+	header.WriteString("data")
+	_ = binary.Write(header, binary.LittleEndian, dataSize)
+
+	_, err := w.Write(header.Bytes())
+	return err
+}
+
+// EncodeWAV writes the given raw audio samples as a WAV file with the given format.
+func EncodeWAV(w io.Writer, format Format, samples []byte) error {
+	if err := WriteWAVHeader(w, format, uint32(len(samples))); err != nil {
+		return err
+	}
+	_, err := w.Write(samples)
+	return err
+}
+
+// HasWAVHeader returns true if the given audio data starts with a RIFF/WAVE header.
+func HasWAVHeader(data []byte) bool {
+	return (len(data) >= 12) && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE"))
+}
+
+// ParseWAV reads the format and the raw audio samples from the given WAV file.
+// If the size of the data chunk is larger than the remaining data (e.g. because the file was streamed and the
+// size is unknown), all remaining bytes are returned as samples.
+func ParseWAV(data []byte) (Format, []byte, error) {
+	if !HasWAVHeader(data) {
+		return Format{}, nil, errors.New("the given audio data doesn't contain a RIFF/WAVE header")
+	}
+
+	var format Format
+	formatFound := false
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkId := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		chunkStart := offset + 8
+
+		switch chunkId {
+		case "fmt ":
+			if (chunkSize < 16) || (chunkStart+16 > len(data)) {
+				return Format{}, nil, errors.New("the WAV header contains an invalid \"fmt \" chunk")
+			}
+			format = Format{
+				Encoding:      Encoding(binary.LittleEndian.Uint16(data[chunkStart : chunkStart+2])),
+				Channels:      binary.LittleEndian.Uint16(data[chunkStart+2 : chunkStart+4]),
+				SampleRate:    binary.LittleEndian.Uint32(data[chunkStart+4 : chunkStart+8]),
+				BitsPerSample: binary.LittleEndian.Uint16(data[chunkStart+14 : chunkStart+16]),
+			}
+			formatFound = true
+		case "data":
+			if !formatFound {
+				return Format{}, nil, errors.New("the WAV header doesn't contain a \"fmt \" chunk in front of the \"data\" chunk")
+			}
+			chunkEnd := chunkStart + chunkSize
+			if (chunkSize < 0) || (chunkEnd > len(data)) {
+				chunkEnd = len(data)
+			}
+			return format, data[chunkStart:chunkEnd], nil
+		}
+
+		// chunks are padded to an even size
+		offset = chunkStart + chunkSize + (chunkSize % 2)
+	}
+	return Format{}, nil, errors.New("the WAV file doesn't contain a \"data\" chunk")
+}
+
+// ConcatWAV concatenates the given audio segments and writes them as a single WAV file with the given format.
+// Each segment can either be a WAV file (whose header is removed) or raw audio samples in the given format.
+// If a segment is a WAV file with a different format, an error is returned.
+func ConcatWAV(w io.Writer, format Format, segments ...[]byte) error {
+	samples := make([][]byte, len(segments))
+	dataSize := 0
+	for i, segment := range segments {
+		if HasWAVHeader(segment) {
+			segmentFormat, segmentSamples, err := ParseWAV(segment)
+			if err != nil {
+				return errors.Join(errors.New(fmt.Sprintf("error while reading audio segment %d", i+1)), err)
+			}
+			if segmentFormat != format {
+				return errors.New(fmt.Sprintf("can't concatenate audio segment %d: its format %+v differs from the target format %+v",
+					i+1, segmentFormat, format))
+			}
+			segment = segmentSamples
+		}
+		samples[i] = segment
+		dataSize += len(segment)
+	}
+
+	if err := WriteWAVHeader(w, format, uint32(dataSize)); err != nil {
+		return err
+	}
+	for _, segment := range samples {
+		if _, err := w.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
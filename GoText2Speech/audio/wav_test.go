@@ -0,0 +1,157 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"testing"
+)
+
+func TestEncodeWAVAndParseWAV(t *testing.T) {
+	formats := []Format{PCM16Format(16000), MuLawFormat(8000), ALawFormat(8000)}
+	samples := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	for _, format := range formats {
+		t.Run(format.Encoding.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := EncodeWAV(buf, format, samples); err != nil {
+				t.Fatalf("EncodeWAV returned error: %s", err.Error())
+			}
+			data := buf.Bytes()
+			if len(data) != format.HeaderSize()+len(samples) {
+				t.Errorf("Wrong file size. Wanted: %d, Got: %d", format.HeaderSize()+len(samples), len(data))
+			}
+			if riffSize := binary.LittleEndian.Uint32(data[4:8]); int(riffSize) != len(data)-8 {
+				t.Errorf("Wrong RIFF chunk size. Wanted: %d, Got: %d", len(data)-8, riffSize)
+			}
+
+			parsedFormat, parsedSamples, err := ParseWAV(data)
+			if err != nil {
+				t.Fatalf("ParseWAV returned error: %s", err.Error())
+			}
+			if parsedFormat != format {
+				t.Errorf("Wrong format was parsed.\nWanted:\t%+v\nGot:\t%+v", format, parsedFormat)
+			}
+			if !bytes.Equal(parsedSamples, samples) {
+				t.Errorf("Wrong samples were parsed.\nWanted:\t%v\nGot:\t%v", samples, parsedSamples)
+			}
+		})
+	}
+}
+
+func TestPCM16FormatHeader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	_ = WriteWAVHeader(buf, PCM16Format(22050), 100)
+	header := buf.Bytes()
+
+	if binary.LittleEndian.Uint32(header[24:28]) != 22050 {
+		t.Error("Wrong sample rate in header")
+	}
+	if binary.LittleEndian.Uint32(header[28:32]) != 44100 {
+		t.Error("Wrong byte rate in header")
+	}
+	if binary.LittleEndian.Uint16(header[32:34]) != 2 {
+		t.Error("Wrong block align in header")
+	}
+	if binary.LittleEndian.Uint32(header[40:44]) != 100 {
+		t.Error("Wrong data size in header")
+	}
+}
+
+func TestParseWAVInvalid(t *testing.T) {
+	if _, _, err := ParseWAV([]byte("ID3 not a wav file")); err == nil {
+		t.Error("No error was returned for data without WAV header")
+	}
+}
+
+func TestConcatWAV(t *testing.T) {
+	format := PCM16Format(16000)
+	withHeader := new(bytes.Buffer)
+	_ = EncodeWAV(withHeader, format, []byte{1, 2, 3, 4})
+
+	result := new(bytes.Buffer)
+	err := ConcatWAV(result, format, withHeader.Bytes(), []byte{5, 6})
+	if err != nil {
+		t.Fatalf("ConcatWAV returned error: %s", err.Error())
+	}
+
+	_, samples, err := ParseWAV(result.Bytes())
+	if err != nil {
+		t.Fatalf("ParseWAV returned error: %s", err.Error())
+	}
+	if !bytes.Equal(samples, []byte{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Segments were not concatenated correctly. Got: %v", samples)
+	}
+}
+
+func TestConcatWAVDifferentFormats(t *testing.T) {
+	segment := new(bytes.Buffer)
+	_ = EncodeWAV(segment, PCM16Format(24000), []byte{1, 2})
+
+	err := ConcatWAV(new(bytes.Buffer), PCM16Format(16000), segment.Bytes())
+	if err == nil {
+		t.Error("No error was returned for segments with different formats")
+	}
+}
+
+func TestConcat(t *testing.T) {
+	// mp3 is concatenated byte by byte
+	result, err := Concat(shared.AudioFormatMp3, 0, []byte{1, 2}, []byte{3})
+	if err != nil {
+		t.Fatalf("Concat returned error: %s", err.Error())
+	}
+	if !bytes.Equal(result, []byte{1, 2, 3}) {
+		t.Errorf("mp3 segments were not concatenated correctly. Got: %v", result)
+	}
+
+	// linear16 segments with headers keep the sample rate of the segments
+	segment := new(bytes.Buffer)
+	_ = EncodeWAV(segment, PCM16Format(24000), []byte{1, 2})
+	result, err = Concat(shared.AudioFormatLinear16, 0, segment.Bytes(), segment.Bytes())
+	if err != nil {
+		t.Fatalf("Concat returned error: %s", err.Error())
+	}
+	format, samples, err := ParseWAV(result)
+	if err != nil {
+		t.Fatalf("ParseWAV returned error: %s", err.Error())
+	}
+	if format.SampleRate != 24000 {
+		t.Errorf("Wrong sample rate. Wanted: 24000, Got: %d", format.SampleRate)
+	}
+	if !bytes.Equal(samples, []byte{1, 2, 1, 2}) {
+		t.Errorf("linear16 segments were not concatenated correctly. Got: %v", samples)
+	}
+}
+
+func TestAddAndRemoveContainer(t *testing.T) {
+	raw := []byte{1, 2, 3, 4}
+	wav, err := AddContainer(shared.AudioFormatPcm, 0, raw)
+	if err != nil {
+		t.Fatalf("AddContainer returned error: %s", err.Error())
+	}
+	format, _, err := ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("ParseWAV returned error: %s", err.Error())
+	}
+	if format != PCM16Format(DefaultPCMSampleRate) {
+		t.Errorf("Wrong format.\nWanted:\t%+v\nGot:\t%+v", PCM16Format(DefaultPCMSampleRate), format)
+	}
+
+	wavAgain, _ := AddContainer(shared.AudioFormatPcm, 0, wav)
+	if !bytes.Equal(wav, wavAgain) {
+		t.Error("AddContainer added a second header")
+	}
+
+	mp3, _ := AddContainer(shared.AudioFormatMp3, 0, raw)
+	if !bytes.Equal(mp3, raw) {
+		t.Error("AddContainer changed compressed audio data")
+	}
+
+	samples, err := RemoveContainer(wav)
+	if err != nil {
+		t.Fatalf("RemoveContainer returned error: %s", err.Error())
+	}
+	if !bytes.Equal(samples, raw) {
+		t.Errorf("RemoveContainer returned wrong samples. Got: %v", samples)
+	}
+}
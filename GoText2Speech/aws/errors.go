@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/smithy-go"
+	"net/http"
+)
+
+// awsErrorKinds The kinds of errors (see ProviderError.Kind) of the error codes of AWS Polly and S3.
+// See https://docs.aws.amazon.com/polly/latest/dg/API_SynthesizeSpeech.html#API_SynthesizeSpeech_Errors
+var awsErrorKinds = map[string]error{
+	"ThrottlingException":      ErrThrottled,
+	"Throttling":               ErrThrottled,
+	"TooManyRequestsException": ErrThrottled,
+	"RequestLimitExceeded":     ErrThrottled,
+	"SlowDown":                 ErrThrottled,
+	"InvalidSsmlException":     ErrInvalidSSML,
+	"SsmlMarksNotSupportedForTextTypeException": ErrInvalidSSML,
+	"TextLengthExceededException":               ErrTextTooLong,
+	"MarksNotSupportedForFormatException":       ErrUnsupportedFormat,
+	"InvalidSampleRateException":                ErrInvalidOptions,
+	"EngineNotSupportedException":               ErrInvalidOptions,
+	"LanguageNotSupportedException":             ErrInvalidOptions,
+	"LexiconNotFoundException":                  ErrInvalidOptions,
+	"ValidationException":                       ErrInvalidOptions,
+	"InvalidS3BucketException":                  ErrInvalidDestination,
+	"InvalidS3KeyException":                     ErrInvalidDestination,
+	"NoSuchBucket":                              ErrInvalidDestination,
+	"UnrecognizedClientException":               ErrAuthentication,
+	"InvalidSignatureException":                 ErrAuthentication,
+	"SignatureDoesNotMatch":                     ErrAuthentication,
+	"ExpiredTokenException":                     ErrAuthentication,
+	"InvalidAccessKeyId":                        ErrAuthentication,
+	"MissingAuthenticationToken":                ErrAuthentication,
+	"AccessDeniedException":                     ErrAuthentication,
+	"AccessDenied":                              ErrAuthentication,
+	"ServiceFailureException":                   ErrProviderUnavailable,
+	"ServiceUnavailable":                        ErrProviderUnavailable,
+	"InternalError":                             ErrProviderUnavailable,
+}
+
+// AWSErrorKind returns the kind of the given error of AWS (e.g. ErrThrottled for a ThrottlingException), based on
+// its error code or, if the code is unknown, its HTTP status code. Returns nil if the error can't be classified.
+func AWSErrorKind(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if kind, ok := awsErrorKinds[apiErr.ErrorCode()]; ok {
+			return kind
+		}
+	}
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		statusCode := httpErr.HTTPStatusCode()
+		switch {
+		case statusCode == http.StatusTooManyRequests:
+			return ErrThrottled
+		case (statusCode == http.StatusUnauthorized) || (statusCode == http.StatusForbidden):
+			return ErrAuthentication
+		case statusCode >= http.StatusInternalServerError:
+			return ErrProviderUnavailable
+		}
+	}
+	return nil
+}
+
+// newProviderError wraps the given error of a request to AWS into a ProviderError. The kind is determined by
+// AWSErrorKind; if the error can't be classified, defaultKind is used.
+func (a T2SAmazonWebServices) newProviderError(op string, defaultKind error, err error) *ProviderError {
+	kind := AWSErrorKind(err)
+	if kind == nil {
+		kind = defaultKind
+	}
+	return NewProviderError(providers.ProviderAWS, op, kind, a.IsRetryableError(err), err)
+}
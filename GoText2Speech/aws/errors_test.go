@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/smithy-go"
+	"net/http"
+	"testing"
+)
+
+func TestAWSErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{&smithy.GenericAPIError{Code: "ThrottlingException"}, ErrThrottled},
+		{&smithy.GenericAPIError{Code: "InvalidSsmlException"}, ErrInvalidSSML},
+		{&smithy.GenericAPIError{Code: "TextLengthExceededException"}, ErrTextTooLong},
+		{&smithy.GenericAPIError{Code: "UnrecognizedClientException"}, ErrAuthentication},
+		{&smithy.GenericAPIError{Code: "NoSuchBucket"}, ErrInvalidDestination},
+		{responseError(http.StatusForbidden), ErrAuthentication},
+		{responseError(http.StatusServiceUnavailable), ErrProviderUnavailable},
+		{responseError(http.StatusBadRequest), nil},
+		{errors.New("unknown"), nil},
+	}
+	for _, test := range tests {
+		if got := AWSErrorKind(test.err); got != test.want {
+			t.Errorf("AWSErrorKind(%v) returned %v, wanted %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestNewProviderError(t *testing.T) {
+	provider := T2SAmazonWebServices{}
+	cause := &smithy.GenericAPIError{Code: "ThrottlingException"}
+	err := error(provider.newProviderError(OpSynthesize, ErrSynthesisFailed, cause))
+
+	if !errors.Is(err, ErrThrottled) || errors.Is(err, ErrSynthesisFailed) {
+		t.Errorf("Error is not classified as throttling error: %s", err.Error())
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || (apiErr.ErrorCode() != "ThrottlingException") {
+		t.Errorf("The AWS error can't be unwrapped from %s", err.Error())
+	}
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("Error is not a ProviderError: %s", err.Error())
+	}
+	if (providerErr.Provider != providers.ProviderAWS) || (providerErr.Op != OpSynthesize) || !providerErr.Retryable {
+		t.Errorf("Unexpected ProviderError: %+v", providerErr)
+	}
+
+	err = provider.newProviderError(OpUpload, ErrUploadFailed, errors.New("unknown"))
+	if !errors.Is(err, ErrUploadFailed) {
+		t.Errorf("Unclassified error doesn't use the default kind: %s", err.Error())
+	}
+}
+
+func TestAudioFormatToAWSValueUnsupported(t *testing.T) {
+	if _, err := AudioFormatToAWSValue(AudioFormatLinear16); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("AudioFormatToAWSValue returned %v, wanted ErrUnsupportedFormat", err)
+	}
+	if _, _, err := GetBucketAndKeyFromAWSDestination("ftp://bucket/key"); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("GetBucketAndKeyFromAWSDestination returned %v, wanted ErrInvalidDestination", err)
+	}
+}
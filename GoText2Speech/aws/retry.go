@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/smithy-go"
+	"net/http"
+)
+
+// awsRetryableErrorCodes The codes of AWS errors that are caused by throttling or temporary failures of AWS Polly or S3.
+var awsRetryableErrorCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"Throttling":               true,
+	"TooManyRequestsException": true,
+	"RequestLimitExceeded":     true,
+	"SlowDown":                 true,
+	"ServiceFailureException":  true,
+	"ServiceUnavailable":       true,
+	"InternalError":            true,
+	"RequestTimeout":           true,
+}
+
+// withoutSDKRetries disables the retries of the AWS SDK for a single request. Requests that are retried by the client
+// (see RetryPolicy) use this option, so that the attempts of both retryers are not multiplied.
+func withoutSDKRetries(o *polly.Options) {
+	o.RetryMaxAttempts = 1
+}
+
+// IsRetryableError returns true if the given error is caused by throttling (e.g. ThrottlingException), a temporary
+// failure of AWS (e.g. ServiceFailureException or HTTP status 503) or a network timeout.
+func (a T2SAmazonWebServices) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && awsRetryableErrorCodes[apiErr.ErrorCode()] {
+		return true
+	}
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		statusCode := httpErr.HTTPStatusCode()
+		if (statusCode == http.StatusTooManyRequests) || (statusCode >= http.StatusInternalServerError) {
+			return true
+		}
+	}
+	return IsTemporaryNetworkError(err)
+}
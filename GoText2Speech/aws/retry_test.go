@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"net/http"
+	"testing"
+)
+
+func responseError(statusCode int) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: statusCode}},
+			Err:      errors.New("response error"),
+		},
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	provider := T2SAmazonWebServices{}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{fmt.Errorf("failed to upload file, %w", &smithy.GenericAPIError{Code: "SlowDown"}), true},
+		{&smithy.GenericAPIError{Code: "ServiceFailureException"}, true},
+		{&smithy.GenericAPIError{Code: "InvalidSsmlException"}, false},
+		{responseError(http.StatusServiceUnavailable), true},
+		{responseError(http.StatusTooManyRequests), true},
+		{responseError(http.StatusBadRequest), false},
+		{context.Canceled, false},
+		{errors.New("unknown"), false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := provider.IsRetryableError(test.err); got != test.want {
+			t.Errorf("IsRetryableError(%v) returned %t, wanted %t", test.err, got, test.want)
+		}
+	}
+}
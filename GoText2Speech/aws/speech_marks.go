@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+	"io"
+	"strings"
+	"time"
+)
+
+// SpeechMarkTypeToAWSValue Converts the given SpeechMarkType into a valid speech mark type that can be used on AWS.
+// Available speech mark types on AWS can be seen here: https://docs.aws.amazon.com/polly/latest/dg/speechmarks.html
+func SpeechMarkTypeToAWSValue(markType SpeechMarkType) (types.SpeechMarkType, error) {
+	switch markType {
+	case SpeechMarkTypeWord:
+		return types.SpeechMarkTypeWord, nil
+	case SpeechMarkTypeSentence:
+		return types.SpeechMarkTypeSentence, nil
+	case SpeechMarkTypeSsml:
+		return types.SpeechMarkTypeSsml, nil
+	case SpeechMarkTypeViseme:
+		return types.SpeechMarkTypeViseme, nil
+	default:
+		return "", errors.Join(ErrInvalidOptions, errors.New("the specified speech mark type "+string(markType)+" is not available on AWS"))
+	}
+}
+
+// awsSpeechMark is a single line of the speech marks that AWS returns with the json output format.
+type awsSpeechMark struct {
+	Time  int64  `json:"time"`
+	Type  string `json:"type"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Value string `json:"value"`
+}
+
+// ParseAWSSpeechMarks parses the speech marks that AWS returns with the json output format.
+// AWS returns one JSON object per line, e.g. {"time":6,"type":"word","start":0,"end":5,"value":"Hello"}.
+func ParseAWSSpeechMarks(speechMarks io.Reader) ([]SpeechMark, error) {
+	var marks []SpeechMark
+	scanner := bufio.NewScanner(speechMarks)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var mark awsSpeechMark
+		if err := json.Unmarshal([]byte(line), &mark); err != nil {
+			return nil, errors.Join(errors.New(fmt.Sprintf("error while parsing speech mark '%s'", line)), err)
+		}
+		speechMark := SpeechMark{
+			Type:  SpeechMarkType(mark.Type),
+			Time:  time.Duration(mark.Time) * time.Millisecond,
+			Start: mark.Start,
+			End:   mark.End,
+			Value: mark.Value,
+		}
+		if speechMark.Type == SpeechMarkTypeViseme {
+			speechMark.Start = -1
+			speechMark.End = -1
+		}
+		marks = append(marks, speechMark)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(errors.New("error while reading speech marks"), err)
+	}
+	return marks, nil
+}
+
+// ExecuteT2SWithSpeechMarks synthesizes the given text like ExecuteT2SDirect and additionally returns the speech
+// marks of the types specified in TextToSpeechOptions.SpeechMarkTypes.
+// AWS returns speech marks only through the json output format. Therefore, a second SynthesizeSpeech request is
+// sent concurrently to the request that synthesizes the audio. Both requests are billed.
+func (a T2SAmazonWebServices) ExecuteT2SWithSpeechMarks(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error) {
+	speechMarksInput, inputErr := createSynthesizeSpeechInput(text, options)
+	if inputErr != nil {
+		return nil, nil, inputErr
+	}
+	speechMarksInput.OutputFormat = types.OutputFormatJson
+	speechMarksInput.SampleRate = nil
+	for _, markType := range options.SpeechMarkTypes {
+		awsMarkType, err := SpeechMarkTypeToAWSValue(markType)
+		if err != nil {
+			return nil, nil, err
+		}
+		speechMarksInput.SpeechMarkTypes = append(speechMarksInput.SpeechMarkTypes, awsMarkType)
+	}
+
+	type speechMarksResult struct {
+		marks []SpeechMark
+		err   error
+	}
+	speechMarksChannel := make(chan speechMarksResult, 1)
+	go func() {
+		output, err := a.t2sClient.SynthesizeSpeech(ctx, speechMarksInput, withoutSDKRetries)
+		if err != nil {
+			speechMarksChannel <- speechMarksResult{err: a.newProviderError(OpSpeechMarks, ErrSynthesisFailed, err)}
+			return
+		}
+		defer output.AudioStream.Close()
+		marks, err := ParseAWSSpeechMarks(output.AudioStream)
+		if err != nil {
+			speechMarksChannel <- speechMarksResult{err: NewProviderError(providers.ProviderAWS, OpSpeechMarks, ErrSynthesisFailed, false, err)}
+			return
+		}
+		speechMarksChannel <- speechMarksResult{marks: marks}
+	}()
+
+	audioData, t2sErr := a.ExecuteT2SDirect(ctx, text, destination, options)
+	speechMarks := <-speechMarksChannel
+	if t2sErr != nil {
+		return nil, nil, t2sErr
+	}
+	if speechMarks.err != nil {
+		// the audio isn't returned, so its stream needs to be closed to release the connection
+		if closer, isCloser := audioData.(io.Closer); isCloser {
+			_ = closer.Close()
+		}
+		return nil, nil, speechMarks.err
+	}
+	return audioData, speechMarks.marks, nil
+}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAWSSpeechMarks(t *testing.T) {
+	input := `{"time":0,"type":"sentence","start":0,"end":23,"value":"Mary had a little lamb."}
+{"time":6,"type":"word","start":0,"end":4,"value":"Mary"}
+{"time":6,"type":"viseme","value":"p"}
+`
+	marks, err := ParseAWSSpeechMarks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAWSSpeechMarks returned error: %s", err.Error())
+	}
+	if len(marks) != 3 {
+		t.Fatalf("Expected 3 speech marks, got %d", len(marks))
+	}
+
+	want := shared.SpeechMark{Type: shared.SpeechMarkTypeWord, Time: 6 * time.Millisecond, Start: 0, End: 4, Value: "Mary"}
+	if marks[1] != want {
+		t.Errorf("Word mark was not parsed correctly.\nWanted:\t%+v\nGot:\t%+v", want, marks[1])
+	}
+	if marks[2].Start != -1 || marks[2].End != -1 {
+		t.Errorf("Viseme mark should not have offsets: %+v", marks[2])
+	}
+}
+
+func TestParseAWSSpeechMarksInvalid(t *testing.T) {
+	if _, err := ParseAWSSpeechMarks(strings.NewReader("not json")); err == nil {
+		t.Error("No error was returned for invalid speech marks")
+	}
+}
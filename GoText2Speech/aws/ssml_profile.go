@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+)
+
+// awsSSMLProfile The SSML tags that are supported by AWS Polly.
+// See https://docs.aws.amazon.com/polly/latest/dg/supportedtags.html
+var awsSSMLProfile = ssml.Profile{
+	Name: "AWS Polly",
+	Elements: map[string]ssml.ElementProfile{
+		"speak": {Attributes: map[string][]string{"xml:lang": nil, "version": nil, "xmlns": nil}},
+		"break": {Attributes: map[string][]string{
+			"strength": {"none", "x-weak", "weak", "medium", "strong", "x-strong"},
+			"time":     nil,
+		}},
+		"emphasis": {
+			Attributes:        map[string][]string{"level": {"strong", "moderate", "reduced"}},
+			ValueTranslations: map[string]map[string]string{"level": {"none": "reduced"}},
+		},
+		"lang": {
+			Attributes:         map[string][]string{"xml:lang": nil, "onlangfailure": nil},
+			RequiredAttributes: []string{"xml:lang"},
+		},
+		"mark": {Attributes: map[string][]string{"name": nil}, RequiredAttributes: []string{"name"}},
+		"p":    {},
+		"s":    {},
+		"phoneme": {
+			Attributes:         map[string][]string{"alphabet": {"ipa", "x-sampa"}, "ph": nil},
+			RequiredAttributes: []string{"ph"},
+		},
+		"prosody": {Attributes: map[string][]string{"volume": nil, "rate": nil, "pitch": nil, "amazon:max-duration": nil}},
+		"say-as": {
+			Attributes: map[string][]string{
+				"interpret-as": {"characters", "spell-out", "cardinal", "number", "ordinal", "digits", "fraction",
+					"unit", "date", "time", "address", "expletive", "telephone"},
+				"format": nil,
+			},
+			RequiredAttributes: []string{"interpret-as"},
+			ValueTranslations: map[string]map[string]string{"interpret-as": {
+				"verbatim": "characters",
+				"bleep":    "expletive",
+			}},
+		},
+		"sub":                 {Attributes: map[string][]string{"alias": nil}, RequiredAttributes: []string{"alias"}},
+		"w":                   {Attributes: map[string][]string{"role": nil}},
+		"amazon:auto-breaths": {Attributes: map[string][]string{"volume": nil, "frequency": nil, "duration": nil}},
+		"amazon:breath":       {Attributes: map[string][]string{"volume": nil, "duration": nil}},
+		"amazon:domain": {
+			Attributes:         map[string][]string{"name": {"news", "conversational", "long-form", "music"}},
+			RequiredAttributes: []string{"name"},
+		},
+		"amazon:effect": {Attributes: map[string][]string{
+			"name":               {"drc", "whispered"},
+			"phonation":          {"soft"},
+			"vocal-tract-length": nil,
+		}},
+	},
+	ElementTranslations: map[string]func(element *ssml.Element) []ssml.Node{
+		// AWS doesn't play audio files -> use the fallback text of the <audio> element, but not its description
+		"audio": func(element *ssml.Element) []ssml.Node {
+			var nodes []ssml.Node
+			for _, child := range element.Children {
+				if childElement, ok := child.(*ssml.Element); ok && (childElement.Name == "desc") {
+					continue
+				}
+				nodes = append(nodes, child)
+			}
+			return nodes
+		},
+	},
+}
+
+// GetSSMLProfile returns the SSML tags and attributes that are supported by AWS Polly.
+// Some tags are only supported by certain engines (e.g. <amazon:effect name="whispered"> is not supported by the
+// neural engine). These restrictions are not part of the profile.
+func (a T2SAmazonWebServices) GetSSMLProfile() ssml.Profile {
+	return awsSSMLProfile
+}
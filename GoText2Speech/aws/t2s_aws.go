@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
 	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
 	"github.com/aws/aws-sdk-go-v2/service/polly/types"
 	//"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -39,7 +41,7 @@ func AudioFormatToAWSValue(format AudioFormat) (string, error) {
 	case AudioFormatPcm:
 		return "pcm", nil
 	default:
-		return "", errors.New("the specified audio format " + string(format) + " is not available on AWS. Either choose a different audio format, choose a different provider or use the TextToSpeechOptions.OutputFormatRaw property to  format check.")
+		return "", errors.Join(ErrUnsupportedFormat, errors.New("the specified audio format "+string(format)+" is not available on AWS. Either choose a different audio format, choose a different provider or use the TextToSpeechOptions.OutputFormatRaw property to  format check."))
 	}
 }
 
@@ -48,7 +50,7 @@ func AudioFormatToAWSValue(format AudioFormat) (string, error) {
 // If enum value couldn't be found or if the specified rawFormat is undefined/empty, an error is returned.
 func AWSValueToAudioFormat(rawFormat string) (AudioFormat, error) {
 	if strings.EqualFold(rawFormat, "") {
-		return "", errors.New("the specified rawFormat was empty")
+		return "", errors.Join(ErrUnsupportedFormat, errors.New("the specified rawFormat was empty"))
 	}
 	for _, audioFormat := range GetAllAudioFormats() {
 		a, _ := AudioFormatToAWSValue(audioFormat)
@@ -56,7 +58,7 @@ func AWSValueToAudioFormat(rawFormat string) (AudioFormat, error) {
 			return audioFormat, nil
 		}
 	}
-	return "", errors.New("the specified rawFormat " + rawFormat + " has no defined AudioFormat value.")
+	return "", errors.Join(ErrUnsupportedFormat, errors.New("the specified rawFormat "+rawFormat+" has no defined AudioFormat value."))
 }
 
 var awsSupportedAudioFormats = []AudioFormat{
@@ -70,6 +72,38 @@ func (a T2SAmazonWebServices) GetSupportedAudioFormats() []AudioFormat {
 	return awsSupportedAudioFormats
 }
 
+// awsTextLengthLimit AWS Polly allows up to 3000 billed characters (SSML tags are not billed)
+// and up to 6000 characters in total per request.
+// See https://docs.aws.amazon.com/polly/latest/dg/limits.html
+var awsTextLengthLimit = TextLengthLimit{
+	MaxLength:      3000,
+	Unit:           TextLengthUnitCharacters,
+	IgnoreSSMLTags: true,
+	MaxTotalLength: 6000,
+}
+
+func (a T2SAmazonWebServices) GetTextLengthLimit() TextLengthLimit {
+	return awsTextLengthLimit
+}
+
+// GetBillingPolicy AWS Polly bills the characters of the text without SSML tags, by engine. Speech marks are requested
+// separately and billed as well.
+// See https://aws.amazon.com/polly/pricing/
+func (a T2SAmazonWebServices) GetBillingPolicy(options TextToSpeechOptions) BillingPolicy {
+	tier := options.VoiceConfig.VoiceIdConfig.Engine
+	if tier == "" {
+		tier = options.VoiceConfig.VoiceParamsConfig.Engine
+	}
+	if tier == "" {
+		tier = string(types.EngineStandard)
+	}
+	return BillingPolicy{
+		Tier:            strings.ToLower(tier),
+		BillSSMLTags:    false,
+		BillSpeechMarks: true,
+	}
+}
+
 func (a T2SAmazonWebServices) IsURLonOwnStorage(url string) bool {
 	return IsAWSUrl(url)
 }
@@ -84,10 +118,13 @@ func (a T2SAmazonWebServices) TransformOptions(text string, options TextToSpeech
 			text = TransformTextIntoSSML(text, options)
 			options.TextType = TextTypeSsml
 		} else {
-			// integrate parameters into a new <prosody> tag
-			openingTag := GetOpeningTagOfSSMLText(text)
-			settingsTag := CreateProsodyTag(options)
-			text = openingTag + settingsTag + RemoveClosingSpeakTagOfSSMLText(RemoveOpeningTagOfSSMLText(text)) + "</prosody></speak>"
+			// integrate parameters into a new <prosody> element that contains the whole content of the root element
+			document, err := ssml.Parse(text)
+			if err != nil {
+				return text, options, errors.Join(ErrInvalidSSML, err)
+			}
+			document.Root.WrapChildren(CreateProsodyElement(options))
+			text = document.String()
 		}
 	}
 
@@ -105,56 +142,76 @@ func (a T2SAmazonWebServices) TransformOptions(text string, options TextToSpeech
 // FindVoice finds a voice for AWS Polly based on the given parameters (language, gender and optionally engine).
 // If voice was found, returns VoiceIdConfig object on which VoiceId and Engine is set (and nil as error).
 // If a voice with the needed parameters is not found, returns nil and error.
-func (a T2SAmazonWebServices) FindVoice(options TextToSpeechOptions) (*VoiceIdConfig, error) {
-
-	// Get list of available voices for the chosen language and pick the first one with the correct gender and engine
-	input := &polly.DescribeVoicesInput{LanguageCode: types.LanguageCode(options.VoiceConfig.VoiceParamsConfig.LanguageCode)}
-	resp, err := a.t2sClient.DescribeVoices(context.Background(), input)
-
+func (a T2SAmazonWebServices) FindVoice(ctx context.Context, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	voices, err := a.ListVoices(ctx, VoiceFilterOf(options))
 	if err != nil {
-		return nil, errors.New("Error while describing voices: " + err.Error())
+		return nil, err
 	}
+	return a.SelectVoice(voices, options)
+}
 
-	targetEngine := options.VoiceConfig.VoiceParamsConfig.Engine
-	var voiceConfig *VoiceIdConfig = nil
-	for _, v := range resp.Voices {
-		if strings.EqualFold(string(v.Gender), options.VoiceConfig.VoiceParamsConfig.Gender.String()) {
-
-			// if engine param is specified, check if engine is supported for this voice
-			if !strings.EqualFold("", targetEngine) {
-				voiceWithEngineFound := false
-				for _, e := range v.SupportedEngines {
-					if strings.EqualFold(string(e), targetEngine) {
-						voiceWithEngineFound = true
-						break
-					}
-				}
-				if !voiceWithEngineFound {
-					continue
-				}
-			}
+// SelectVoice chooses the best voice of the given voices for the language, gender, engine and preferences of the options.
+func (a T2SAmazonWebServices) SelectVoice(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	return FindVoiceInList(voices, options)
+}
 
-			voiceConfig = &VoiceIdConfig{
-				VoiceId: *v.Name,
-				Engine:  targetEngine,
-			}
-			fmt.Printf("Found voice with language %s, gender %s and engine %s: %s\n",
-				options.VoiceConfig.VoiceParamsConfig.LanguageCode,
-				options.VoiceConfig.VoiceParamsConfig.Gender.String(),
-				targetEngine,
-				*v.Name)
+// ListVoices lists all voices on AWS Polly that match the given filter.
+// Voices that speak the language of the filter only as additional language (i.e. bilingual voices) are included.
+func (a T2SAmazonWebServices) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
+	input := &polly.DescribeVoicesInput{IncludeAdditionalLanguageCodes: true}
+	// AWS only accepts full language codes (e.g. en-US), other language codes are filtered afterwards
+	if strings.Contains(filter.LanguageCode, "-") {
+		input.LanguageCode = types.LanguageCode(filter.LanguageCode)
+	}
+	if filter.Engine != "" {
+		input.Engine = types.Engine(strings.ToLower(filter.Engine))
+	}
+
+	var voices []Voice
+	for {
+		resp, err := a.t2sClient.DescribeVoices(ctx, input)
+		if err != nil {
+			return nil, a.newProviderError(OpListVoices, ErrProviderUnavailable, err)
+		}
+		for _, v := range resp.Voices {
+			voices = append(voices, AWSVoiceToVoice(v))
+		}
+		if resp.NextToken == nil {
 			break
 		}
+		input.NextToken = resp.NextToken
 	}
+	return FilterVoices(voices, filter), nil
+}
 
-	if voiceConfig == nil {
-		errText := fmt.Sprintf("error: No voice found for language %s and gender %s\n",
-			options.VoiceConfig.VoiceParamsConfig.LanguageCode,
-			options.VoiceConfig.VoiceParamsConfig.Gender.String())
-		return nil, errors.New(errText)
+// AWSVoiceToVoice converts the given AWS Polly voice into a provider-independent Voice.
+func AWSVoiceToVoice(v types.Voice) Voice {
+	voice := Voice{
+		Provider:      providers.ProviderAWS,
+		Id:            string(v.Id),
+		Name:          aws.ToString(v.Name),
+		LanguageCodes: []string{string(v.LanguageCode)},
+		Gender:        AWSGenderToVoiceGender(v.Gender),
+	}
+	for _, code := range v.AdditionalLanguageCodes {
+		voice.LanguageCodes = append(voice.LanguageCodes, string(code))
+	}
+	for _, engine := range v.SupportedEngines {
+		voice.SupportedEngines = append(voice.SupportedEngines, string(engine))
 	}
+	return voice
+}
 
-	return voiceConfig, nil
+// AWSGenderToVoiceGender converts the given AWS Polly gender into a VoiceGender.
+func AWSGenderToVoiceGender(gender types.Gender) VoiceGender {
+	switch gender {
+	case types.GenderFemale:
+		return VoiceGenderFemale
+	case types.GenderMale:
+		return VoiceGenderMale
+	default:
+		return VoiceGenderUnspecified
+	}
 }
 
 type CredentialsProvider struct {
@@ -166,6 +223,10 @@ func (b CredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, err
 }
 
 func (a T2SAmazonWebServices) CreateServiceClient(cred CredentialsHolder, region string) (T2SProvider, error) {
+	if cred.AwsCredentials == nil {
+		return a, NewProviderError(providers.ProviderAWS, OpCreateClient, ErrAuthentication, false,
+			errors.New("the credentials don't contain AWS credentials"))
+	}
 	credProv := CredentialsProvider{
 		credentials: *cred.AwsCredentials,
 	}
@@ -182,7 +243,6 @@ func (a T2SAmazonWebServices) AddFileExtensionToDestinationIfNeeded(options Text
 	if options.AddFileExtension {
 		audioFormat, err := AWSValueToAudioFormat(outputFormatRaw.(string))
 		if err != nil {
-			fmt.Printf("%s\n", err.Error())
 			errNew := errors.New(fmt.Sprintf("No file extension found for the specified raw audio format %s. No file extension is added to file name.\n", outputFormatRaw.(string)))
 			return destination, errors.Join(err, errNew)
 		} else {
@@ -198,12 +258,30 @@ func (a T2SAmazonWebServices) AddFileExtensionToDestinationIfNeeded(options Text
 // ExecuteT2SDirect executes Text-to-Speech using AWS Polly service. The given text is transformed into speech
 // using the given options. The created audio file is uploaded to AWS S3 on the given destination.
 // The destination string can either be an AWS S3 URI (starting with "s3://") or AWS S3 Object URL (starting with "https://").
-func (a T2SAmazonWebServices) ExecuteT2SDirect(text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+func (a T2SAmazonWebServices) ExecuteT2SDirect(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+
+	speechInput, inputErr := createSynthesizeSpeechInput(text, options)
+	if inputErr != nil {
+		return nil, inputErr
+	}
+
+	logger := LoggerFromContext(ctx)
+	logger.Debug("Synthesizing speech on AWS", LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId)
+	output, err := a.t2sClient.SynthesizeSpeech(ctx, speechInput, withoutSDKRetries)
+
+	if err != nil {
+		return nil, a.newProviderError(OpSynthesize, ErrSynthesisFailed, err)
+	}
+	logger.Debug("Synthesized speech on AWS", LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId)
+	return output.AudioStream, nil
+}
 
+// createSynthesizeSpeechInput creates the input for the SynthesizeSpeech request from the given text and options.
+func createSynthesizeSpeechInput(text string, options TextToSpeechOptions) (*polly.SynthesizeSpeechInput, error) {
 	outputFormatRaw, outputFormatAssertedCorrectly := options.OutputFormatRaw.(string)
 
 	if !outputFormatAssertedCorrectly {
-		outputFormatError := errors.New("the raw output format was not a string, but AWS can only use strings as output format")
+		outputFormatError := errors.Join(ErrUnsupportedFormat, errors.New("the raw output format was not a string, but AWS can only use strings as output format"))
 		return nil, outputFormatError
 	}
 
@@ -221,17 +299,7 @@ func (a T2SAmazonWebServices) ExecuteT2SDirect(text string, destination string,
 		s := fmt.Sprintf("%d", options.SampleRate)
 		speechInput.SampleRate = &s
 	}
-
-	fmt.Printf("Synthesizing...\n")
-	output, err := a.t2sClient.SynthesizeSpeech(context.Background(), speechInput)
-
-	if err != nil {
-		errNew := errors.New("Error while synthesizing speech on AWS: " + err.Error() + "\n")
-		fmt.Printf(errNew.Error())
-		return nil, errNew
-	}
-	fmt.Printf("Synthesizing done!\n")
-	return output.AudioStream, nil
+	return speechInput, nil
 }
 
 // GetBucketAndKeyFromAWSDestination receives either an AWS S3 URI (starting with "s3://") or
@@ -250,40 +318,40 @@ func GetBucketAndKeyFromAWSDestination(destination string) (string, string, erro
 		key := strings.SplitN(dotSplits[2], "/", 2)[1]
 		return bucket, key, nil
 	} else {
-		return "", "", errors.New(fmt.Sprintf("The given destination '%s' is not a valid S3 URI or S3 Object URL.", destination))
+		return "", "", errors.Join(ErrInvalidDestination, errors.New(fmt.Sprintf("The given destination '%s' is not a valid S3 URI or S3 Object URL.", destination)))
 	}
 }
 
-func (a T2SAmazonWebServices) UploadFile(fileContents io.Reader, destination string) error {
+func (a T2SAmazonWebServices) UploadFile(ctx context.Context, fileContents io.Reader, destination string) error {
 	bucket, key, destinationFormatErr := GetBucketAndKeyFromAWSDestination(destination)
 	if destinationFormatErr != nil {
 		return destinationFormatErr
 	}
-	return a.uploadFileToS3(fileContents, bucket, key)
+	return a.uploadFileToS3(ctx, fileContents, bucket, key)
 }
 
 // UploadFileToS3 takes a file stream and uploads it to S3 using the given S3 bucket and key.
 // Code adapted from AWS Docs (https://docs.aws.amazon.com/sdk-for-go/api/service/s3/#hdr-Upload_Managers)
-func (a T2SAmazonWebServices) uploadFileToS3(fileContents io.Reader, bucket string, key string) error {
+func (a T2SAmazonWebServices) uploadFileToS3(ctx context.Context, fileContents io.Reader, bucket string, key string) error {
 
-	fmt.Printf("Uploading file...\n")
-
-	// Create an uploader with the session and default options
+	// Create an uploader with the session and default options. Failed uploads are retried by the client
+	// (see RetryPolicy), so the retries of the AWS SDK are disabled.
 	uploader := s3.New(s3.Options{
 		Credentials: CredentialsProvider{
 			credentials: *a.credentials.AwsCredentials,
 		},
-		Region: a.region,
+		Region:           a.region,
+		RetryMaxAttempts: 1,
 	})
 
 	buf := new(bytes.Buffer)
-	_, err1 := buf.ReadFrom(fileContents)
-	if err1 != nil {
-		fmt.Println(err1.Error())
+	_, err := buf.ReadFrom(fileContents)
+	if err != nil {
+		return NewProviderError(providers.ProviderAWS, OpUpload, ErrUploadFailed, false, err)
 	}
 
 	// Upload the file to S3.
-	_, err := uploader.PutObject(context.Background(), &s3.PutObjectInput{
+	_, err = uploader.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:        aws.String(bucket),
 		Key:           aws.String(key),
 		Body:          buf,
@@ -291,9 +359,9 @@ func (a T2SAmazonWebServices) uploadFileToS3(fileContents io.Reader, bucket stri
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to upload file, %v", err)
+		return a.newProviderError(OpUpload, ErrUploadFailed, err)
 	}
-	fmt.Printf("file uploaded to, %s/%s\n", bucket, key)
+	LoggerFromContext(ctx).Debug("Uploaded file to S3", LogKeyDestination, "s3://"+bucket+"/"+key)
 	return nil
 }
 
@@ -85,6 +85,14 @@ func TestTransformOptions(t *testing.T) {
 			pitch:    options.Pitch,
 			textType: shared.TextTypeSsml,
 		},
+		{
+			input:    "<?xml version=\"1.0\"?><speak><!-- <b> --><sub alias=\"a > b\">a&gt;b</sub></speak>",
+			want:     "<?xml version=\"1.0\"?><speak><prosody volume=\"0.000dB\" pitch=\"0.000%\" rate=\"110.000%\"><!-- <b> --><sub alias=\"a &gt; b\">a&gt;b</sub></prosody></speak>",
+			volume:   options.Volume,
+			rate:     1.1,
+			pitch:    options.Pitch,
+			textType: shared.TextTypeSsml,
+		},
 		{
 			input:    "<speak>Hello World!</speak>",
 			want:     "<speak><prosody volume=\"10.000dB\" pitch=\"-5.000%\" rate=\"95.000%\">Hello World!</prosody></speak>",
@@ -287,3 +295,43 @@ func TestAddFileExtensionToDestinationIfNeededOtherExtension(t *testing.T) {
 		t.Errorf("File extension was incorrectly added. Actual value: %s\n", destination)
 	}
 }
+
+func TestSSMLProfileTranslatesGCPSpecificSSML(t *testing.T) {
+	input := "<speak><par><media><speak>Hi</speak></media></par> <say-as interpret-as=\"verbatim\">abc</say-as> " +
+		"<audio src=\"https://example.com/bell.mp3\"><desc>Bell</desc>ding</audio></speak>"
+	want := "<speak>Hi <say-as interpret-as=\"characters\">abc</say-as> ding</speak>"
+
+	provider := T2SAmazonWebServices{}
+	if _, err := shared.ApplySSMLProfile(input, provider.GetSSMLProfile(), shared.SSMLValidationStrict); err == nil {
+		t.Error("No error was returned for SSML text with GCP specific elements")
+	}
+	result, err := shared.ApplySSMLProfile(input, provider.GetSSMLProfile(), shared.SSMLValidationTranslate)
+	if err != nil {
+		t.Fatalf("ApplySSMLProfile returned error: %s", err.Error())
+	}
+	if result != want {
+		t.Errorf("SSML text was not translated correctly.\nWanted:\t%s\nGot:\t%s", want, result)
+	}
+}
+
+func TestSSMLProfileAcceptsSpeakAttributes(t *testing.T) {
+	input := "<speak version=\"1.1\" xmlns=\"http://www.w3.org/2001/10/synthesis\" xml:lang=\"en-US\">Hi</speak>"
+
+	provider := T2SAmazonWebServices{}
+	if _, err := shared.ApplySSMLProfile(input, provider.GetSSMLProfile(), shared.SSMLValidationStrict); err != nil {
+		t.Errorf("ApplySSMLProfile returned error for valid speak attributes: %s", err.Error())
+	}
+}
+
+func TestGetBillingPolicy(t *testing.T) {
+	provider := T2SAmazonWebServices{}
+	options := shared.GetDefaultTextToSpeechOptions()
+	if tier := provider.GetBillingPolicy(*options).Tier; tier != "standard" {
+		t.Errorf("Tier without engine is %s, wanted standard", tier)
+	}
+	options.VoiceConfig.VoiceIdConfig = shared.VoiceIdConfig{VoiceId: "Joanna", Engine: "Neural"}
+	policy := provider.GetBillingPolicy(*options)
+	if (policy.Tier != "neural") || policy.BillSSMLTags || !policy.BillSpeechMarks {
+		t.Errorf("Unexpected billing policy: %+v", policy)
+	}
+}
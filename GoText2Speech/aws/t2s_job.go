@@ -0,0 +1,132 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+	"strings"
+)
+
+// awsT2SJobTextLengthLimit Synthesis tasks can synthesize up to 100,000 billed characters (SSML tags are not billed)
+// and 200,000 characters in total. See https://docs.aws.amazon.com/polly/latest/dg/limits.html
+var awsT2SJobTextLengthLimit = TextLengthLimit{
+	MaxLength:      100000,
+	Unit:           TextLengthUnitCharacters,
+	IgnoreSSMLTags: true,
+	MaxTotalLength: 200000,
+}
+
+func (a T2SAmazonWebServices) GetT2SJobTextLengthLimit() TextLengthLimit {
+	return awsT2SJobTextLengthLimit
+}
+
+// StartT2SJob starts a Polly synthesis task (StartSpeechSynthesisTask) that writes the audio file to S3.
+// Polly names the file itself: the key of the given destination is only used as prefix, followed by the task ID and
+// the file extension. The final location is set as destination of the returned job.
+func (a T2SAmazonWebServices) StartT2SJob(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SJob, error) {
+	bucket, key, destinationErr := GetBucketAndKeyFromAWSDestination(destination)
+	if destinationErr != nil {
+		return nil, destinationErr
+	}
+	speechInput, inputErr := createSynthesizeSpeechInput(text, options)
+	if inputErr != nil {
+		return nil, inputErr
+	}
+
+	taskInput := &polly.StartSpeechSynthesisTaskInput{
+		OutputFormat:       speechInput.OutputFormat,
+		OutputS3BucketName: aws.String(bucket),
+		Text:               speechInput.Text,
+		VoiceId:            speechInput.VoiceId,
+		Engine:             speechInput.Engine,
+		SampleRate:         speechInput.SampleRate,
+		TextType:           speechInput.TextType,
+	}
+	if key != "" {
+		taskInput.OutputS3KeyPrefix = aws.String(key)
+	}
+	output, err := a.t2sClient.StartSpeechSynthesisTask(ctx, taskInput, withoutSDKRetries)
+	if err != nil {
+		return nil, a.newProviderError(OpStartJob, ErrSynthesisFailed, err)
+	}
+	if output.SynthesisTask == nil {
+		return nil, NewProviderError(providers.ProviderAWS, OpStartJob, ErrSynthesisFailed, false,
+			errors.New("AWS didn't return the started speech synthesis task"))
+	}
+	job := AWSSynthesisTaskToT2SJob(*output.SynthesisTask)
+	if job.Destination == "" {
+		job.Destination = destination
+	}
+	return &job, nil
+}
+
+// GetT2SJob requests the status of the synthesis task (GetSpeechSynthesisTask).
+func (a T2SAmazonWebServices) GetT2SJob(ctx context.Context, job T2SJob) (*T2SJob, error) {
+	output, err := a.t2sClient.GetSpeechSynthesisTask(ctx, &polly.GetSpeechSynthesisTaskInput{
+		TaskId: aws.String(job.Id),
+	}, withoutSDKRetries)
+	if err != nil {
+		return nil, a.newProviderError(OpGetJob, ErrProviderUnavailable, err)
+	}
+	if output.SynthesisTask == nil {
+		return nil, NewProviderError(providers.ProviderAWS, OpGetJob, ErrProviderUnavailable, false,
+			errors.New(fmt.Sprintf("AWS didn't return the speech synthesis task %s", job.Id)))
+	}
+	updated := AWSSynthesisTaskToT2SJob(*output.SynthesisTask)
+	if updated.Destination == "" {
+		updated.Destination = job.Destination
+	}
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = job.CreatedAt
+	}
+	return &updated, nil
+}
+
+// CancelT2SJob Polly synthesis tasks can't be canceled, so ErrT2SJobCancelNotSupported is always returned.
+func (a T2SAmazonWebServices) CancelT2SJob(ctx context.Context, job T2SJob) error {
+	return NewProviderError(providers.ProviderAWS, OpCancelJob, ErrT2SJobCancelNotSupported, false,
+		errors.New(fmt.Sprintf("speech synthesis task %s on AWS runs until it is finished", job.Id)))
+}
+
+// AWSSynthesisTaskToT2SJob converts a Polly synthesis task into a T2SJob.
+// Polly doesn't report the progress of a task, so the progress is -1 until the task is finished.
+func AWSSynthesisTaskToT2SJob(task types.SynthesisTask) T2SJob {
+	job := T2SJob{
+		Provider:      providers.ProviderAWS,
+		Id:            aws.ToString(task.TaskId),
+		Destination:   AWSOutputUriToS3Uri(aws.ToString(task.OutputUri)),
+		VoiceIdConfig: VoiceIdConfig{VoiceId: string(task.VoiceId), Engine: string(task.Engine)},
+		StatusReason:  aws.ToString(task.TaskStatusReason),
+		Progress:      -1,
+		CreatedAt:     aws.ToTime(task.CreationTime),
+	}
+	switch task.TaskStatus {
+	case types.TaskStatusScheduled:
+		job.Status = T2SJobStatusPending
+	case types.TaskStatusInProgress:
+		job.Status = T2SJobStatusRunning
+	case types.TaskStatusCompleted:
+		job.Status = T2SJobStatusSucceeded
+		job.Progress = 1
+	case types.TaskStatusFailed:
+		job.Status = T2SJobStatusFailed
+	}
+	return job
+}
+
+// AWSOutputUriToS3Uri converts the output URI of a synthesis task, which is an S3 object URL with the bucket in the
+// path (e.g. "https://s3.us-east-1.amazonaws.com/bucket/key.mp3"), into an S3 URI (e.g. "s3://bucket/key.mp3").
+// Other URIs are returned unchanged.
+func AWSOutputUriToS3Uri(outputUri string) string {
+	withoutPrefix, isHttps := strings.CutPrefix(outputUri, "https://")
+	host, path, hasPath := strings.Cut(withoutPrefix, "/")
+	if !isHttps || !hasPath || !strings.HasPrefix(host, "s3") {
+		return outputUri
+	}
+	return "s3://" + path
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+	"testing"
+)
+
+func TestAWSOutputUriToS3Uri(t *testing.T) {
+	tests := map[string]string{
+		"https://s3.us-east-1.amazonaws.com/bucket/folder/audio.1234.mp3": "s3://bucket/folder/audio.1234.mp3",
+		"s3://bucket/audio.mp3":            "s3://bucket/audio.mp3",
+		"https://example.com/bucket/a.mp3": "https://example.com/bucket/a.mp3",
+		"":                                 "",
+	}
+	for outputUri, want := range tests {
+		if got := AWSOutputUriToS3Uri(outputUri); got != want {
+			t.Errorf("AWSOutputUriToS3Uri(%s) = %s, wanted %s", outputUri, got, want)
+		}
+	}
+}
+
+func TestAWSSynthesisTaskToT2SJob(t *testing.T) {
+	task := types.SynthesisTask{
+		TaskId:     aws.String("1234"),
+		TaskStatus: types.TaskStatusInProgress,
+		OutputUri:  aws.String("https://s3.eu-central-1.amazonaws.com/bucket/audio.1234.mp3"),
+		VoiceId:    "Joanna",
+		Engine:     types.EngineNeural,
+	}
+	job := AWSSynthesisTaskToT2SJob(task)
+	if (job.Id != "1234") || (job.Status != T2SJobStatusRunning) || (job.Progress != -1) ||
+		(job.Destination != "s3://bucket/audio.1234.mp3") || (job.VoiceIdConfig != VoiceIdConfig{VoiceId: "Joanna", Engine: "neural"}) {
+		t.Errorf("Wrong job: %+v", job)
+	}
+
+	task.TaskStatus = types.TaskStatusFailed
+	task.TaskStatusReason = aws.String("Invalid SSML")
+	if job = AWSSynthesisTaskToT2SJob(task); (job.Status != T2SJobStatusFailed) || (job.StatusReason != "Invalid SSML") {
+		t.Errorf("Wrong failed job: %+v", job)
+	}
+}
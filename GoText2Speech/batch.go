@@ -0,0 +1,96 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"sync"
+)
+
+// T2SBatch synthesizes the given items on a pool of workers (see BatchOptions.Concurrency) and returns a channel
+// that receives the result of each item as soon as it is finished. Therefore, the results are not necessarily in the
+// order of the items (see BatchResult.Index). The channel receives exactly one result per item and is closed after
+// all items are finished.
+func (a *GoT2SClient) T2SBatch(items []BatchItem, opts BatchOptions) <-chan BatchResult {
+	return a.T2SBatchWithContext(context.Background(), items, opts)
+}
+
+// T2SBatchWithContext is the same as T2SBatch, but the given context is passed to the synthesis of all items.
+// If the context is canceled, items that weren't started yet are reported with the context's error.
+func (a *GoT2SClient) T2SBatchWithContext(ctx context.Context, items []BatchItem, opts BatchOptions) <-chan BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	// the channel can hold all results, so that the workers never block if the results are not read
+	results := make(chan BatchResult, len(items))
+	batchCtx, cancel := context.WithCancel(ctx)
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				if batchCtx.Err() != nil {
+					results <- skippedBatchResult(ctx, index, items[index])
+					continue
+				}
+				result := a.executeBatchItem(batchCtx, index, items[index])
+				if (result.Err != nil) && opts.StopOnError {
+					cancel()
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		for index := range items {
+			select {
+			case indices <- index:
+			case <-batchCtx.Done():
+				results <- skippedBatchResult(ctx, index, items[index])
+			}
+		}
+		close(indices)
+		wg.Wait()
+		cancel()
+		close(results)
+	}()
+	return results
+}
+
+// executeBatchItem synthesizes a single item of a batch.
+func (a *GoT2SClient) executeBatchItem(ctx context.Context, index int, item BatchItem) BatchResult {
+	batchResult := BatchResult{Index: index, Item: item}
+	if (item.Text == "") == (item.Source == "") {
+		batchResult.Err = errors.Join(ErrInvalidOptions, errors.New(fmt.Sprintf("invalid batch item %d: exactly one of text and source must be specified", index)))
+		return batchResult
+	}
+	if item.Source != "" {
+		batchResult.Result, batchResult.Err = a.T2SWithResult(ctx, item.Source, item.Destination, item.Options)
+	} else {
+		batchResult.Result, batchResult.Err = a.T2SDirectWithResult(ctx, item.Text, item.Destination, item.Options)
+	}
+	if batchResult.Err != nil {
+		batchResult.Err = errors.Join(errors.New(fmt.Sprintf("error while synthesizing batch item %d", index)), batchResult.Err)
+	}
+	return batchResult
+}
+
+// skippedBatchResult returns the result of an item that wasn't started, because the batch was stopped
+// (either by the given context of the batch or by a failed item).
+func skippedBatchResult(ctx context.Context, index int, item BatchItem) BatchResult {
+	err := ctx.Err()
+	if err == nil {
+		err = ErrBatchItemSkipped
+	}
+	return BatchResult{Index: index, Item: item, Err: err}
+}
@@ -0,0 +1,100 @@
+package GoText2Speech
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var errFakeSynthesis = errors.New("fake synthesis error")
+
+// createBatchItems creates the given number of items that are synthesized on the given provider.
+func createBatchItems(t *testing.T, provider fakeProvider, count int) []BatchItem {
+	dir := t.TempDir()
+	items := make([]BatchItem, count)
+	for i := range items {
+		options := testOptions()
+		options.Provider = provider.name
+		items[i] = BatchItem{
+			Text:        fmt.Sprintf("Text number %d", i),
+			Destination: filepath.Join(dir, fmt.Sprintf("audio_%d.mp3", i)),
+			Options:     options,
+		}
+	}
+	return items
+}
+
+// collectBatchResults reads all results of a batch and returns them ordered by index.
+func collectBatchResults(t *testing.T, results <-chan BatchResult, count int) []BatchResult {
+	ordered := make([]BatchResult, count)
+	received := 0
+	for result := range results {
+		if ordered[result.Index].Item.Destination != "" {
+			t.Errorf("Received more than one result for item %d", result.Index)
+		}
+		ordered[result.Index] = result
+		received++
+	}
+	if received != count {
+		t.Errorf("Received %d results, wanted %d", received, count)
+	}
+	return ordered
+}
+
+func TestT2SBatch(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	items := createBatchItems(t, fake, 10)
+	items[3].Text = ""
+
+	results := collectBatchResults(t, client.T2SBatch(items, BatchOptions{Concurrency: 3}), len(items))
+	for i, result := range results {
+		if i == 3 {
+			if result.Err == nil {
+				t.Errorf("Item without text and source didn't fail")
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("Item %d failed: %s", i, result.Err.Error())
+			continue
+		}
+		if content, _ := os.ReadFile(result.Result.Destination); string(content) != items[i].Text {
+			t.Errorf("Wrong audio data for item %d: %s", i, string(content))
+		}
+	}
+}
+
+func TestT2SBatchStopOnError(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	failing := newFakeProvider("FAKE_FAILING")
+	failing.failWith = errFakeSynthesis
+	registerFakeProviders(t, fake, failing)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	items := createBatchItems(t, fake, 5)
+	items[0].Options.Provider = failing.name
+
+	results := collectBatchResults(t, client.T2SBatch(items, BatchOptions{Concurrency: 1, StopOnError: true}), len(items))
+	if !errors.Is(results[0].Err, errFakeSynthesis) {
+		t.Errorf("Wrong error for failed item: %v", results[0].Err)
+	}
+	for _, result := range results[1:] {
+		if !errors.Is(result.Err, ErrBatchItemSkipped) {
+			t.Errorf("Item %d was not skipped: %v", result.Index, result.Err)
+		}
+	}
+
+	results = collectBatchResults(t, client.T2SBatch(items, BatchOptions{Concurrency: 1}), len(items))
+	if !errors.Is(results[0].Err, errFakeSynthesis) {
+		t.Errorf("Wrong error for failed item: %v", results[0].Err)
+	}
+	for _, result := range results[1:] {
+		if result.Err != nil {
+			t.Errorf("Item %d failed: %s", result.Index, result.Err.Error())
+		}
+	}
+}
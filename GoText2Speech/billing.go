@@ -0,0 +1,139 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sync"
+)
+
+// SetPriceTable sets the rates that are used to estimate the cost of syntheses (see EstimateCost and T2SResult.Billing).
+// By default, GetDefaultPriceTable is used.
+func (a *GoT2SClient) SetPriceTable(prices PriceTable) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.priceTable = prices.Clone()
+}
+
+// PriceTable returns a copy of the rates that are used to estimate the cost of syntheses (see SetPriceTable).
+func (a *GoT2SClient) PriceTable() PriceTable {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.priceTable.Clone()
+}
+
+// EstimateCost estimates the characters that the provider of the given options would bill for synthesizing the given
+// text and their cost according to the price table of the client (see SetPriceTable), without sending any requests.
+// The text and options are adjusted for the provider the same way as in T2SDirect and, if
+// TextToSpeechOptions.SplitLongText is true, the text is split into chunks.
+// The provider must be specified, because it can't be chosen without requests. If no voice ID is specified, the
+// pricing tier is determined by VoiceParamsConfig.Engine or, if it is empty, the standard voices of the provider.
+func (a *GoT2SClient) EstimateCost(text string, options TextToSpeechOptions) (*BillingRecord, error) {
+	if options.Provider == providers.ProviderUnspecified {
+		return nil, errors.Join(ErrInvalidOptions, errors.New("the provider must be specified to estimate the cost of a synthesis"))
+	}
+	provider, err := NewRegisteredProviderInstance(options.Provider)
+	if err != nil {
+		return nil, err
+	}
+	options, err = inferTextType(text, options)
+	if err != nil {
+		return nil, err
+	}
+	text, options, err = a.transformForProvider(a.contextWithLogger(context.Background()), provider, text, options)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := []string{text}
+	if options.SplitLongText {
+		limit := TextLengthLimitOf(provider).WithMaxLength(options.MaxChunkLength)
+		chunks, err = SplitTextIntoChunks(text, options.TextType, limit)
+		if err != nil {
+			return nil, errors.Join(errors.New("error while splitting text into chunks"), err)
+		}
+	}
+	usage := newBillingUsage()
+	for _, chunk := range chunks {
+		usage.add(provider, chunk, options)
+	}
+	record := a.billingRecord(usage, provider, options)
+	return &record, nil
+}
+
+// billingUsage accumulates the billed characters and requests of a single synthesis per provider.
+// It is passed to the synthesis via the context (see contextWithBillingUsage).
+type billingUsage struct {
+	mutex      sync.Mutex
+	characters map[providers.Provider]int
+	requests   map[providers.Provider]int
+}
+
+func newBillingUsage() *billingUsage {
+	return &billingUsage{
+		characters: make(map[providers.Provider]int),
+		requests:   make(map[providers.Provider]int),
+	}
+}
+
+// add adds a request that synthesized the given text on the given provider.
+func (u *billingUsage) add(provider T2SProvider, text string, options TextToSpeechOptions) {
+	policy := BillingPolicyOf(provider, options)
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.characters[options.Provider] += policy.BilledCharacters(text, options)
+	u.requests[options.Provider] += requestsPerSynthesis(provider, options)
+}
+
+// requestsPerSynthesis returns the number of requests that the given provider sends to synthesize a text with the
+// given options, i.e. 2 if the speech marks are requested separately (see BillingPolicy.BillSpeechMarks), otherwise 1.
+func requestsPerSynthesis(provider T2SProvider, options TextToSpeechOptions) int {
+	if BillingPolicyOf(provider, options).BillSpeechMarks && (len(options.SpeechMarkTypes) > 0) {
+		return 2
+	}
+	return 1
+}
+
+// billingUsageContextKey is the key of the billingUsage in a context (see contextWithBillingUsage).
+type billingUsageContextKey struct{}
+
+// contextWithBillingUsage returns a context that carries a new billingUsage, in which the requests of the synthesis
+// are recorded (see recordBilling).
+func contextWithBillingUsage(ctx context.Context) (context.Context, *billingUsage) {
+	usage := newBillingUsage()
+	return context.WithValue(ctx, billingUsageContextKey{}, usage), usage
+}
+
+// recordBilling records a successful request that synthesized the given text in the billingUsage of the given context.
+// Does nothing if the context doesn't carry a billingUsage.
+func recordBilling(ctx context.Context, provider T2SProvider, text string, options TextToSpeechOptions) {
+	if usage, ok := ctx.Value(billingUsageContextKey{}).(*billingUsage); ok {
+		usage.add(provider, text, options)
+	}
+}
+
+// billingRecord creates the billing record of the given provider from the given usage.
+func (a *GoT2SClient) billingRecord(usage *billingUsage, provider T2SProvider, options TextToSpeechOptions) BillingRecord {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	return NewBillingRecord(a.PriceTable(), options.Tenant, options.Provider, BillingPolicyOf(provider, options).Tier,
+		usage.characters[options.Provider], usage.requests[options.Provider])
+}
+
+// recordBillingMetrics adds the billed characters and the estimated cost of the given record to the metrics of the client.
+func (a *GoT2SClient) recordBillingMetrics(ctx context.Context, record BillingRecord) {
+	attributes := []attribute.KeyValue{
+		attribute.String(LogKeyProvider, string(record.Provider)),
+		attribute.String(AttributeTier, record.Tier),
+		attribute.String(AttributeTenant, record.Tenant),
+	}
+	tel := a.getTelemetry()
+	tel.billedCharacters.Add(ctx, int64(record.BilledCharacters), metric.WithAttributes(attributes...))
+	if record.Priced {
+		tel.estimatedCost.Add(ctx, record.EstimatedCost, metric.WithAttributes(
+			append(attributes, attribute.String(AttributeCurrency, record.Currency))...))
+	}
+}
@@ -0,0 +1,75 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"path/filepath"
+	"testing"
+)
+
+func TestT2SResultContainsBilling(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	client.SetSynthesisCache(NewMemorySynthesisCache(10, 0))
+	prices := PriceTable{Currency: "EUR"}
+	prices.SetRate(fake.name, "standard", 2)
+	client.SetPriceTable(prices)
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	options := testOptions()
+	options.Provider = fake.name
+	options.Tenant = "tenant-a"
+	result, err := client.T2SDirectWithResult(context.Background(), "Hello world", destination, options)
+	if err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	billing := result.Billing
+	if (billing.Tenant != "tenant-a") || (billing.Provider != fake.name) || (billing.Tier != "standard") ||
+		(billing.BilledCharacters != 11) || (billing.Requests != 1) || (billing.Currency != "EUR") || !billing.Priced {
+		t.Errorf("Unexpected billing record: %+v", billing)
+	}
+	if billing.EstimatedCost != 22.0/1000000 {
+		t.Errorf("Estimated cost is %g, wanted %g", billing.EstimatedCost, 22.0/1000000)
+	}
+
+	estimate, err := client.EstimateCost("Hello world", options)
+	if err != nil {
+		t.Fatalf("EstimateCost returned error: %s", err.Error())
+	}
+	if (estimate.BilledCharacters != billing.BilledCharacters) || (estimate.EstimatedCost != billing.EstimatedCost) {
+		t.Errorf("Estimate %+v doesn't match billing record %+v", estimate, billing)
+	}
+
+	// cached audio is not billed
+	result, err = client.T2SDirectWithResult(context.Background(), "Hello world", destination, options)
+	if err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	if (result.Billing.BilledCharacters != 0) || (result.Billing.EstimatedCost != 0) {
+		t.Errorf("Cached audio was billed: %+v", result.Billing)
+	}
+}
+
+func TestEstimateCostOfChunks(t *testing.T) {
+	registerFakeProviders(t, newFakeProvider("FAKE"))
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	options := testOptions()
+	options.Provider = "FAKE"
+	options.SplitLongText = true
+	options.MaxChunkLength = 20
+	estimate, err := client.EstimateCost("First sentence. Second sentence.", options)
+	if err != nil {
+		t.Fatalf("EstimateCost returned error: %s", err.Error())
+	}
+	if (estimate.Requests != 2) || estimate.Priced {
+		t.Errorf("Unexpected estimate: %+v", estimate)
+	}
+
+	options.Provider = ""
+	if _, err := client.EstimateCost("Hello", options); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("EstimateCost without provider returned %v, wanted ErrInvalidOptions", err)
+	}
+}
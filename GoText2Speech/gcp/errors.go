@@ -0,0 +1,55 @@
+package gcp
+
+import (
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// GCPErrorKind returns the kind of the given error of GCP (e.g. ErrThrottled for RESOURCE_EXHAUSTED), based on its
+// gRPC status code or HTTP status code. Returns nil if the error can't be classified.
+// GCP reports invalid SSML, unknown voices and other invalid requests all as INVALID_ARGUMENT, which is classified
+// as ErrInvalidOptions.
+func GCPErrorKind(err error) error {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.ResourceExhausted:
+			return ErrThrottled
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return ErrAuthentication
+		case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+			return ErrInvalidOptions
+		case codes.Unavailable, codes.Internal:
+			return ErrProviderUnavailable
+		}
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return ErrThrottled
+		case (apiErr.Code == http.StatusUnauthorized) || (apiErr.Code == http.StatusForbidden):
+			return ErrAuthentication
+		case apiErr.Code == http.StatusBadRequest:
+			return ErrInvalidOptions
+		case apiErr.Code >= http.StatusInternalServerError:
+			return ErrProviderUnavailable
+		}
+	}
+	return nil
+}
+
+// newProviderError wraps the given error of a request to GCP into a ProviderError. The kind is determined by
+// GCPErrorKind; if the error can't be classified, defaultKind is used.
+func (a T2SGoogleCloudPlatform) newProviderError(op string, defaultKind error, err error) *ProviderError {
+	kind := GCPErrorKind(err)
+	if kind == nil {
+		kind = defaultKind
+	}
+	return NewProviderError(providers.ProviderGCP, op, kind, a.IsRetryableError(err), err)
+}
@@ -0,0 +1,32 @@
+package gcp
+
+import (
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// IsRetryableError returns true if the given error is caused by throttling (RESOURCE_EXHAUSTED or HTTP status 429),
+// a temporary failure of GCP (e.g. UNAVAILABLE or HTTP status 503) or a network timeout.
+func (a T2SGoogleCloudPlatform) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.ResourceExhausted, codes.Unavailable, codes.Aborted, codes.Internal:
+			return true
+		}
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if (apiErr.Code == http.StatusTooManyRequests) || (apiErr.Code >= http.StatusInternalServerError) {
+			return true
+		}
+	}
+	return IsTemporaryNetworkError(err)
+}
@@ -0,0 +1,132 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"google.golang.org/api/googleapi"
+	"io"
+	"net/http"
+	"time"
+)
+
+// gcpTimepointingEndpoint The v1 API of GCP doesn't support time pointing. Therefore, the REST endpoint of the
+// v1beta1 API is used to request timepoints of SSML <mark> tags.
+// See https://cloud.google.com/text-to-speech/docs/reference/rest/v1beta1/text/synthesize
+const gcpTimepointingEndpoint = "https://texttospeech.googleapis.com/v1beta1/text:synthesize"
+
+type gcpSynthesizeRequest struct {
+	Input struct {
+		Ssml string `json:"ssml"`
+	} `json:"input"`
+	Voice struct {
+		LanguageCode string `json:"languageCode"`
+		Name         string `json:"name"`
+	} `json:"voice"`
+	AudioConfig struct {
+		AudioEncoding    string   `json:"audioEncoding"`
+		SpeakingRate     float64  `json:"speakingRate,omitempty"`
+		Pitch            float64  `json:"pitch,omitempty"`
+		VolumeGainDb     float64  `json:"volumeGainDb,omitempty"`
+		SampleRateHertz  int32    `json:"sampleRateHertz,omitempty"`
+		EffectsProfileId []string `json:"effectsProfileId,omitempty"`
+	} `json:"audioConfig"`
+	EnableTimePointing []string `json:"enableTimePointing"`
+}
+
+type gcpSynthesizeResponse struct {
+	AudioContent string `json:"audioContent"`
+	Timepoints   []struct {
+		MarkName    string  `json:"markName"`
+		TimeSeconds float64 `json:"timeSeconds"`
+	} `json:"timepoints"`
+}
+
+// ExecuteT2SWithSpeechMarks synthesizes the given text like ExecuteT2SDirect and additionally returns the speech
+// marks of the types specified in TextToSpeechOptions.SpeechMarkTypes.
+// GCP only reports timepoints of SSML <mark> tags. For word and sentence speech marks, <mark> tags are inserted in
+// front of every word or sentence (see AddSpeechMarkTags). Plain text is therefore transformed into SSML.
+// Viseme speech marks are not available on GCP.
+func (a T2SGoogleCloudPlatform) ExecuteT2SWithSpeechMarks(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error) {
+	if IncludesSpeechMarkType(options.SpeechMarkTypes, SpeechMarkTypeViseme) {
+		return nil, nil, errors.Join(ErrInvalidOptions, errors.New("the speech mark type "+string(SpeechMarkTypeViseme)+" is not available on GCP"))
+	}
+
+	ssmlText, insertedMarks := AddSpeechMarkTags(text, options.TextType, options.SpeechMarkTypes)
+
+	request := gcpSynthesizeRequest{EnableTimePointing: []string{"SSML_MARK"}}
+	request.Input.Ssml = ssmlText
+	languageCode, err := GCPLanguageCodeOfVoice(options.VoiceConfig.VoiceIdConfig.VoiceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	audioEncoding, err := gcpAudioEncoding(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Voice.LanguageCode = languageCode
+	request.Voice.Name = options.VoiceConfig.VoiceIdConfig.VoiceId
+	request.AudioConfig.AudioEncoding = audioEncoding.String()
+	request.AudioConfig.SpeakingRate = options.SpeakingRate
+	request.AudioConfig.Pitch = options.Pitch
+	request.AudioConfig.VolumeGainDb = options.Volume
+	request.AudioConfig.SampleRateHertz = options.SampleRate
+	request.AudioConfig.EffectsProfileId = options.AudioEffects
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while creating synthesize request with time pointing"), ErrInvalidOptions, err)
+	}
+
+	if a.httpClient == nil {
+		return nil, nil, a.httpClientErr
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, gcpTimepointingEndpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while creating synthesize request with time pointing"), ErrInvalidOptions, err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := a.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while synthesizing speech with time pointing on GCP"),
+			a.newProviderError(OpSpeechMarks, ErrSynthesisFailed, err))
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while reading synthesize response from GCP"),
+			a.newProviderError(OpSpeechMarks, ErrSynthesisFailed, err))
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, nil, errors.Join(errors.New("error while synthesizing speech with time pointing on GCP"),
+			a.newProviderError(OpSpeechMarks, ErrSynthesisFailed,
+				&googleapi.Error{Code: httpResponse.StatusCode, Message: httpResponse.Status, Body: string(responseBody)}))
+	}
+
+	var response gcpSynthesizeResponse
+	if err = json.Unmarshal(responseBody, &response); err != nil {
+		return nil, nil, errors.Join(errors.New("error while parsing synthesize response from GCP"),
+			NewProviderError(providers.ProviderGCP, OpSpeechMarks, ErrSynthesisFailed, false, err))
+	}
+	audioContent, err := base64.StdEncoding.DecodeString(response.AudioContent)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while decoding audio content from GCP"),
+			NewProviderError(providers.ProviderGCP, OpSpeechMarks, ErrSynthesisFailed, false, err))
+	}
+
+	timepoints := make([]SSMLMarkTimepoint, len(response.Timepoints))
+	for i, timepoint := range response.Timepoints {
+		timepoints[i] = SSMLMarkTimepoint{
+			MarkName: timepoint.MarkName,
+			Time:     time.Duration(timepoint.TimeSeconds * float64(time.Second)),
+		}
+	}
+	speechMarks := ResolveSpeechMarkTimepoints(timepoints, insertedMarks, options.SpeechMarkTypes)
+	return bytes.NewReader(audioContent), speechMarks, nil
+}
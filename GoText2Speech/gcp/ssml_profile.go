@@ -0,0 +1,83 @@
+package gcp
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+)
+
+// gcpMediaAttributes The attributes that are supported by the <media> element and, partially, by the <audio> element.
+var gcpMediaAttributes = map[string][]string{
+	"xml:id":      nil,
+	"begin":       nil,
+	"end":         nil,
+	"repeatCount": nil,
+	"repeatDur":   nil,
+	"soundLevel":  nil,
+	"fadeInDur":   nil,
+	"fadeOutDur":  nil,
+}
+
+// gcpSSMLProfile The SSML elements that are supported by GCP Text-to-Speech.
+// See https://cloud.google.com/text-to-speech/docs/ssml
+var gcpSSMLProfile = ssml.Profile{
+	Name: "GCP Text-to-Speech",
+	Elements: map[string]ssml.ElementProfile{
+		"speak": {Attributes: map[string][]string{"xml:lang": nil, "version": nil, "xmlns": nil}},
+		"break": {Attributes: map[string][]string{
+			"strength": {"none", "x-weak", "weak", "medium", "strong", "x-strong"},
+			"time":     nil,
+		}},
+		"say-as": {
+			Attributes: map[string][]string{
+				"interpret-as": {"currency", "telephone", "verbatim", "date", "characters", "cardinal", "ordinal",
+					"fraction", "expletive", "bleep", "unit", "time"},
+				"format":   nil,
+				"detail":   nil,
+				"language": nil,
+			},
+			RequiredAttributes: []string{"interpret-as"},
+			ValueTranslations: map[string]map[string]string{"interpret-as": {
+				"spell-out": "characters",
+				"digits":    "characters",
+				"number":    "cardinal",
+			}},
+		},
+		"audio": {
+			Attributes: map[string][]string{
+				"src": nil, "clipBegin": nil, "clipEnd": nil, "speed": nil,
+				"repeatCount": nil, "repeatDur": nil, "soundLevel": nil,
+			},
+			RequiredAttributes: []string{"src"},
+		},
+		"desc":     {},
+		"p":        {},
+		"s":        {},
+		"sub":      {Attributes: map[string][]string{"alias": nil}, RequiredAttributes: []string{"alias"}},
+		"mark":     {Attributes: map[string][]string{"name": nil}, RequiredAttributes: []string{"name"}},
+		"prosody":  {Attributes: map[string][]string{"volume": nil, "rate": nil, "pitch": nil}},
+		"emphasis": {Attributes: map[string][]string{"level": {"strong", "moderate", "none", "reduced"}}},
+		"par":      {},
+		"seq":      {},
+		"media":    {Attributes: gcpMediaAttributes},
+		"phoneme": {
+			Attributes:         map[string][]string{"alphabet": {"ipa", "x-sampa", "japanese-yomigana", "pinyin"}, "ph": nil},
+			RequiredAttributes: []string{"ph"},
+		},
+		"voice": {Attributes: map[string][]string{
+			"name": nil, "gender": nil, "variant": nil, "language": nil, "languages": nil, "required": nil, "ordering": nil,
+		}},
+		"lang": {Attributes: map[string][]string{"xml:lang": nil}, RequiredAttributes: []string{"xml:lang"}},
+	},
+	ElementTranslations: map[string]func(element *ssml.Element) []ssml.Node{
+		// GCP doesn't support breathing sounds -> use a short pause instead
+		"amazon:breath": func(element *ssml.Element) []ssml.Node {
+			breakElement := ssml.NewElement("break").SetAttribute("strength", "x-weak")
+			breakElement.SelfClosing = true
+			return []ssml.Node{breakElement}
+		},
+	},
+}
+
+// GetSSMLProfile returns the SSML elements and attributes that are supported by GCP Text-to-Speech.
+func (a T2SGoogleCloudPlatform) GetSSMLProfile() ssml.Profile {
+	return gcpSSMLProfile
+}
@@ -8,15 +8,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
 	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
 	"io"
 	"math"
+	"net/http"
 	"strings"
 )
 
 type T2SGoogleCloudPlatform struct {
 	credentials CredentialsHolder
 	t2sClient   *texttospeech.Client
+	// longAudioClient The client for long audio synthesis (see StartT2SJob).
+	// nil if it couldn't be created (see longAudioClientErr).
+	longAudioClient    *texttospeech.TextToSpeechLongAudioSynthesizeClient
+	longAudioClientErr error
+	// httpClient The authenticated client for the REST requests with time pointing (see ExecuteT2SWithSpeechMarks).
+	// nil if it couldn't be created (see httpClientErr).
+	httpClient    *http.Client
+	httpClientErr error
 }
 
 // AudioFormatToGCPValue Converts the given AudioFormat into a valid format that can be used on GCP.
@@ -38,7 +51,21 @@ func AudioFormatToGCPValue(format AudioFormat) (int16, error) {
 	case AudioFormatAlaw:
 		return 6, nil
 	default:
-		return 0, errors.New("the specified audio format " + string(format) + " is not available on GCP. Either choose a different audio format, choose a different provider or use the TextToSpeechOptions.OutputFormatRaw property to bypass format check.")
+		return 0, errors.Join(ErrUnsupportedFormat, errors.New("the specified audio format "+string(format)+" is not available on GCP. Either choose a different audio format, choose a different provider or use the TextToSpeechOptions.OutputFormatRaw property to bypass format check."))
+	}
+}
+
+// GCPGenderToVoiceGender Reverse of VoiceGenderToGCPGender function.
+func GCPGenderToVoiceGender(gender texttospeechpb.SsmlVoiceGender) VoiceGender {
+	switch gender {
+	case texttospeechpb.SsmlVoiceGender_FEMALE:
+		return VoiceGenderFemale
+	case texttospeechpb.SsmlVoiceGender_MALE:
+		return VoiceGenderMale
+	case texttospeechpb.SsmlVoiceGender_NEUTRAL:
+		return VoiceGenderNeutral
+	default:
+		return VoiceGenderUnspecified
 	}
 }
 
@@ -62,7 +89,7 @@ func VoiceGenderToGCPGender(gender VoiceGender) texttospeechpb.SsmlVoiceGender {
 // If enum value couldn't be found or if the specified rawFormat is undefined/empty, an error is returned.
 func GCPValueToAudioFormat(rawFormat int16) (AudioFormat, error) {
 	if rawFormat < 1 {
-		return "", errors.New("the specified rawFormat was undefined")
+		return "", errors.Join(ErrUnsupportedFormat, errors.New("the specified rawFormat was undefined"))
 	}
 	for _, audioFormat := range GetAllAudioFormats() {
 		a, _ := AudioFormatToGCPValue(audioFormat)
@@ -70,7 +97,7 @@ func GCPValueToAudioFormat(rawFormat int16) (AudioFormat, error) {
 			return audioFormat, nil
 		}
 	}
-	return "", errors.New(fmt.Sprintf("the specified rawFormat %d has no defined AudioFormat value.", rawFormat))
+	return "", errors.Join(ErrUnsupportedFormat, errors.New(fmt.Sprintf("the specified rawFormat %d has no defined AudioFormat value.", rawFormat)))
 }
 
 var gcpSupportedAudioFormats = []AudioFormat{
@@ -85,6 +112,35 @@ func (a T2SGoogleCloudPlatform) GetSupportedAudioFormats() []AudioFormat {
 	return gcpSupportedAudioFormats
 }
 
+// gcpTextLengthLimit GCP allows up to 5000 bytes of input (including SSML tags) per request.
+// See https://cloud.google.com/text-to-speech/quotas
+var gcpTextLengthLimit = TextLengthLimit{
+	MaxLength:      5000,
+	Unit:           TextLengthUnitBytes,
+	IgnoreSSMLTags: false,
+}
+
+func (a T2SGoogleCloudPlatform) GetTextLengthLimit() TextLengthLimit {
+	return gcpTextLengthLimit
+}
+
+// GetBillingPolicy GCP bills the characters of the text including SSML tags (except <mark> tags), by voice type.
+// See https://cloud.google.com/text-to-speech/pricing
+func (a T2SGoogleCloudPlatform) GetBillingPolicy(options TextToSpeechOptions) BillingPolicy {
+	tier := GCPVoiceType(options.VoiceConfig.VoiceIdConfig.VoiceId)
+	if tier == "" {
+		tier = options.VoiceConfig.VoiceParamsConfig.Engine
+	}
+	if tier == "" {
+		tier = "Standard"
+	}
+	return BillingPolicy{
+		Tier:                 tier,
+		BillSSMLTags:         true,
+		UnbilledSSMLElements: []string{"mark"},
+	}
+}
+
 func (a T2SGoogleCloudPlatform) TransformOptions(text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error) {
 	// on GCP, the pitch value is in range [-20.0, 20.0]. GoTextToSpeech pitch value is in [-1.0, 1.0].
 	options.Pitch = math.Min(math.Max(options.Pitch, -1), 1) * 20.0
@@ -104,57 +160,126 @@ func (a T2SGoogleCloudPlatform) IsURLonOwnStorage(url string) bool {
 	return IsGoogleUrl(url)
 }
 
-func (a T2SGoogleCloudPlatform) FindVoice(options TextToSpeechOptions) (*VoiceIdConfig, error) {
+// FindVoice finds a voice for GCP based on the given parameters (language and gender).
+// If a voice with the needed parameters is not found, returns nil and error.
+func (a T2SGoogleCloudPlatform) FindVoice(ctx context.Context, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	filter := VoiceFilterOf(options)
+	// GCP doesn't have engines
+	filter.Engine = ""
+	voices, err := a.ListVoices(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return a.SelectVoice(voices, options)
+}
+
+// SelectVoice chooses the best voice of the given voices for the language, gender and preferences of the options.
+// The engine of the options is ignored, because GCP doesn't have engines.
+// Voice types (e.g. "Wavenet") in the preferred engines of the options are considered.
+func (a T2SGoogleCloudPlatform) SelectVoice(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	options.VoiceConfig.VoiceParamsConfig.Engine = ""
+	voiceIdConfig, err := FindVoiceInList(voices, options)
+	if err != nil {
+		return nil, err
+	}
+	// the voice type is part of the voice ID
+	voiceIdConfig.Engine = ""
+	return voiceIdConfig, nil
+}
+
+// ListVoices lists all voices on GCP that match the given filter.
+// Since GCP doesn't have engines, the voice type (e.g. "Wavenet") is used as engine (see Voice.SupportedEngines).
+func (a T2SGoogleCloudPlatform) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
 	req := &texttospeechpb.ListVoicesRequest{
-		LanguageCode: options.VoiceConfig.VoiceParamsConfig.LanguageCode,
+		LanguageCode: filter.LanguageCode,
 	}
-	resp, err := a.t2sClient.ListVoices(context.Background(), req)
+	resp, err := a.t2sClient.ListVoices(ctx, req)
 	if err != nil {
-		return nil, errors.Join(errors.New("error while listing available voices for language "+options.VoiceConfig.VoiceParamsConfig.LanguageCode), err)
+		return nil, errors.Join(errors.New("error while listing available voices for language "+filter.LanguageCode),
+			a.newProviderError(OpListVoices, ErrProviderUnavailable, err))
 	}
 
-	gcpGender := VoiceGenderToGCPGender(options.VoiceConfig.VoiceParamsConfig.Gender)
-	var gcpVoice *texttospeechpb.Voice = nil
+	voices := make([]Voice, 0, len(resp.GetVoices()))
 	for _, voice := range resp.GetVoices() {
-		if voice.GetSsmlGender() == gcpGender {
-			gcpVoice = voice
-		}
+		voices = append(voices, GCPVoiceToVoice(voice))
 	}
+	return FilterVoices(voices, filter), nil
+}
 
-	if gcpVoice == nil {
-		errText := fmt.Sprintf("error: No voice found for language %s and gender %s\n",
-			options.VoiceConfig.VoiceParamsConfig.LanguageCode,
-			options.VoiceConfig.VoiceParamsConfig.Gender.String())
-		return nil, errors.New(errText)
+// GCPVoiceToVoice converts the given GCP voice into a provider-independent Voice.
+func GCPVoiceToVoice(voice *texttospeechpb.Voice) Voice {
+	return Voice{
+		Provider:          providers.ProviderGCP,
+		Id:                voice.GetName(),
+		Name:              voice.GetName(),
+		LanguageCodes:     voice.GetLanguageCodes(),
+		Gender:            GCPGenderToVoiceGender(voice.GetSsmlGender()),
+		SupportedEngines:  []string{GCPVoiceType(voice.GetName())},
+		NaturalSampleRate: voice.GetNaturalSampleRateHertz(),
 	}
+}
 
-	fmt.Printf("Voice found for language %s and gender %s: %s\n",
-		options.VoiceConfig.VoiceParamsConfig.LanguageCode,
-		options.VoiceConfig.VoiceParamsConfig.Gender.String(),
-		gcpVoice.GetName())
-
-	voiceConfig := &VoiceIdConfig{
-		VoiceId: gcpVoice.GetName(),
+// GCPVoiceType returns the type of the voice with the given name,
+// e.g. "Wavenet" for "en-US-Wavenet-A" or "Chirp-HD" for "en-US-Chirp-HD-D".
+func GCPVoiceType(voiceName string) string {
+	parts := strings.Split(voiceName, "-")
+	if len(parts) < 4 {
+		return ""
 	}
-	return voiceConfig, nil
+	return strings.Join(parts[2:len(parts)-1], "-")
 }
 
 func (a T2SGoogleCloudPlatform) CreateServiceClient(credentials CredentialsHolder, region string) (T2SProvider, error) {
 	ctx := context.Background()
-	client, err := texttospeech.NewClient(ctx)
+	// the Google credentials are used by both clients if available, otherwise the application default credentials
+	var clientOptions []option.ClientOption
+	if credentials.GoogleCredentials != nil {
+		clientOptions = append(clientOptions, option.WithCredentials(credentials.GoogleCredentials))
+	}
+	client, err := texttospeech.NewClient(ctx, clientOptions...)
 	if err != nil {
-		return a, err
+		return a, a.newProviderError(OpCreateClient, ErrProviderUnavailable, err)
 	}
+	// Failed synthesis requests are retried by the client (see RetryPolicy), so the retries of the client library
+	// are disabled for SynthesizeSpeech.
+	client.CallOptions.SynthesizeSpeech = nil
+
+	a.credentials = credentials
 	a.t2sClient = client
+	// synthesis doesn't need the long audio client, so an error is only returned by the job operations (see StartT2SJob)
+	a.longAudioClient, err = texttospeech.NewTextToSpeechLongAudioSynthesizeClient(ctx, clientOptions...)
+	if err != nil {
+		a.longAudioClientErr = errors.Join(errors.New("error while creating GCP long audio synthesis client"),
+			a.newProviderError(OpCreateClient, ErrProviderUnavailable, err))
+	}
+	a.httpClient, a.httpClientErr = newGCPHTTPClient(ctx, credentials)
 	return a, nil
 }
 
+// newGCPHTTPClient creates an HTTP client that authenticates requests with the Google credentials of the given holder,
+// or with the application default credentials if the holder has no Google credentials.
+func newGCPHTTPClient(ctx context.Context, credentials CredentialsHolder) (*http.Client, error) {
+	if credentials.GoogleCredentials != nil {
+		return oauth2.NewClient(ctx, credentials.GoogleCredentials.TokenSource), nil
+	}
+	httpClient, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, errors.Join(errors.New("error while creating authenticated HTTP client for GCP"),
+			NewProviderError(providers.ProviderGCP, OpSpeechMarks, ErrAuthentication, false, err))
+	}
+	return httpClient, nil
+}
+
 func (a T2SGoogleCloudPlatform) AddFileExtensionToDestinationIfNeeded(options TextToSpeechOptions, outputFormatRaw any, destination string) (string, error) {
 	if options.AddFileExtension {
-		audioFormat, err := GCPValueToAudioFormat(outputFormatRaw.(int16))
+		options.OutputFormatRaw = outputFormatRaw
+		audioEncoding, err := gcpAudioEncoding(options)
+		var audioFormat AudioFormat
+		if err == nil {
+			audioFormat, err = GCPValueToAudioFormat(int16(audioEncoding))
+		}
 		if err != nil {
-			fmt.Printf("%s\n", err.Error())
-			errNew := errors.New(fmt.Sprintf("No file extension found for the specified raw audio format %d. No file extension is added to file name.\n", outputFormatRaw.(int)))
+			errNew := errors.New(fmt.Sprintf("No file extension found for the specified raw audio format %v. No file extension is added to file name.\n", outputFormatRaw))
 			return destination, errors.Join(err, errNew)
 		} else {
 			audioFormatStr := AudioFormatToFileExtension(audioFormat)
@@ -167,11 +292,41 @@ func (a T2SGoogleCloudPlatform) AddFileExtensionToDestinationIfNeeded(options Te
 }
 
 func GetBucketAndKeyFromCLoudStorageDestination(destination string) (string, string, error) {
-	storageObj := ParseUrlToGoStorageObject(destination)
+	if !IsGoogleUrl(destination) {
+		return "", "", errors.Join(ErrInvalidDestination, errors.New(fmt.Sprintf("the destination %s is not a Cloud Storage URL", destination)))
+	}
+	storageObj, err := ParseUrlToGoStorageObject(destination)
+	if err != nil {
+		return "", "", err
+	}
 	return storageObj.Bucket, storageObj.Key, nil
 }
 
-func (a T2SGoogleCloudPlatform) ExecuteT2SDirect(text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+func (a T2SGoogleCloudPlatform) ExecuteT2SDirect(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+	speechRequest, err := createSynthesizeSpeechRequest(text, options)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.t2sClient.SynthesizeSpeech(ctx, speechRequest)
+	if err != nil {
+		return nil, a.newProviderError(OpSynthesize, ErrSynthesisFailed, err)
+	}
+
+	stream := bytes.NewReader(result.GetAudioContent())
+	return stream, nil
+}
+
+// createSynthesizeSpeechRequest creates the SynthesizeSpeech request from the given text and options.
+func createSynthesizeSpeechRequest(text string, options TextToSpeechOptions) (*texttospeechpb.SynthesizeSpeechRequest, error) {
+	languageCode, err := GCPLanguageCodeOfVoice(options.VoiceConfig.VoiceIdConfig.VoiceId)
+	if err != nil {
+		return nil, err
+	}
+	audioEncoding, err := gcpAudioEncoding(options)
+	if err != nil {
+		return nil, err
+	}
+
 	var input *texttospeechpb.SynthesisInput = nil
 	if options.TextType == TextTypeSsml {
 		inputSource := &texttospeechpb.SynthesisInput_Ssml{
@@ -189,49 +344,62 @@ func (a T2SGoogleCloudPlatform) ExecuteT2SDirect(text string, destination string
 		}
 	}
 
-	req := texttospeechpb.SynthesizeSpeechRequest{
+	return &texttospeechpb.SynthesizeSpeechRequest{
 		Input: input,
 		Voice: &texttospeechpb.VoiceSelectionParams{
-			LanguageCode: options.VoiceConfig.VoiceIdConfig.VoiceId[0:5],
+			LanguageCode: languageCode,
 			Name:         options.VoiceConfig.VoiceIdConfig.VoiceId,
 		},
 		AudioConfig: &texttospeechpb.AudioConfig{
-			AudioEncoding:    texttospeechpb.AudioEncoding(options.OutputFormatRaw.(int16)),
+			AudioEncoding:    audioEncoding,
 			SpeakingRate:     options.SpeakingRate,
 			Pitch:            options.Pitch,
 			VolumeGainDb:     options.Volume,
 			SampleRateHertz:  options.SampleRate,
 			EffectsProfileId: options.AudioEffects,
 		},
-	}
+	}, nil
+}
 
-	result, err := a.t2sClient.SynthesizeSpeech(context.Background(), &req)
-	if err != nil {
-		return nil, err
+// GCPLanguageCodeOfVoice returns the language code of the voice with the given name, i.e. the text before the third
+// "-", e.g. "en-US" for "en-US-Wavenet-A" or "cmn-CN" for "cmn-CN-Standard-A".
+func GCPLanguageCodeOfVoice(voiceName string) (string, error) {
+	parts := strings.SplitN(voiceName, "-", 3)
+	if (len(parts) < 3) || (parts[0] == "") || (parts[1] == "") {
+		return "", errors.Join(ErrInvalidOptions, errors.New(fmt.Sprintf("the voice ID '%s' is not a valid GCP voice name", voiceName)))
 	}
+	return parts[0] + "-" + parts[1], nil
+}
 
-	stream := bytes.NewReader(result.GetAudioContent())
-	return stream, nil
+// gcpAudioEncoding returns the audio encoding of the given options (see TextToSpeechOptions.OutputFormatRaw).
+func gcpAudioEncoding(options TextToSpeechOptions) (texttospeechpb.AudioEncoding, error) {
+	switch outputFormatRaw := options.OutputFormatRaw.(type) {
+	case int16:
+		return texttospeechpb.AudioEncoding(outputFormatRaw), nil
+	case texttospeechpb.AudioEncoding:
+		return outputFormatRaw, nil
+	default:
+		return texttospeechpb.AudioEncoding_AUDIO_ENCODING_UNSPECIFIED, errors.Join(ErrUnsupportedFormat, errors.New(fmt.Sprintf(
+			"the raw output format must be an int16 value of texttospeechpb.AudioEncoding, but was %T", options.OutputFormatRaw)))
+	}
 }
 
-func (a T2SGoogleCloudPlatform) UploadFile(fileContents io.Reader, destination string) error {
+func (a T2SGoogleCloudPlatform) UploadFile(ctx context.Context, fileContents io.Reader, destination string) error {
 	bucket, key, destinationFormatErr := GetBucketAndKeyFromCLoudStorageDestination(destination)
 	if destinationFormatErr != nil {
 		return destinationFormatErr
 	}
-	return a.uploadFileToCS(fileContents, bucket, key)
+	return a.uploadFileToCS(ctx, fileContents, bucket, key)
 }
 
 // uploadFileToCS takes a file stream and uploads it to Google Cloud Storage using the given CS bucket and key.
 // Inspired by Google Cloud Storage examples (https://cloud.google.com/storage/docs/uploading-objects#permissions-client-libraries).
-func (a T2SGoogleCloudPlatform) uploadFileToCS(fileContents io.Reader, bucket string, key string) error {
-
-	fmt.Printf("Uploading file to %s/%s...\n", bucket, key)
+func (a T2SGoogleCloudPlatform) uploadFileToCS(ctx context.Context, fileContents io.Reader, bucket string, key string) error {
 
-	ctx := context.Background()
 	client, err := storage.NewClient(ctx)
 	if err != nil {
-		return errors.Join(errors.New(fmt.Sprintf("Error while uploading file '%s' on bucket '%s' to Google Cloud Storage.", key, bucket)), err)
+		return errors.Join(errors.New(fmt.Sprintf("Error while uploading file '%s' on bucket '%s' to Google Cloud Storage.", key, bucket)),
+			a.newProviderError(OpUpload, ErrUploadFailed, err))
 	}
 
 	defer client.Close()
@@ -239,23 +407,25 @@ func (a T2SGoogleCloudPlatform) uploadFileToCS(fileContents io.Reader, bucket st
 	cloudObj := client.Bucket(bucket).Object(key)
 
 	wc := cloudObj.NewWriter(ctx)
-	fmt.Printf("fileContents: %s", fileContents)
-	fmt.Printf("Created writer\n")
 	if _, err = io.Copy(wc, fileContents); err != nil {
-		return fmt.Errorf("io.Copy: %w", err)
+		return a.newProviderError(OpUpload, ErrUploadFailed, fmt.Errorf("io.Copy: %w", err))
 	}
 	if err := wc.Close(); err != nil {
-		return fmt.Errorf("Writer.Close: %w", err)
+		return a.newProviderError(OpUpload, ErrUploadFailed, fmt.Errorf("Writer.Close: %w", err))
 	}
-	fmt.Printf("file uploaded to, %s/%s\n", bucket, key)
+	LoggerFromContext(ctx).Debug("Uploaded file to Cloud Storage", LogKeyDestination, "gs://"+bucket+"/"+key)
 	return nil
 
 }
 
 func (a T2SGoogleCloudPlatform) CloseServiceClient() error {
+	// the clients don't exist if creating them failed
 	if a.t2sClient == nil {
-		fmt.Println("Warning: Couldn't close GCP T2S service client, because client doesn't exist.")
 		return nil
 	}
-	return a.t2sClient.Close()
+	err := a.t2sClient.Close()
+	if a.longAudioClient != nil {
+		err = errors.Join(err, a.longAudioClient.Close())
+	}
+	return err
 }
@@ -0,0 +1,142 @@
+package gcp
+
+import (
+	longrunningpb "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// gcpT2SJobTextLengthLimit Long audio synthesis accepts up to 1 MB of input.
+// See https://cloud.google.com/text-to-speech/docs/create-audio-text-long-audio-synthesis
+var gcpT2SJobTextLengthLimit = TextLengthLimit{
+	MaxLength:      1000000,
+	Unit:           TextLengthUnitBytes,
+	IgnoreSSMLTags: false,
+}
+
+// gcpT2SJobLocation The location of the long audio synthesis operations.
+const gcpT2SJobLocation = "global"
+
+func (a T2SGoogleCloudPlatform) GetT2SJobTextLengthLimit() TextLengthLimit {
+	return gcpT2SJobTextLengthLimit
+}
+
+// StartT2SJob starts a long audio synthesis (SynthesizeLongAudio) that writes the audio file to the given Cloud Storage
+// destination. The operation is started in the project of the Google credentials of the client (or, if the client has
+// no Google credentials, of the application default credentials).
+// Long audio synthesis only supports the LINEAR16 output format (AudioFormatLinear16), so other formats
+// are rejected with ErrUnsupportedFormat.
+func (a T2SGoogleCloudPlatform) StartT2SJob(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SJob, error) {
+	speechRequest, err := createSynthesizeSpeechRequest(text, options)
+	if err != nil {
+		return nil, err
+	}
+	if encoding := speechRequest.AudioConfig.AudioEncoding; encoding != texttospeechpb.AudioEncoding_LINEAR16 {
+		return nil, errors.Join(ErrUnsupportedFormat, errors.New(fmt.Sprintf("long audio synthesis on GCP only supports the LINEAR16 format, not %s", encoding)))
+	}
+	if a.longAudioClient == nil {
+		return nil, a.longAudioClientErr
+	}
+	projectId, err := a.projectId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bucket, key, _ := GetBucketAndKeyFromCLoudStorageDestination(destination)
+
+	operation, err := a.longAudioClient.SynthesizeLongAudio(ctx, &texttospeechpb.SynthesizeLongAudioRequest{
+		Parent:       fmt.Sprintf("projects/%s/locations/%s", projectId, gcpT2SJobLocation),
+		Input:        speechRequest.Input,
+		AudioConfig:  speechRequest.AudioConfig,
+		OutputGcsUri: fmt.Sprintf("gs://%s/%s", bucket, key),
+		Voice:        speechRequest.Voice,
+	})
+	if err != nil {
+		return nil, errors.Join(errors.New("error while starting long audio synthesis on GCP"),
+			a.newProviderError(OpStartJob, ErrSynthesisFailed, err))
+	}
+	return &T2SJob{
+		Provider:      providers.ProviderGCP,
+		Id:            operation.Name(),
+		Destination:   destination,
+		VoiceIdConfig: options.VoiceConfig.VoiceIdConfig,
+		Status:        T2SJobStatusPending,
+		Progress:      0,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// GetT2SJob polls the long audio synthesis operation.
+func (a T2SGoogleCloudPlatform) GetT2SJob(ctx context.Context, job T2SJob) (*T2SJob, error) {
+	if a.longAudioClient == nil {
+		return nil, a.longAudioClientErr
+	}
+	operation := a.longAudioClient.SynthesizeLongAudioOperation(job.Id)
+	_, pollErr := operation.Poll(ctx)
+	if (pollErr != nil) && !operation.Done() { // the status couldn't be requested
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while requesting long audio synthesis %s on GCP", job.Id)),
+			a.newProviderError(OpGetJob, ErrProviderUnavailable, pollErr))
+	}
+
+	updated := job
+	if metadata, _ := operation.Metadata(); metadata != nil {
+		updated.Progress = metadata.GetProgressPercentage() / 100
+		if startTime := metadata.GetStartTime(); startTime != nil {
+			updated.CreatedAt = startTime.AsTime()
+		}
+	}
+	switch {
+	case !operation.Done():
+		updated.Status = T2SJobStatusRunning
+	case pollErr == nil:
+		updated.Status = T2SJobStatusSucceeded
+		updated.Progress = 1
+	case status.Code(pollErr) == codes.Canceled:
+		updated.Status = T2SJobStatusCanceled
+		updated.StatusReason = pollErr.Error()
+	default:
+		updated.Status = T2SJobStatusFailed
+		updated.StatusReason = pollErr.Error()
+	}
+	return &updated, nil
+}
+
+// CancelT2SJob cancels the long audio synthesis operation. The cancellation is asynchronous, i.e. the job might still
+// succeed (see GetT2SJob).
+func (a T2SGoogleCloudPlatform) CancelT2SJob(ctx context.Context, job T2SJob) error {
+	if a.longAudioClient == nil {
+		return a.longAudioClientErr
+	}
+	err := a.longAudioClient.LROClient.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: job.Id})
+	if err != nil {
+		return errors.Join(errors.New(fmt.Sprintf("error while canceling long audio synthesis %s on GCP", job.Id)),
+			a.newProviderError(OpCancelJob, ErrProviderUnavailable, err))
+	}
+	return nil
+}
+
+// projectId returns the ID of the project of the Google credentials of the client,
+// or of the application default credentials if the client has no Google credentials.
+func (a T2SGoogleCloudPlatform) projectId(ctx context.Context) (string, error) {
+	credentials := a.credentials.GoogleCredentials
+	if credentials == nil {
+		var err error
+		credentials, err = google.FindDefaultCredentials(ctx)
+		if err != nil {
+			return "", errors.Join(errors.New("error while finding the default Google credentials"),
+				NewProviderError(providers.ProviderGCP, OpStartJob, ErrAuthentication, false, err))
+		}
+	}
+	if credentials.ProjectID == "" {
+		return "", NewProviderError(providers.ProviderGCP, OpStartJob, ErrAuthentication, false,
+			errors.New("the Google credentials don't specify a project, which is needed for long audio synthesis"))
+	}
+	return credentials.ProjectID, nil
+}
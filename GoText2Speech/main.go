@@ -2,31 +2,73 @@ package GoText2Speech
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/FaaSTools/GoStorage/gostorage"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/audio"
 	ts2_aws "github.com/FaaSTools/GoText2Speech/GoText2Speech/aws"
 	ts2_gcp "github.com/FaaSTools/GoText2Speech/GoText2Speech/gcp"
 	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
 	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/subtitles"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
+// GoT2SClient synthesizes speech on the registered providers. A GoT2SClient is safe for concurrent use by multiple
+// goroutines and should be reused, because the service clients of the providers are only created once per client
+// (when the provider is used for the first time).
 type GoT2SClient struct {
-	providerInstances map[providers.Provider]*T2SProvider
-	region            string
-	credentials       *CredentialsHolder
-	tempBuckets       map[providers.Provider]string
-	DeleteTempFile    bool
-	gostorageClient   *gostorage.GoStorage
+	region      string
+	credentials *CredentialsHolder
+	// DeleteTempFile If true, temporary files are deleted after they were uploaded. Must not be changed while the
+	// client is in use.
+	DeleteTempFile bool
+	rateLimiters   *ProviderRateLimiters
+
+	// instancesMutex guards providerInstances
+	instancesMutex    sync.Mutex
+	providerInstances map[providers.Provider]*providerInstance
+
+	// mutex guards the settings that can be changed while the client is in use
+	mutex          sync.RWMutex
+	tempBuckets    map[providers.Provider]string
+	voiceCatalog   *VoiceCatalog
+	retryPolicy    RetryPolicy
+	synthesisCache SynthesisCache
+	logger         Logger
+	logUserText    bool
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry
+	priceTable     PriceTable
+
+	gostorageOnce   sync.Once
+	gostorageClient *gostorage.GoStorage
 }
 
-func CreateGoT2SClient(credentials *CredentialsHolder, region string) GoT2SClient {
+// providerInstance is the instance of a provider of a GoT2SClient, whose service client is created exactly once.
+type providerInstance struct {
+	once     sync.Once
+	provider T2SProvider
+	err      error
+}
+
+// CreateGoT2SClient creates a client with the given credentials for the given region. If credentials is nil,
+// the credentials are loaded from the default location (see gostorage.LoadCredentialsFromDefaultLocation).
+func CreateGoT2SClient(credentials *CredentialsHolder, region string) *GoT2SClient {
 	if credentials == nil {
 		awsCred, gcpCred := gostorage.LoadCredentialsFromDefaultLocation()
 		awsCred = &aws.Credentials{
@@ -38,36 +80,103 @@ func CreateGoT2SClient(credentials *CredentialsHolder, region string) GoT2SClien
 			GoogleCredentials: gcpCred,
 		}
 	}
-	return GoT2SClient{
-		providerInstances: make(map[providers.Provider]*T2SProvider),
+	return &GoT2SClient{
+		providerInstances: make(map[providers.Provider]*providerInstance),
 		tempBuckets:       make(map[providers.Provider]string),
 		credentials:       credentials,
 		region:            region,
 		DeleteTempFile:    true,
+		voiceCatalog:      NewVoiceCatalog(DefaultVoiceCatalogTTL),
+		retryPolicy:       GetDefaultRetryPolicy(),
+		rateLimiters:      NewProviderRateLimiters(),
+		logger:            NopLogger{},
+		tracerProvider:    trace.NewNoopTracerProvider(),
+		meterProvider:     noop.NewMeterProvider(),
+		telemetry:         newNoopTelemetry(),
+		priceTable:        GetDefaultPriceTable(),
 	}
 }
 
-func (a GoT2SClient) getProviderInstance(provider providers.Provider) T2SProvider {
-	if a.providerInstances[provider] == nil {
-		prov := CreateProviderInstance(provider)
-		var err error = nil
+func init() {
+	_ = RegisterProvider(providers.ProviderAWS, func() T2SProvider {
+		return ts2_aws.T2SAmazonWebServices{}
+	})
+	_ = RegisterProvider(providers.ProviderGCP, func() T2SProvider {
+		return ts2_gcp.T2SGoogleCloudPlatform{}
+	})
+}
+
+// getProviderInstance returns the instance of the given provider. The instance and its service client are created
+// when the provider is requested for the first time. Concurrent requests wait until the instance was created.
+func (a *GoT2SClient) getProviderInstance(provider providers.Provider) (T2SProvider, error) {
+	a.instancesMutex.Lock()
+	instance, ok := a.providerInstances[provider]
+	if !ok {
+		instance = &providerInstance{}
+		a.providerInstances[provider] = instance
+	}
+	a.instancesMutex.Unlock()
+
+	instance.once.Do(func() {
+		prov, err := NewRegisteredProviderInstance(provider)
+		if err != nil {
+			instance.err = err
+			return
+		}
 		prov, err = prov.CreateServiceClient(*a.credentials, a.region)
 		if err != nil {
-			fmt.Printf("Error while creating service client: %s\n", err)
+			a.Logger().Error("Error while creating service client", LogKeyProvider, provider, LogKeyError, err)
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				err = NewProviderError(provider, OpCreateClient, ErrProviderUnavailable, false, err)
+			}
+			instance.err = err
+			return
 		}
-		a.providerInstances[provider] = &prov
+		instance.provider = prov
+	})
+	if instance.err != nil {
+		// the instance is removed, so that creating it is tried again next time (e.g. after registering the provider)
+		a.instancesMutex.Lock()
+		if a.providerInstances[provider] == instance {
+			delete(a.providerInstances, provider)
+		}
+		a.instancesMutex.Unlock()
+		return nil, instance.err
 	}
-	return *a.providerInstances[provider]
+	return instance.provider, nil
 }
 
-func (a GoT2SClient) CloseProviderClient(provider providers.Provider) error {
-	return a.getProviderInstance(provider).CloseServiceClient()
+// CloseProviderClient closes the service client of the given provider. If the provider is used afterwards,
+// a new service client is created.
+func (a *GoT2SClient) CloseProviderClient(provider providers.Provider) error {
+	a.instancesMutex.Lock()
+	instance, ok := a.providerInstances[provider]
+	delete(a.providerInstances, provider)
+	a.instancesMutex.Unlock()
+	if !ok {
+		return nil
+	}
+	// waits until the instance was created, if it is created concurrently
+	instance.once.Do(func() {})
+	if instance.provider == nil {
+		return nil
+	}
+	return instance.provider.CloseServiceClient()
 }
 
-func (a GoT2SClient) CloseAllProviderClients() error {
+// CloseAllProviderClients closes the service clients of all providers that were used by the client.
+func (a *GoT2SClient) CloseAllProviderClients() error {
+	a.instancesMutex.Lock()
+	instancedProviders := make([]providers.Provider, 0, len(a.providerInstances))
+	for provider := range a.providerInstances {
+		instancedProviders = append(instancedProviders, provider)
+	}
+	a.instancesMutex.Unlock()
+
 	var allErrors error = nil
-	for _, instance := range a.providerInstances {
-		err := (*instance).CloseServiceClient()
+	for _, provider := range instancedProviders {
+		err := a.CloseProviderClient(provider)
 		if err != nil {
 			if allErrors == nil {
 				allErrors = err
@@ -79,47 +188,524 @@ func (a GoT2SClient) CloseAllProviderClients() error {
 	return allErrors
 }
 
-func (a GoT2SClient) SetTempBucket(provider providers.Provider, tempBucket string) {
+func (a *GoT2SClient) SetTempBucket(provider providers.Provider, tempBucket string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 	a.tempBuckets[provider] = tempBucket
 }
 
+// SetRetryPolicy sets the policy for retrying failed synthesis requests and uploads (see RetryPolicy).
+// By default, GetDefaultRetryPolicy is used. Use NoRetryPolicy to disable retries.
+func (a *GoT2SClient) SetRetryPolicy(policy RetryPolicy) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.retryPolicy = policy
+}
+
+// RetryPolicy returns the policy for retrying failed synthesis requests and uploads (see SetRetryPolicy).
+func (a *GoT2SClient) RetryPolicy() RetryPolicy {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.retryPolicy
+}
+
+// SetRateLimit limits the synthesis requests that are sent to the given provider (see RateLimit).
+// The limit is shared by all goroutines that use the client. Each retry counts as a request, and so does the separate
+// speech marks request on AWS. A request counts as in flight until its audio has been read or closed.
+// Only synthesis requests (including StartT2SJob) are limited: listing voices, uploads and requesting the status of
+// T2S jobs are not.
+// By default, requests are not limited.
+func (a *GoT2SClient) SetRateLimit(provider providers.Provider, limit RateLimit) {
+	a.rateLimiters.Set(provider, limit)
+}
+
+// RemoveRateLimit removes the limit of the given provider that was set with SetRateLimit.
+func (a *GoT2SClient) RemoveRateLimit(provider providers.Provider) {
+	a.rateLimiters.Remove(provider)
+}
+
+// SetVoiceCatalog sets the voice catalog that caches the voices of the providers (see VoiceCatalog).
+// By default, each client has its own catalog with DefaultVoiceCatalogTTL. The same catalog can be shared by
+// multiple clients. If catalog is nil, voices are not cached and the providers are queried every time.
+func (a *GoT2SClient) SetVoiceCatalog(catalog *VoiceCatalog) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.voiceCatalog = catalog
+}
+
+// VoiceCatalog returns the voice catalog of the client (or nil if voices are not cached),
+// e.g. to load or save snapshots.
+func (a *GoT2SClient) VoiceCatalog() *VoiceCatalog {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.voiceCatalog
+}
+
+// SetSynthesisCache sets the cache that stores synthesized audio (see SynthesisCache), so that the same text isn't
+// synthesized again with the same voice and options. The cache is keyed by the final text and options that are sent
+// to the provider (see SynthesisCacheKey). Syntheses with speech marks are not cached.
+// By default, clients have no synthesis cache. If cache is nil, the cache is disabled.
+func (a *GoT2SClient) SetSynthesisCache(cache SynthesisCache) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.synthesisCache = cache
+}
+
+// SynthesisCache returns the synthesis cache of the client, or nil if synthesized audio is not cached.
+func (a *GoT2SClient) SynthesisCache() SynthesisCache {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.synthesisCache
+}
+
+// SetLogger sets the logger that receives the diagnostics of the client and the providers (see Logger), e.g. a
+// *slog.Logger. By default, nothing is logged. If logger is nil, logging is disabled.
+// The text that is synthesized is redacted in the logs, unless SetLogUserText is enabled.
+// A logger in the context of a request (see ContextWithLogger) takes precedence over the logger of the client.
+func (a *GoT2SClient) SetLogger(logger Logger) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if logger == nil {
+		logger = NopLogger{}
+	}
+	a.logger = logger
+}
+
+// Logger returns the logger of the client (see SetLogger).
+func (a *GoT2SClient) Logger() Logger {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.logger
+}
+
+// SetLogUserText sets whether the text that is synthesized is included in the logs. By default, only the length
+// of the text is logged (see RedactText).
+func (a *GoT2SClient) SetLogUserText(logUserText bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.logUserText = logUserText
+}
+
+// redactText returns the given text for logging (see SetLogUserText).
+func (a *GoT2SClient) redactText(text string) string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return RedactText(text, a.logUserText)
+}
+
+// SetTracerProvider sets the OpenTelemetry tracer provider that receives the spans of the client, e.g. a provider of
+// the OpenTelemetry SDK with an exporter. Each synthesis creates a span with child spans for the stages provider
+// selection (SelectProvider), voice discovery (FindVoice), option transformation (TransformOptions), synthesis
+// (Synthesize or SynthesizeChunks), upload (Upload) and temporary file handling (WriteTempFile and RemoveTempFile).
+// By default, no spans are created. If provider is nil, tracing is disabled.
+func (a *GoT2SClient) SetTracerProvider(provider trace.TracerProvider) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+	a.tracerProvider = provider
+	var err error
+	a.telemetry, err = newTelemetry(a.tracerProvider, a.meterProvider)
+	return err
+}
+
+// SetMeterProvider sets the OpenTelemetry meter provider that receives the metrics of the client (see MetricCharacters,
+// MetricAudioBytes, MetricSynthesisDuration, MetricUploadDuration, MetricErrors, MetricBilledCharacters and
+// MetricEstimatedCost), e.g. a provider of the OpenTelemetry SDK with a reader. By default, no metrics are recorded.
+// If provider is nil, metrics are disabled. If the metric instruments can't be created, an error is returned and no
+// metrics are recorded.
+func (a *GoT2SClient) SetMeterProvider(provider metric.MeterProvider) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if provider == nil {
+		provider = noop.NewMeterProvider()
+	}
+	a.meterProvider = provider
+	var err error
+	a.telemetry, err = newTelemetry(a.tracerProvider, a.meterProvider)
+	return err
+}
+
+// getTelemetry returns the tracer and metric instruments of the client.
+func (a *GoT2SClient) getTelemetry() *telemetry {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.telemetry
+}
+
+// contextWithLogger returns a context that carries the logger of the client, so that the providers use it too.
+// If the given context already carries a logger, it is returned unchanged.
+func (a *GoT2SClient) contextWithLogger(ctx context.Context) context.Context {
+	if _, ok := LoggerFromContext(ctx).(NopLogger); !ok {
+		return ctx
+	}
+	return ContextWithLogger(ctx, a.Logger())
+}
+
+// getCachedAudio returns the audio of the given synthesis from the synthesis cache of the client and its key.
+// Returns false if the client has no cache or the cache doesn't contain the audio.
+// Errors of the cache are not fatal, because the audio can still be synthesized.
+func (a *GoT2SClient) getCachedAudio(ctx context.Context, text string, options TextToSpeechOptions) ([]byte, string, bool) {
+	cache := a.SynthesisCache()
+	if cache == nil {
+		return nil, "", false
+	}
+	key := SynthesisCacheKey(text, options)
+	data, found, err := cache.Get(ctx, key)
+	if err != nil {
+		LoggerFromContext(ctx).Warn("Error while reading synthesis cache", LogKeyError, err)
+		return nil, key, false
+	}
+	if found {
+		LoggerFromContext(ctx).Debug("Using cached audio", "key", key, LogKeyBytes, len(data))
+	}
+	return data, key, found
+}
+
+// putCachedAudio stores the given audio in the synthesis cache of the client (if it has one).
+func (a *GoT2SClient) putCachedAudio(ctx context.Context, key string, data []byte) {
+	cache := a.SynthesisCache()
+	if cache == nil {
+		return
+	}
+	if err := cache.Put(ctx, key, data); err != nil {
+		LoggerFromContext(ctx).Warn("Error while writing synthesis cache", LogKeyError, err)
+	}
+}
+
+// findVoice finds a voice for the given options on the given provider. If the client has a voice catalog and the
+// provider implements VoiceListProvider, the voice is chosen from the cached voices of the provider.
+func (a *GoT2SClient) findVoice(ctx context.Context, provider providers.Provider, instance T2SProvider, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	ctx, span := a.getTelemetry().tracer.Start(ctx, "FindVoice",
+		trace.WithAttributes(attribute.String(LogKeyProvider, string(provider))))
+	defer span.End()
+	var voiceIdConfig *VoiceIdConfig
+	var err error
+	listProvider, canList := instance.(VoiceListProvider)
+	if catalog := a.VoiceCatalog(); (catalog == nil) || !canList {
+		voiceIdConfig, err = instance.FindVoice(ctx, options)
+	} else {
+		var voices []Voice
+		voices, err = catalog.Voices(ctx, provider, listProvider)
+		if err == nil {
+			voiceIdConfig, err = listProvider.SelectVoice(voices, options)
+		}
+	}
+	if err != nil {
+		endSpanWithError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String(LogKeyVoice, voiceIdConfig.VoiceId), attribute.String(AttributeEngine, voiceIdConfig.Engine))
+	LoggerFromContext(ctx).Debug("Found voice", LogKeyProvider, provider, LogKeyVoice, voiceIdConfig.VoiceId,
+		"engine", voiceIdConfig.Engine, "language", options.VoiceConfig.VoiceParamsConfig.LanguageCode,
+		"gender", options.VoiceConfig.VoiceParamsConfig.Gender.String())
+	return voiceIdConfig, nil
+}
+
+// listVoices lists the voices of the given provider that match the given filter.
+// If the client has a voice catalog, the cached voices of the provider are used.
+// Returns ErrVoiceListNotSupported if the provider doesn't implement VoiceListProvider.
+func (a *GoT2SClient) listVoices(ctx context.Context, provider providers.Provider, instance T2SProvider, filter VoiceFilter) ([]Voice, error) {
+	listProvider, ok := instance.(VoiceListProvider)
+	if !ok {
+		return nil, errors.Join(ErrVoiceListNotSupported, errors.New(fmt.Sprintf("provider %s can't list its voices", provider)))
+	}
+	catalog := a.VoiceCatalog()
+	if catalog == nil {
+		return listProvider.ListVoices(ctx, filter)
+	}
+	voices, err := catalog.Voices(ctx, provider, listProvider)
+	if err != nil {
+		return nil, err
+	}
+	return FilterVoices(voices, filter), nil
+}
+
+// ListVoices lists the voices of all registered providers that match the given filter.
+// If filter.Provider is specified, only the voices of this provider are listed. Otherwise, providers that can't list
+// their voices (see VoiceListProvider) are skipped.
+// The voices are ordered by provider (in the order the providers were registered).
+// If listing the voices fails for some providers, the voices of the other providers are returned together with an
+// error that contains the errors of the failed providers.
+func (a *GoT2SClient) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
+	ctx = a.contextWithLogger(ctx)
+	providersToQuery := GetRegisteredProviders()
+	if filter.Provider != providers.ProviderUnspecified {
+		providersToQuery = []providers.Provider{filter.Provider}
+	}
+
+	var wg sync.WaitGroup
+	voicesPerProvider := make([][]Voice, len(providersToQuery))
+	errorsPerProvider := make([]error, len(providersToQuery))
+	for i, provider := range providersToQuery {
+		wg.Add(1)
+		go func(i int, prov providers.Provider) {
+			defer wg.Done()
+			provInstance, err := a.getProviderInstance(prov)
+			if err == nil {
+				voicesPerProvider[i], err = a.listVoices(ctx, prov, provInstance, filter)
+			}
+			if errors.Is(err, ErrVoiceListNotSupported) && (filter.Provider == providers.ProviderUnspecified) {
+				return
+			}
+			if err != nil {
+				errorsPerProvider[i] = errors.Join(errors.New(fmt.Sprintf("error while listing voices of provider %s", prov)), err)
+			}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	var voices []Voice
+	for _, providerVoices := range voicesPerProvider {
+		voices = append(voices, providerVoices...)
+	}
+	return voices, errors.Join(errorsPerProvider...)
+}
+
+// RankVoices ranks the voices of all registered providers (or only of options.Provider, if specified) for the voice
+// parameters and preferences of the given options (see shared.RankVoices). The best voice is returned first, each
+// with the reasons for its ranking. Like ListVoices, returns the ranked voices of all providers that could be queried
+// together with an error that contains the errors of the failed providers.
+func (a *GoT2SClient) RankVoices(ctx context.Context, options TextToSpeechOptions) ([]RankedVoice, error) {
+	voices, err := a.ListVoices(ctx, VoiceFilterOf(options))
+	return RankVoices(voices, options.VoiceConfig.VoiceParamsConfig, options.VoiceConfig.VoicePreferences), err
+}
+
 // T2SDirect Transforms the given text into speech and stores the file in destination.
 // If the given options specify a provider, this provider will be used.
 // If the given options don't specify a provider, a provider will be chosen based on heuristics.
-func (a GoT2SClient) T2SDirect(text string, destination string, options TextToSpeechOptions) (GoT2SClient, error) {
+func (a *GoT2SClient) T2SDirect(text string, destination string, options TextToSpeechOptions) error {
+	return a.T2SDirectWithContext(context.Background(), text, destination, options)
+}
 
-	// error check: If the given text is supposed to be a SSML text and does not contain <speak>-tags, it is invalid.
-	if (options.TextType == TextTypeSsml) && !HasSpeakTag(text) {
-		return a, errors.New("invalid text. The text type was SSML, but the given text didn't contain <speak>-tags")
+// T2SDirectSSML is the same as T2SDirect, but takes an SSML document (e.g. created with ssml.New) instead of text.
+// The text type of the given options is set to TextTypeSsml.
+func (a *GoT2SClient) T2SDirectSSML(document *ssml.Document, destination string, options TextToSpeechOptions) error {
+	options.TextType = TextTypeSsml
+	return a.T2SDirect(document.String(), destination, options)
+}
+
+// T2SDirectWithContext is the same as T2SDirect, but the given context is passed to all requests that are sent
+// to the providers (i.e. voice discovery, speech synthesis and upload). If the context is canceled or its deadline
+// is exceeded, the synthesis is aborted and the context's error is returned.
+func (a *GoT2SClient) T2SDirectWithContext(ctx context.Context, text string, destination string, options TextToSpeechOptions) error {
+	_, err := a.T2SDirectWithResult(ctx, text, destination, options)
+	return err
+}
+
+// T2SDirectWithResult is the same as T2SDirectWithContext, but additionally returns information about the synthesis,
+// like the chosen provider and voice, the final destination and the speech marks (see TextToSpeechOptions.SpeechMarkTypes).
+func (a *GoT2SClient) T2SDirectWithResult(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SResult, error) {
+	ctx = a.contextWithLogger(ctx)
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "T2SDirect")
+	defer span.End()
+	result, err := a.t2sDirectWithResult(ctx, text, destination, options)
+	if err != nil {
+		tel.recordError(ctx, span, err, options)
+		return nil, err
 	}
+	return result, nil
+}
 
-	// if text type is auto, text type needs to be inferred
-	if options.TextType == TextTypeAuto {
-		// SSML text needs to be wrapped in a "speak" root node (i.e. <speak>...</speak>)
-		if HasSpeakTag(text) {
-			options.TextType = TextTypeSsml
-		} else {
-			options.TextType = TextTypeText
-		}
+// t2sDirectWithResult implements T2SDirectWithResult. The context must carry the logger of the client.
+func (a *GoT2SClient) t2sDirectWithResult(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SResult, error) {
+	logger := LoggerFromContext(ctx)
+	start := time.Now()
+	options, err := inferTextType(text, options)
+	if err != nil {
+		return nil, err
 	}
 
+	// the options before the provider and voice are chosen, used to choose another provider if failover is enabled
+	failoverOptions := options
+	ctx, usage := contextWithBillingUsage(ctx)
+
 	if options.Provider == providers.ProviderUnspecified {
 		if !options.VoiceConfig.VoiceIdConfig.IsEmpty() {
-			fmt.Printf("Cloud provider was unspecified, but voiceId was specified. In most cases, the voiceId is " +
-				"only available on a single provider. This means that the provider that will be chosen automatically " +
-				"might not support the specified voiceId. For best results, either specify the cloud provider " +
-				"alongslide the voiceId, or remove voiceId and specify voice parameters (gender & language).\n")
+			logger.Warn("Cloud provider was unspecified, but voiceId was specified. In most cases, the voiceId is "+
+				"only available on a single provider. This means that the provider that will be chosen automatically "+
+				"might not support the specified voiceId. For best results, either specify the cloud provider "+
+				"alongside the voiceId, or remove voiceId and specify voice parameters (gender & language).",
+				LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId)
 		}
 
-		var err error
-		options, err = a.determineProvider(options, destination)
+		options, err = a.determineProvider(ctx, options, destination)
 		if err != nil {
-			return a, err
+			return nil, err
+		}
+	}
+
+	provider, audioData, speechMarks, options, failedProviders, t2sErr := a.synthesizeWithFailover(ctx, text, destination, options, failoverOptions)
+	if t2sErr != nil {
+		return nil, t2sErr
+	}
+	if closer, isCloser := audioData.(io.Closer); isCloser {
+		// releases the connection and the rate limit of the request, even if the audio isn't stored
+		defer closer.Close()
+	}
+	trace.SpanFromContext(ctx).SetAttributes(voiceAttributes(options)...)
+	billing := a.billingRecord(usage, provider, options)
+	a.recordBillingMetrics(ctx, billing)
+
+	var fileExtErr error = nil
+	destination, fileExtErr = provider.AddFileExtensionToDestinationIfNeeded(options, options.OutputFormatRaw, destination)
+	if fileExtErr != nil { // not a fatal error
+		logger.Warn("Error while adding file extension to destination", LogKeyError, fileExtErr)
+	}
+
+	countedAudio := &countingReader{reader: audioData}
+	t2sErr = a.storeFile(ctx, provider, countedAudio, destination)
+	if t2sErr != nil {
+		return nil, t2sErr
+	}
+	a.getTelemetry().audioBytes.Add(ctx, countedAudio.count, metric.WithAttributes(voiceAttributes(options)...))
+	logger.Info("Stored synthesized speech", LogKeyProvider, options.Provider,
+		LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId, LogKeyDestination, destination,
+		LogKeyBytes, countedAudio.count, LogKeyDuration, time.Since(start))
+
+	subtitleDestination := ""
+	if options.Subtitles.Format != SubtitleFormatNone {
+		subtitleData, subtitleErr := subtitles.Generate(speechMarks, options.Subtitles)
+		if subtitleErr != nil {
+			return nil, errors.Join(errors.New("error while generating subtitles"), ErrInvalidOptions, subtitleErr)
+		}
+		subtitleDestination = subtitles.DestinationFor(destination, options.Subtitles.Format)
+		t2sErr = a.storeFile(ctx, provider, bytes.NewReader(subtitleData), subtitleDestination)
+		if t2sErr != nil {
+			return nil, t2sErr
+		}
+	}
+
+	// move file to actual destination, if needed
+	/*
+		if !strings.EqualFold(providerDestination, destination) {
+
+			tempStorageObj := ParseUrlToGoStorageObject(providerDestination)
+			if a.IsProviderStorageUrl(destination) {
+				actualStorageObj := ParseUrlToGoStorageObject(destination)
+				a.gostorageClient.Copy(tempStorageObj, actualStorageObj)
+			} else { // local file
+				a.gostorageClient.DownloadFile(tempStorageObj, destination)
+			}
+
+			if a.DeleteTempFile {
+				a.gostorageClient.DeleteFile(tempStorageObj)
+			}
+		}
+	*/
+
+	return &T2SResult{
+		Provider:            options.Provider,
+		VoiceIdConfig:       options.VoiceConfig.VoiceIdConfig,
+		Destination:         destination,
+		SpeechMarks:         speechMarks,
+		SubtitleDestination: subtitleDestination,
+		FailedProviders:     failedProviders,
+		Billing:             billing,
+	}, nil
+}
+
+// synthesizeWithFailover synthesizes the given text on the provider of the given options. If the synthesis fails
+// with a retryable error (see RetryableErrorClassifier) or the provider is not available and
+// TextToSpeechOptions.Failover is enabled, the voice is chosen again on the remaining registered providers based on the
+// VoiceParamsConfig of failoverOptions (i.e. the options before the provider and voice were chosen), until one of them
+// succeeds. Returns the provider that synthesized the audio, the audio data, the speech marks, the final options and
+// the providers that failed before.
+func (a *GoT2SClient) synthesizeWithFailover(ctx context.Context, text string, destination string, options TextToSpeechOptions, failoverOptions TextToSpeechOptions) (T2SProvider, io.Reader, []SpeechMark, TextToSpeechOptions, []providers.Provider, error) {
+	provider, err := a.getProviderInstance(options.Provider)
+	if err == nil {
+		var audioData io.Reader
+		var speechMarks []SpeechMark
+		audioData, speechMarks, options, err = a.synthesize(ctx, provider, text, destination, options)
+		if err == nil {
+			return provider, audioData, speechMarks, options, nil, nil
+		}
+		if !RetryableErrors(provider)(err) {
+			return nil, nil, nil, options, nil, err
+		}
+	}
+	// a voice ID is specific to a provider, so another provider can only be chosen based on voice parameters
+	if !options.Failover || !failoverOptions.VoiceConfig.VoiceIdConfig.IsEmpty() {
+		return nil, nil, nil, options, nil, err
+	}
+
+	failedProviders := []providers.Provider{options.Provider}
+	failoverErrors := []error{errors.Join(errors.New(fmt.Sprintf("error while synthesizing speech on provider %s", options.Provider)), err)}
+	for _, candidate := range GetRegisteredProviders() {
+		if (candidate == options.Provider) || (ctx.Err() != nil) {
+			continue
+		}
+		LoggerFromContext(ctx).Warn("Synthesis failed, failing over to another provider", "failedProvider",
+			failedProviders[len(failedProviders)-1], LogKeyProvider, candidate, LogKeyError, failoverErrors[len(failoverErrors)-1])
+		candidateOptions := failoverOptions
+		candidateOptions.Provider = candidate
+
+		candidateProvider, candidateErr := a.getProviderInstance(candidate)
+		if candidateErr == nil {
+			var audioData io.Reader
+			var speechMarks []SpeechMark
+			audioData, speechMarks, candidateOptions, candidateErr = a.synthesize(ctx, candidateProvider, text, destination, candidateOptions)
+			if candidateErr == nil {
+				return candidateProvider, audioData, speechMarks, candidateOptions, failedProviders, nil
+			}
+		}
+		failedProviders = append(failedProviders, candidate)
+		failoverErrors = append(failoverErrors, errors.Join(errors.New(fmt.Sprintf("error while synthesizing speech on provider %s", candidate)), candidateErr))
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		failoverErrors = append(failoverErrors, ctxErr)
+	}
+	return nil, nil, nil, options, failedProviders, errors.Join(append([]error{errors.New("synthesis failed on all providers")}, failoverErrors...)...)
+}
+
+// synthesize chooses a voice on the given provider (if the options don't specify one), adjusts the text and options
+// for the provider and synthesizes the text. Returns the audio data, the speech marks and the adjusted options.
+func (a *GoT2SClient) synthesize(ctx context.Context, provider T2SProvider, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, TextToSpeechOptions, error) {
+	start := time.Now()
+	text, options, err := a.prepareSynthesis(ctx, provider, text, options)
+	if err != nil {
+		return nil, nil, options, err
+	}
+
+	// subtitles are generated from word and sentence speech marks
+	if (options.Subtitles.Format != SubtitleFormatNone) &&
+		!IncludesSpeechMarkType(options.SpeechMarkTypes, SpeechMarkTypeWord) &&
+		!IncludesSpeechMarkType(options.SpeechMarkTypes, SpeechMarkTypeSentence) {
+		options.SpeechMarkTypes = append(append([]SpeechMarkType{}, options.SpeechMarkTypes...),
+			SpeechMarkTypeWord, SpeechMarkTypeSentence)
+	}
+
+	// adjust provider-specific settings and execute T2S on selected provider
+	var audioData io.Reader
+	var speechMarks []SpeechMark
+	var t2sErr error
+	if options.SplitLongText {
+		audioData, speechMarks, t2sErr = a.executeT2SInChunks(ctx, provider, text, destination, options)
+	} else {
+		audioData, speechMarks, t2sErr = a.executeT2S(ctx, provider, text, destination, options)
+	}
+	if t2sErr != nil {
+		return nil, nil, options, t2sErr
+	}
+
+	if audio.IsUncompressedAudioFormat(options.OutputFormat) {
+		audioData, t2sErr = applyAudioContainer(audioData, options)
+		if t2sErr != nil {
+			return nil, nil, options, t2sErr
 		}
 	}
 
-	provider := a.getProviderInstance(options.Provider)
+	LoggerFromContext(ctx).Info("Synthesized speech", LogKeyProvider, options.Provider,
+		LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId, "speechMarks", len(speechMarks),
+		LogKeyDuration, time.Since(start))
+	return audioData, speechMarks, options, nil
+}
 
+// prepareSynthesis chooses a voice on the given provider (if the options don't specify one) and adjusts the text and
+// options for the provider. Returns the adjusted text and options.
+func (a *GoT2SClient) prepareSynthesis(ctx context.Context, provider T2SProvider, text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error) {
 	if options.VoiceConfig.VoiceIdConfig.IsEmpty() {
 		// if both VoiceParamsConfig is undefined -> use default object
 		if options.VoiceConfig.VoiceParamsConfig == (VoiceParamsConfig{}) {
@@ -134,56 +720,107 @@ func (a GoT2SClient) T2SDirect(text string, destination string, options TextToSp
 			}
 		}
 
-		fmt.Printf("Trying to find voice\n")
-		voiceIdConfig, chooseVoiceErr := provider.FindVoice(options)
+		LoggerFromContext(ctx).Debug("Trying to find voice", LogKeyProvider, options.Provider)
+		voiceIdConfig, chooseVoiceErr := a.findVoice(ctx, options.Provider, provider, options)
 		if chooseVoiceErr != nil {
-			return a, chooseVoiceErr
+			return text, options, chooseVoiceErr
 		}
 		options.VoiceConfig.VoiceIdConfig = *voiceIdConfig
 	}
+	return a.transformForProvider(ctx, provider, text, options)
+}
+
+// transformForProvider checks the SSML of the given text against the SSML profile of the given provider and adjusts the
+// text and options for the provider (see T2SProvider.TransformOptions). No requests are sent.
+func (a *GoT2SClient) transformForProvider(ctx context.Context, provider T2SProvider, text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error) {
+	_, span := a.getTelemetry().tracer.Start(ctx, "TransformOptions", trace.WithAttributes(voiceAttributes(options)...))
+	defer span.End()
+
+	// check if the SSML text is supported by the chosen provider before sending any requests
+	if options.TextType == TextTypeSsml {
+		var ssmlErr error
+		text, ssmlErr = ApplySSMLProfile(text, SSMLProfileOf(provider), options.SSMLValidation)
+		if ssmlErr != nil {
+			endSpanWithError(span, ssmlErr)
+			return text, options, ssmlErr
+		}
+	}
 
 	// adjust parameters for the chosen provider
 	var transformOptionsError error
 	text, options, transformOptionsError = provider.TransformOptions(text, options)
 
 	if transformOptionsError != nil {
-		return a, transformOptionsError
+		endSpanWithError(span, transformOptionsError)
+		return text, options, transformOptionsError
 	}
 
-	fmt.Println("Final Text: " + text)
+	LoggerFromContext(ctx).Debug("Final text", LogKeyProvider, options.Provider, LogKeyText, a.redactText(text))
+	return text, options, nil
+}
 
-	// adjust provider-specific settings and execute T2S on selected provider
-	audioData, t2sErr := provider.ExecuteT2SDirect(text, destination, options)
-	if t2sErr != nil {
-		return a, t2sErr
+// inferTextType checks the text type of the given options and infers it from the given text if it is TextTypeAuto.
+func inferTextType(text string, options TextToSpeechOptions) (TextToSpeechOptions, error) {
+	// error check: If the given text is supposed to be a SSML text and does not contain <speak>-tags, it is invalid.
+	if (options.TextType == TextTypeSsml) && !HasSpeakTag(text) {
+		return options, errors.Join(ErrInvalidSSML, errors.New("invalid text. The text type was SSML, but the given text didn't contain <speak>-tags"))
 	}
 
-	var fileExtErr error = nil
-	destination, fileExtErr = provider.AddFileExtensionToDestinationIfNeeded(options, options.OutputFormatRaw, destination)
-	if fileExtErr != nil { // not a fatal error
-		fmt.Printf(fileExtErr.Error())
+	// if text type is auto, text type needs to be inferred
+	if options.TextType == TextTypeAuto {
+		// SSML text needs to be wrapped in a "speak" root node (i.e. <speak>...</speak>)
+		if HasSpeakTag(text) {
+			options.TextType = TextTypeSsml
+		} else {
+			options.TextType = TextTypeText
+		}
+	}
+	return options, nil
+}
+
+// storeFile stores the given data at the given destination. The destination can be one of the following:
+// * Storage of the given provider (uploaded directly by the provider)
+// * Storage of another supported provider (uploaded via GoStorage)
+// * Local file
+func (a *GoT2SClient) storeFile(ctx context.Context, provider T2SProvider, data io.Reader, destination string) (err error) {
+	storage := "local"
+	if provider.IsURLonOwnStorage(destination) {
+		storage = "provider"
+	} else if a.IsProviderStorageUrl(destination) {
+		storage = "gostorage"
 	}
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "Upload", trace.WithAttributes(
+		attribute.String(LogKeyDestination, destination), attribute.String(AttributeStorage, storage)))
+	start := time.Now()
+	defer func() {
+		attributes := []attribute.KeyValue{attribute.String(AttributeStorage, storage)}
+		if err != nil {
+			attributes = append(attributes, attribute.String(AttributeErrorType, ErrorType(err)))
+		}
+		tel.uploadDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attributes...))
+		endSpanWithError(span, err)
+		span.End()
+	}()
 
 	if provider.IsURLonOwnStorage(destination) { // own storage -> upload directly
-		err := provider.UploadFile(audioData, destination)
+		err := RetryWithReader(ctx, a.RetryPolicy(), RetryableErrors(provider), data, func(data io.Reader) error {
+			return provider.UploadFile(ctx, data, destination)
+		})
 		if err != nil {
-			return a, errors.Join(errors.New(fmt.Sprintf("error while uploading audio file to %s", destination)), err)
+			return errors.Join(ErrUploadFailed, errors.New(fmt.Sprintf("error while uploading file to %s", destination)), err)
 		}
 	} else if a.IsProviderStorageUrl(destination) { // other cloud storage -> upload via GoStorage
-		tmpFile, err := os.CreateTemp("", "sample")
+		target, err := ParseUrlToGoStorageObject(destination)
 		if err != nil {
-			return a, errors.Join(errors.New("error while creating file for temporarily storing audio file before upload"), err)
+			return errors.Join(ErrUploadFailed, err)
 		}
-
-		err = StoreAudioToLocalFile(audioData, tmpFile)
+		tmpFile, err := a.writeTempFile(ctx, data)
 		if err != nil {
-			if a.IsProviderStorageUrl(destination) {
-				return a, errors.Join(errors.New("error while writing audio to temporary file"), err)
-			}
-			return a, errors.Join(errors.New("error while writing audio to local file"), err)
+			return err
 		}
 
-		target := ParseUrlToGoStorageObject(destination)
+		a.initializeGoStorage()
 		a.gostorageClient.UploadFile(gostorage.GoStorageObject{
 			Bucket:        target.Bucket,
 			Key:           target.Key,
@@ -193,52 +830,283 @@ func (a GoT2SClient) T2SDirect(text string, destination string, options TextToSp
 			ProviderType:  target.ProviderType,
 		})
 
-		closeErr := tmpFile.Close()
-		if closeErr != nil {
-			return a, errors.Join(errors.New("error while closing tmp file"), closeErr)
+		if err = a.removeTempFile(ctx, tmpFile); err != nil {
+			return err
 		}
+	} else { // local file -> store locally
+		return storeLocalFile(data, destination)
+	}
+	return nil
+}
 
-		if a.DeleteTempFile {
-			removeErr := os.Remove(tmpFile.Name())
-			if removeErr != nil {
-				return a, errors.Join(errors.New("error while removing temporarily stored audio file"), removeErr)
-			}
+// storeLocalFile writes the given data to a new file at the given local path. The file is closed on every path and
+// removed if writing or closing it fails, so that no partial audio file is left behind.
+func storeLocalFile(data io.Reader, destination string) (err error) {
+	file, err := os.Create(destination)
+	if err != nil {
+		return errors.Join(ErrUploadFailed, errors.New(fmt.Sprintf("error while creating file at destination %s", destination)), err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			err = errors.Join(err, ErrUploadFailed, errors.New("error while closing local file"), closeErr)
 		}
-	} else { // local file -> store locally
-		file, err := os.Open(destination)
 		if err != nil {
-			return a, errors.Join(errors.New(fmt.Sprintf("error while opening file at destination %s", destination)), err)
+			_ = os.Remove(destination)
 		}
+	}()
 
-		err = StoreAudioToLocalFile(audioData, file)
-		if err != nil {
-			return a, errors.Join(errors.New("error while writing audio to local file"), err)
-		}
-		closeErr := file.Close()
-		if closeErr != nil {
-			return a, errors.Join(errors.New("error while closing local file"), closeErr)
+	if err = StoreAudioToLocalFile(data, file); err != nil {
+		return errors.Join(ErrUploadFailed, errors.New("error while writing to local file"), err)
+	}
+	return nil
+}
+
+// writeTempFile writes the given data to a new temporary file, which is used to upload the data via GoStorage.
+func (a *GoT2SClient) writeTempFile(ctx context.Context, data io.Reader) (*os.File, error) {
+	_, span := a.getTelemetry().tracer.Start(ctx, "WriteTempFile")
+	defer span.End()
+	tmpFile, err := os.CreateTemp("", "sample")
+	if err != nil {
+		err = errors.Join(ErrUploadFailed, errors.New("error while creating file for temporarily storing file before upload"), err)
+		endSpanWithError(span, err)
+		return nil, err
+	}
+
+	err = StoreAudioToLocalFile(data, tmpFile)
+	if err != nil {
+		err = errors.Join(ErrUploadFailed, errors.New("error while writing to temporary file"), err)
+		endSpanWithError(span, err)
+		return nil, err
+	}
+	return tmpFile, nil
+}
+
+// removeTempFile closes the given temporary file and removes it, if DeleteTempFile is true.
+func (a *GoT2SClient) removeTempFile(ctx context.Context, tmpFile *os.File) error {
+	_, span := a.getTelemetry().tracer.Start(ctx, "RemoveTempFile")
+	defer span.End()
+	closeErr := tmpFile.Close()
+	if closeErr != nil {
+		err := errors.Join(ErrUploadFailed, errors.New("error while closing tmp file"), closeErr)
+		endSpanWithError(span, err)
+		return err
+	}
+
+	if a.DeleteTempFile {
+		removeErr := os.Remove(tmpFile.Name())
+		if removeErr != nil {
+			err := errors.Join(ErrUploadFailed, errors.New("error while removing temporarily stored file"), removeErr)
+			endSpanWithError(span, err)
+			return err
 		}
 	}
+	return nil
+}
+
+// executeT2SInChunks splits the given text into chunks that fit into the text length limit of the given provider
+// and synthesizes up to TextToSpeechOptions.MaxChunkConcurrency chunks concurrently. The audio data of all chunks is returned as one continuous audio stream.
+// If the synthesis of one chunk fails (after retries), the synthesis of all other chunks is canceled and the error is returned.
+// Speech marks are only supported if the text fits into a single chunk.
+func (a *GoT2SClient) executeT2SInChunks(ctx context.Context, provider T2SProvider, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error) {
+	limit := TextLengthLimitOf(provider).WithMaxLength(options.MaxChunkLength)
+	chunks, err := SplitTextIntoChunks(text, options.TextType, limit)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error while splitting text into chunks"), err)
+	}
+	if len(chunks) == 1 {
+		return a.executeT2S(ctx, provider, chunks[0], destination, options)
+	}
+	if len(options.SpeechMarkTypes) > 0 {
+		return nil, nil, errors.Join(ErrInvalidOptions, errors.New(fmt.Sprintf("speech marks are not supported for texts that need to be split into "+
+			"multiple chunks, but the text was split into %d chunks", len(chunks))))
+	}
 
-	// move file to actual destination, if needed
-	/*
-		if !strings.EqualFold(providerDestination, destination) {
+	LoggerFromContext(ctx).Info("Synthesizing text in chunks", LogKeyProvider, options.Provider, "chunks", len(chunks))
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "SynthesizeChunks", trace.WithAttributes(append(voiceAttributes(options),
+		attribute.Int(AttributeCharacters, utf8.RuneCountInString(text)), attribute.Int(AttributeChunks, len(chunks)))...))
+	defer span.End()
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-			tempStorageObj := ParseUrlToGoStorageObject(providerDestination)
-			if a.IsProviderStorageUrl(destination) {
-				actualStorageObj := ParseUrlToGoStorageObject(destination)
-				a.gostorageClient.Copy(tempStorageObj, actualStorageObj)
-			} else { // local file
-				a.gostorageClient.DownloadFile(tempStorageObj, destination)
+	concurrency := options.MaxChunkConcurrency
+	if concurrency < 1 {
+		concurrency = DefaultMaxChunkConcurrency
+	}
+	if concurrency > len(chunks) {
+		concurrency = len(chunks)
+	}
+
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	var firstErr error = nil
+	audioChunks := make([][]byte, len(chunks))
+	synthesizeChunk := func(index int, chunkText string) {
+		audioBytes, cacheKey, cached := a.getCachedAudio(chunkCtx, chunkText, options)
+		if cached {
+			mut.Lock()
+			audioChunks[index] = audioBytes
+			mut.Unlock()
+			return
+		}
+		start := time.Now()
+		chunkErr := Retry(chunkCtx, a.RetryPolicy(), RetryableErrors(provider), func() error {
+			release, err := a.rateLimiters.Acquire(chunkCtx, options.Provider)
+			if err != nil {
+				return err
+			}
+			defer release()
+			audioData, err := provider.ExecuteT2SDirect(chunkCtx, chunkText, destination, options)
+			if err != nil {
+				return err
+			}
+			audioBytes, err = io.ReadAll(audioData)
+			if closer, isCloser := audioData.(io.Closer); isCloser {
+				_ = closer.Close()
+			}
+			return err
+		})
+		mut.Lock()
+		if chunkErr != nil {
+			if firstErr == nil {
+				firstErr = errors.Join(errors.New(fmt.Sprintf("error while synthesizing chunk %d of %d", index+1, len(chunks))), chunkErr)
+				cancel()
 			}
+			mut.Unlock()
+			return
+		}
+		audioChunks[index] = audioBytes
+		mut.Unlock()
+		// only the shared state is locked, so that the chunks are recorded and cached concurrently
+		tel.recordSynthesis(chunkCtx, chunkText, time.Since(start), options)
+		recordBilling(chunkCtx, provider, chunkText, options)
+		a.putCachedAudio(chunkCtx, cacheKey, audioBytes)
+	}
 
-			if a.DeleteTempFile {
-				a.gostorageClient.DeleteFile(tempStorageObj)
+	// the chunks are synthesized by a limited number of workers, so that long texts don't cause a burst of requests
+	indices := make(chan int)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				if chunkCtx.Err() == nil {
+					synthesizeChunk(index, chunks[index])
+				}
 			}
+		}()
+	}
+	for index := range chunks {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+
+	if (firstErr == nil) && (ctx.Err() != nil) {
+		firstErr = errors.Join(errors.New("error while synthesizing chunks"), ctx.Err())
+	}
+	if firstErr != nil {
+		endSpanWithError(span, firstErr)
+		return nil, nil, firstErr
+	}
+
+	joinedAudio, concatErr := audio.Concat(options.OutputFormat, options.SampleRate, audioChunks...)
+	if concatErr != nil {
+		err = errors.Join(errors.New("error while joining the audio of the synthesized chunks"), ErrUnsupportedFormat, concatErr)
+		endSpanWithError(span, err)
+		return nil, nil, err
+	}
+	return bytes.NewReader(joinedAudio), nil, nil
+}
+
+// executeT2S synthesizes the given text on the given provider. If TextToSpeechOptions.SpeechMarkTypes is not empty,
+// the speech marks are requested as well. Failed requests are retried according to the retry policy of the client.
+// If the client has a synthesis cache, the audio is taken from the cache if possible and otherwise added to it.
+func (a *GoT2SClient) executeT2S(ctx context.Context, provider T2SProvider, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error) {
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "Synthesize", trace.WithAttributes(append(voiceAttributes(options),
+		attribute.Int(AttributeCharacters, utf8.RuneCountInString(text)))...))
+	defer span.End()
+
+	speechMarksProvider, supportsSpeechMarks := provider.(SpeechMarksProvider)
+	if (len(options.SpeechMarkTypes) > 0) && !supportsSpeechMarks {
+		err := errors.Join(ErrInvalidOptions, errors.New(fmt.Sprintf("provider %s doesn't support speech marks", options.Provider)))
+		endSpanWithError(span, err)
+		return nil, nil, err
+	}
+
+	// speech marks are not cached
+	useCache := false
+	cacheKey := ""
+	if len(options.SpeechMarkTypes) == 0 {
+		cachedAudio, key, cached := a.getCachedAudio(ctx, text, options)
+		span.SetAttributes(attribute.Bool(AttributeCached, cached))
+		if cached {
+			return bytes.NewReader(cachedAudio), nil, nil
 		}
-	*/
+		useCache, cacheKey = key != "", key
+	}
 
-	return a, t2sErr
+	var audioData io.Reader
+	var audioBytes []byte
+	var speechMarks []SpeechMark
+	start := time.Now()
+	err := Retry(ctx, a.RetryPolicy(), RetryableErrors(provider), func() error {
+		release, err := a.rateLimiters.AcquireN(ctx, options.Provider, requestsPerSynthesis(provider, options))
+		if err != nil {
+			return err
+		}
+		if len(options.SpeechMarkTypes) > 0 {
+			audioData, speechMarks, err = speechMarksProvider.ExecuteT2SWithSpeechMarks(ctx, text, destination, options)
+		} else {
+			audioData, err = provider.ExecuteT2SDirect(ctx, text, destination, options)
+		}
+		if err != nil {
+			release()
+			return err
+		}
+		if !useCache {
+			// the request is in flight until the audio has been read or closed
+			audioData = &releasingReader{reader: audioData, release: release}
+			return nil
+		}
+		defer release()
+		// the audio needs to be read completely to store it in the cache
+		audioBytes, err = io.ReadAll(audioData)
+		if closer, isCloser := audioData.(io.Closer); isCloser {
+			_ = closer.Close()
+		}
+		return err
+	})
+	if err != nil {
+		endSpanWithError(span, err)
+		return nil, nil, err
+	}
+	tel.recordSynthesis(ctx, text, time.Since(start), options)
+	recordBilling(ctx, provider, text, options)
+	if useCache {
+		a.putCachedAudio(ctx, cacheKey, audioBytes)
+		audioData = bytes.NewReader(audioBytes)
+	}
+	return audioData, speechMarks, nil
+}
+
+// applyAudioContainer makes sure that uncompressed audio data is stored in a WAV container.
+// If TextToSpeechOptions.RawAudioOutput is true, the WAV container is removed instead.
+func applyAudioContainer(audioData io.Reader, options TextToSpeechOptions) (io.Reader, error) {
+	data, err := io.ReadAll(audioData)
+	if err != nil {
+		return nil, errors.Join(errors.New("error while reading synthesized audio"), ErrSynthesisFailed, err)
+	}
+	if options.RawAudioOutput {
+		data, err = audio.RemoveContainer(data)
+	} else {
+		data, err = audio.AddContainer(options.OutputFormat, options.SampleRate, data)
+	}
+	if err != nil {
+		return nil, errors.Join(errors.New("error while processing WAV container of synthesized audio"), ErrUnsupportedFormat, err)
+	}
+	return bytes.NewReader(data), nil
 }
 
 // T2S Transforms the text in the source file into speech and stores the file in destination.
@@ -249,43 +1117,78 @@ func (a GoT2SClient) T2SDirect(text string, destination string, options TextToSp
 // * Local file
 // If the given options specify a provider, this provider will be used.
 // If the given options don't specify a provider, a provider will be chosen based on heuristics.
-func (a GoT2SClient) T2S(source string, destination string, options TextToSpeechOptions) (GoT2SClient, error) {
+func (a *GoT2SClient) T2S(source string, destination string, options TextToSpeechOptions) error {
+	return a.T2SWithContext(context.Background(), source, destination, options)
+}
+
+// T2SWithContext is the same as T2S, but the given context is passed to all requests that are sent to the providers
+// and to the download of source files via HTTP.
+func (a *GoT2SClient) T2SWithContext(ctx context.Context, source string, destination string, options TextToSpeechOptions) error {
+	_, err := a.T2SWithResult(ctx, source, destination, options)
+	return err
+}
+
+// T2SWithResult is the same as T2SWithContext, but additionally returns information about the synthesis
+// (see T2SDirectWithResult).
+func (a *GoT2SClient) T2SWithResult(ctx context.Context, source string, destination string, options TextToSpeechOptions) (*T2SResult, error) {
+	ctx = a.contextWithLogger(ctx)
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "T2S")
+	defer span.End()
+	result, err := a.t2sWithResult(ctx, source, destination, options)
+	if err != nil {
+		tel.recordError(ctx, span, err, options)
+		return nil, err
+	}
+	return result, nil
+}
+
+// t2sWithResult implements T2SWithResult. The context must carry the logger of the client.
+func (a *GoT2SClient) t2sWithResult(ctx context.Context, source string, destination string, options TextToSpeechOptions) (*T2SResult, error) {
+	logger := LoggerFromContext(ctx)
 
 	localFilePath := ""
 	text := ""
 	fileOnCloudProvider := false
 	if a.IsProviderStorageUrl(source) { // file on supported cloud provider
-		storageObj := ParseUrlToGoStorageObject(source)
-		a = a.initializeGoStorage()
+		storageObj, err := ParseUrlToGoStorageObject(source)
+		if err != nil {
+			return nil, err
+		}
+		a.initializeGoStorage()
 		fileReader := a.gostorageClient.DownloadFileAsReader(storageObj)
 		fileBuf := new(bytes.Buffer)
 		_, bufErr := fileBuf.ReadFrom(fileReader)
 		if bufErr != nil {
-			return a, errors.Join(errors.New("error occurred while reading input file from file reader"), bufErr)
+			return nil, errors.Join(ErrSourceUnavailable, errors.New("error occurred while reading input file from file reader"), bufErr)
 		}
 		text = fileBuf.String()
 		readerCloseErr := (fileReader.(io.ReadCloser)).Close()
 		if readerCloseErr != nil {
-			fmt.Printf("non fatal-error while closing input file reader: %s\n", readerCloseErr.Error())
+			logger.Warn("Non-fatal error while closing input file reader", "source", source, LogKeyError, readerCloseErr)
 		}
 		fileOnCloudProvider = true
 	} else if strings.HasPrefix(source, "http") { // file somewhere else online
-		response, err := http.Get(source)
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
 		if err != nil {
-			return a, errors.Join(errors.New(fmt.Sprintf("Couldn't download the source file '%s'.", source)), err)
+			return nil, errors.Join(ErrSourceUnavailable, errors.New(fmt.Sprintf("Couldn't create request for the source file '%s'.", source)), err)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return nil, errors.Join(ErrSourceUnavailable, errors.New(fmt.Sprintf("Couldn't download the source file '%s'.", source)), err)
 		}
 
 		// close body after function call ended
 		defer func(Body io.ReadCloser) {
 			err := Body.Close()
 			if err != nil {
-				fmt.Printf(errors.Join(errors.New(fmt.Sprintf("A non-fatal error occurred while closing the HTTP response for the source file '%s'.", source)), err).Error())
+				logger.Warn("Non-fatal error while closing the HTTP response for the source file", "source", source, LogKeyError, err)
 			}
 		}(response.Body)
 
 		textBytes, err2 := io.ReadAll(response.Body)
 		if err2 != nil {
-			return a, errors.Join(errors.New(fmt.Sprintf("Couldn't download the source file '%s'. An error occurred while reading body.", source)), err)
+			return nil, errors.Join(ErrSourceUnavailable, errors.New(fmt.Sprintf("Couldn't download the source file '%s'. An error occurred while reading body.", source)), err2)
 		}
 		text = string(textBytes)
 	} else { // local file
@@ -299,43 +1202,58 @@ func (a GoT2SClient) T2S(source string, destination string, options TextToSpeech
 			if fileOnCloudProvider {
 				helperText = "temporarily stored "
 			}
-			return a, errors.Join(errors.New(fmt.Sprintf("Couldn't read the %stext file on '%s'.", helperText, localFilePath)), err)
+			return nil, errors.Join(ErrSourceUnavailable, errors.New(fmt.Sprintf("Couldn't read the %stext file on '%s'.", helperText, localFilePath)), err)
 		}
 		text = string(dat)
 	}
 
-	fmt.Printf("Read the following text from file: %s\n", text)
-	return a.T2SDirect(text, destination, options)
+	logger.Debug("Read text from source file", "source", source, LogKeyBytes, len(text), LogKeyText, a.redactText(text))
+	return a.t2sDirectWithResult(ctx, text, destination, options)
 }
 
-func (a GoT2SClient) initializeGoStorage() GoT2SClient {
-	if a.gostorageClient == nil {
+func (a *GoT2SClient) initializeGoStorage() {
+	a.gostorageOnce.Do(func() {
 		a.gostorageClient = &gostorage.GoStorage{
 			Credentials: *a.credentials,
 		}
-	}
-	return a
+	})
 }
 
+// CreateProviderInstance creates a new instance of the given provider using the provider registry
+// (see RegisterProvider). The built-in providers AWS and GCP are always registered.
+// If the given provider is not registered, nil is returned.
 func CreateProviderInstance(provider providers.Provider) T2SProvider {
-	switch provider {
-	case providers.ProviderAWS:
-		return ts2_aws.T2SAmazonWebServices{}
-	case providers.ProviderGCP:
-		return ts2_gcp.T2SGoogleCloudPlatform{}
-	default:
+	instance, err := NewRegisteredProviderInstance(provider)
+	if err != nil {
 		return nil
 	}
+	return instance
 }
 
-func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination string) (TextToSpeechOptions, error) {
+// determineProvider chooses the provider and voice for the given options (see selectProvider), if the options don't
+// specify a provider.
+func (a *GoT2SClient) determineProvider(ctx context.Context, options TextToSpeechOptions, destination string) (TextToSpeechOptions, error) {
+	ctx, span := a.getTelemetry().tracer.Start(ctx, "SelectProvider")
+	defer span.End()
+	selected, err := a.selectProvider(ctx, options, destination)
+	if err != nil {
+		endSpanWithError(span, err)
+		return selected, err
+	}
+	span.SetAttributes(voiceAttributes(selected)...)
+	return selected, nil
+}
+
+// selectProvider chooses the provider and voice of determineProvider.
+func (a *GoT2SClient) selectProvider(ctx context.Context, options TextToSpeechOptions, destination string) (TextToSpeechOptions, error) {
 
 	// First heuristic: Choose provider that offers voice parameters (gender, language)
 	var wg sync.WaitGroup
 	var mut sync.Mutex
 
 	voicePerProvider := make(map[providers.Provider]*VoiceIdConfig)
-	for _, provider := range providers.GetAllProviders() {
+	var voiceErrors []error
+	for _, provider := range GetRegisteredProviders() {
 
 		if !options.VoiceConfig.VoiceIdConfig.IsEmpty() {
 			voicePerProvider[provider] = &options.VoiceConfig.VoiceIdConfig
@@ -346,11 +1264,16 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 		go func(prov providers.Provider) {
 			defer wg.Done()
 			defer mut.Unlock()
-			voiceId, err := a.getProviderInstance(prov).FindVoice(options)
+			var voiceId *VoiceIdConfig = nil
+			provInstance, err := a.getProviderInstance(prov)
+			if err == nil {
+				voiceId, err = a.findVoice(ctx, prov, provInstance, options)
+			}
 			mut.Lock()
 			if err != nil {
-				fmt.Printf("Error while trying to find voice for provider %s: %s", prov, err.Error())
+				LoggerFromContext(ctx).Debug("Error while trying to find voice", LogKeyProvider, prov, LogKeyError, err)
 				voicePerProvider[prov] = nil
+				voiceErrors = append(voiceErrors, err)
 			} else {
 				voicePerProvider[prov] = voiceId
 			}
@@ -359,6 +1282,11 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 
 	wg.Wait()
 
+	// if the context was canceled while the voices were searched, the missing voices are not meaningful
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return options, errors.Join(errors.New("error while trying to find voice"), ctxErr)
+	}
+
 	for prov, voice := range voicePerProvider {
 		if voice == nil {
 			delete(voicePerProvider, prov)
@@ -367,9 +1295,11 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 
 	if len(voicePerProvider) < 2 {
 		if len(voicePerProvider) < 1 {
-			return options, errors.New(fmt.Sprintf(
+			// the errors of the providers are included, so that e.g. authentication errors can be distinguished
+			return options, errors.Join(append([]error{ErrVoiceNotFound, errors.New(fmt.Sprintf(
 				"Error while trying to find voice. No voice found with the given language '%s' and gender '%s' on any provider.",
-				options.VoiceConfig.VoiceParamsConfig.LanguageCode, options.VoiceConfig.VoiceParamsConfig.Gender))
+				options.VoiceConfig.VoiceParamsConfig.LanguageCode, options.VoiceConfig.VoiceParamsConfig.Gender))},
+				voiceErrors...)...)
 		}
 
 		// Only one provider offers this voice -> use this provider
@@ -384,7 +1314,8 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 	// Second heuristic: Choose provider on which the destination file should be stored
 
 	for prov, voice := range voicePerProvider {
-		if a.getProviderInstance(prov).IsURLonOwnStorage(destination) {
+		provInstance, err := a.getProviderInstance(prov)
+		if err == nil && provInstance.IsURLonOwnStorage(destination) {
 			options.Provider = prov
 			options.VoiceConfig.VoiceIdConfig = *voice
 			return options, nil
@@ -394,7 +1325,11 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 	// Third/Fourth heuristic: Choose provider that offers the chosen output format
 	if options.OutputFormat != AudioFormatUnspecified {
 		for prov, _ := range voicePerProvider {
-			audioFormats := a.getProviderInstance(prov).GetSupportedAudioFormats()
+			provInstance, err := a.getProviderInstance(prov)
+			if err != nil {
+				continue
+			}
+			audioFormats := provInstance.GetSupportedAudioFormats()
 			// make sure at least one provider is still available in the end
 			if !IncludesAudioFormat(audioFormats, options.OutputFormat) && (len(voicePerProvider) > 1) {
 				delete(voicePerProvider, prov)
@@ -407,18 +1342,54 @@ func (a GoT2SClient) determineProvider(options TextToSpeechOptions, destination
 	// * Multiple providers support the output format
 	// * Only one provider is left, which supports the output format
 	// * Only one provider is left, which doesn't support the output format
-	// Use first provider in map (i.e. random provider that is still left)
-	for prov, voice := range voicePerProvider {
-		options.Provider = prov
-		options.VoiceConfig.VoiceIdConfig = *voice
-		return options, nil
+	// Use first provider that is still left (in the order in which the providers were registered)
+	for _, prov := range GetRegisteredProviders() {
+		if voice, ok := voicePerProvider[prov]; ok {
+			options.Provider = prov
+			options.VoiceConfig.VoiceIdConfig = *voice
+			return options, nil
+		}
+	}
+
+	return options, errors.Join(ErrProviderUnavailable, errors.New("error while choosing provider for text-to-speech: Undefined error. This error should not have happened"))
+}
+
+// countingReader counts the bytes that are read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// releasingReader calls release as soon as the underlying reader is read completely, fails or is closed.
+type releasingReader struct {
+	reader  io.Reader
+	release func()
+}
+
+func (r *releasingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil {
+		r.release()
 	}
+	return n, err
+}
 
-	return options, errors.New("error while choosing provider for text-to-speech: Undefined error. This error should not have happened")
+func (r *releasingReader) Close() error {
+	defer r.release()
+	if closer, isCloser := r.reader.(io.Closer); isCloser {
+		return closer.Close()
+	}
+	return nil
 }
 
 // IsProviderStorageUrl checks if the given string is a valid file URL for a storage service of one of the
 // supported storage providers.
-func (a GoT2SClient) IsProviderStorageUrl(url string) bool {
+func (a *GoT2SClient) IsProviderStorageUrl(url string) bool {
 	return IsAWSUrl(url) || IsGoogleUrl(url)
 }
@@ -0,0 +1,495 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ts2_aws "github.com/FaaSTools/GoText2Speech/GoText2Speech/aws"
+	ts2_gcp "github.com/FaaSTools/GoText2Speech/GoText2Speech/gcp"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errFakeThrottling = errors.New("fake throttling")
+
+// fakeProvider is a provider that "synthesizes" the text itself (i.e. the audio data is the text).
+type fakeProvider struct {
+	name providers.Provider
+	// clientsCreated counts the calls of CreateServiceClient
+	clientsCreated *int32
+	// syntheses counts the calls of ExecuteT2SDirect
+	syntheses *int32
+	// failWith is returned by ExecuteT2SDirect if set
+	failWith error
+	// inFlight and maxInFlight count the concurrent calls of ExecuteT2SDirect
+	inFlight    *int32
+	maxInFlight *int32
+}
+
+func (p fakeProvider) TransformOptions(text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error) {
+	return text, options, nil
+}
+
+func (p fakeProvider) FindVoice(ctx context.Context, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	voices, _ := p.ListVoices(ctx, VoiceFilterOf(options))
+	return p.SelectVoice(voices, options)
+}
+
+func (p fakeProvider) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
+	voices := []Voice{{Provider: p.name, Id: string(p.name) + "-voice", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderFemale}}
+	return FilterVoices(voices, filter), nil
+}
+
+func (p fakeProvider) SelectVoice(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	return FindVoiceInList(voices, options)
+}
+
+func (p fakeProvider) CreateServiceClient(credentials CredentialsHolder, region string) (T2SProvider, error) {
+	atomic.AddInt32(p.clientsCreated, 1)
+	return p, nil
+}
+
+func (p fakeProvider) ExecuteT2SDirect(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+	atomic.AddInt32(p.syntheses, 1)
+	inFlight := atomic.AddInt32(p.inFlight, 1)
+	defer atomic.AddInt32(p.inFlight, -1)
+	for max := atomic.LoadInt32(p.maxInFlight); (inFlight > max) && !atomic.CompareAndSwapInt32(p.maxInFlight, max, inFlight); {
+		max = atomic.LoadInt32(p.maxInFlight)
+	}
+	time.Sleep(time.Millisecond)
+	if p.failWith != nil {
+		return nil, p.failWith
+	}
+	return strings.NewReader(text), nil
+}
+
+func (p fakeProvider) ExecuteT2SWithSpeechMarks(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error) {
+	audioData, err := p.ExecuteT2SDirect(ctx, text, destination, options)
+	return audioData, nil, err
+}
+
+func (p fakeProvider) UploadFile(ctx context.Context, file io.Reader, destination string) error {
+	return nil
+}
+
+func (p fakeProvider) IsURLonOwnStorage(url string) bool {
+	return strings.HasPrefix(url, "fake://")
+}
+
+func (p fakeProvider) GetTextLengthLimit() TextLengthLimit {
+	return TextLengthLimit{MaxLength: 3000, Unit: TextLengthUnitCharacters}
+}
+
+func (p fakeProvider) GetSSMLProfile() ssml.Profile {
+	return ssml.Profile{}
+}
+
+func (p fakeProvider) GetBillingPolicy(options TextToSpeechOptions) BillingPolicy {
+	return BillingPolicy{Tier: "standard"}
+}
+
+func (p fakeProvider) IsRetryableError(err error) bool {
+	return errors.Is(err, errFakeThrottling)
+}
+
+func (p fakeProvider) GetSupportedAudioFormats() []AudioFormat {
+	return []AudioFormat{AudioFormatMp3}
+}
+
+// StartT2SJob starts a fake job that is finished after two polls (see GetT2SJob).
+func (p fakeProvider) StartT2SJob(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SJob, error) {
+	return &T2SJob{Provider: p.name, Id: "job-" + text, Destination: destination, VoiceIdConfig: options.VoiceConfig.VoiceIdConfig}, nil
+}
+
+func (p fakeProvider) GetT2SJob(ctx context.Context, job T2SJob) (*T2SJob, error) {
+	job.Status = T2SJobStatusRunning
+	job.Progress += 0.5
+	if job.Progress >= 1 {
+		job.Status = T2SJobStatusSucceeded
+		if p.failWith != nil {
+			job.Status = T2SJobStatusFailed
+			job.StatusReason = p.failWith.Error()
+		}
+	}
+	return &job, nil
+}
+
+func (p fakeProvider) CancelT2SJob(ctx context.Context, job T2SJob) error {
+	return ErrT2SJobCancelNotSupported
+}
+
+func (p fakeProvider) GetT2SJobTextLengthLimit() TextLengthLimit {
+	return TextLengthLimit{MaxLength: 100000, Unit: TextLengthUnitCharacters}
+}
+
+func (p fakeProvider) CloseServiceClient() error {
+	return nil
+}
+
+func (p fakeProvider) AddFileExtensionToDestinationIfNeeded(options TextToSpeechOptions, outputFormatRaw any, destination string) (string, error) {
+	return destination, nil
+}
+
+// registerFakeProviders replaces the built-in providers with the given fake providers for the duration of the test,
+// so that no requests are sent to AWS or GCP.
+// minimalProvider only implements T2SProvider, i.e. none of the optional provider interfaces (e.g. T2SJobProvider).
+type minimalProvider struct {
+	T2SProvider
+}
+
+func (p minimalProvider) CreateServiceClient(credentials CredentialsHolder, region string) (T2SProvider, error) {
+	return p, nil
+}
+
+func registerFakeProviders(t *testing.T, fakeProviders ...fakeProvider) {
+	UnregisterProvider(providers.ProviderAWS)
+	UnregisterProvider(providers.ProviderGCP)
+	for _, fake := range fakeProviders {
+		fake := fake
+		_ = RegisterProvider(fake.name, func() T2SProvider { return fake })
+	}
+	t.Cleanup(func() {
+		for _, fake := range fakeProviders {
+			UnregisterProvider(fake.name)
+		}
+		_ = RegisterProvider(providers.ProviderAWS, func() T2SProvider {
+			return ts2_aws.T2SAmazonWebServices{}
+		})
+		_ = RegisterProvider(providers.ProviderGCP, func() T2SProvider {
+			return ts2_gcp.T2SGoogleCloudPlatform{}
+		})
+	})
+}
+
+func newFakeProvider(name string) fakeProvider {
+	return fakeProvider{name: providers.Provider(name), clientsCreated: new(int32), syntheses: new(int32),
+		inFlight: new(int32), maxInFlight: new(int32)}
+}
+
+func testOptions() TextToSpeechOptions {
+	options := GetDefaultTextToSpeechOptions()
+	options.VoiceConfig.VoiceParamsConfig = VoiceParamsConfig{LanguageCode: "en-US", Gender: VoiceGenderFemale}
+	return *options
+}
+
+func TestProviderClientIsCreatedOnce(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.getProviderInstance(fake.name); err != nil {
+				t.Errorf("getProviderInstance returned error: %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+	if created := atomic.LoadInt32(fake.clientsCreated); created != 1 {
+		t.Errorf("Service client was created %d times, wanted 1", created)
+	}
+
+	if err := client.CloseAllProviderClients(); err != nil {
+		t.Errorf("CloseAllProviderClients returned error: %s", err.Error())
+	}
+	_, _ = client.getProviderInstance(fake.name)
+	if created := atomic.LoadInt32(fake.clientsCreated); created != 2 {
+		t.Errorf("Service client was not created again after closing (created %d times)", created)
+	}
+}
+
+func TestConcurrentT2SDirect(t *testing.T) {
+	first := newFakeProvider("FAKE_1")
+	second := newFakeProvider("FAKE_2")
+	registerFakeProviders(t, first, second)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			options := testOptions()
+			if i%2 == 0 {
+				options.Provider = second.name
+			}
+			text := fmt.Sprintf("Text number %d", i)
+			destination := filepath.Join(dir, fmt.Sprintf("audio_%d.mp3", i))
+			result, err := client.T2SDirectWithResult(context.Background(), text, destination, options)
+			if err != nil {
+				t.Errorf("T2SDirectWithResult returned error: %s", err.Error())
+				return
+			}
+			if content, _ := os.ReadFile(result.Destination); string(content) != text {
+				t.Errorf("Wrong audio data for text %d: %s", i, string(content))
+			}
+		}(i)
+		// settings are changed while the client is in use
+		go func(i int) {
+			defer wg.Done()
+			client.SetTempBucket(first.name, fmt.Sprintf("bucket-%d", i))
+			client.SetRetryPolicy(GetDefaultRetryPolicy())
+			client.SetRateLimit(second.name, RateLimit{MaxConcurrency: 2})
+			_, _ = client.ListVoices(context.Background(), VoiceFilter{})
+		}(i)
+	}
+	wg.Wait()
+
+	if created := atomic.LoadInt32(first.clientsCreated) + atomic.LoadInt32(second.clientsCreated); created != 2 {
+		t.Errorf("Service clients were created %d times, wanted 2", created)
+	}
+}
+
+func TestVoicesOfProviderWithoutVoiceList(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	minimal := newFakeProvider("FAKE_MINIMAL")
+	registerFakeProviders(t, fake, minimal)
+	UnregisterProvider(minimal.name)
+	_ = RegisterProvider(minimal.name, func() T2SProvider { return minimalProvider{minimal} })
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	// providers that can't list their voices are skipped, unless they are requested explicitly
+	voices, err := client.ListVoices(context.Background(), VoiceFilter{})
+	if (err != nil) || (len(voices) != 1) || (voices[0].Provider != fake.name) {
+		t.Errorf("Wrong voices: %+v (error: %v)", voices, err)
+	}
+	if _, err = client.ListVoices(context.Background(), VoiceFilter{Provider: minimal.name}); !errors.Is(err, ErrVoiceListNotSupported) {
+		t.Errorf("Wrong error when listing voices of provider without voice list: %v", err)
+	}
+
+	// the voice is found with FindVoice instead of the voice catalog
+	voiceIdConfig, err := client.findVoice(context.Background(), minimal.name, minimalProvider{minimal}, testOptions())
+	if err != nil {
+		t.Fatalf("findVoice returned error: %s", err.Error())
+	}
+	if voiceIdConfig.VoiceId != "FAKE_MINIMAL-voice" {
+		t.Errorf("Wrong voice: %s", voiceIdConfig.VoiceId)
+	}
+}
+
+func TestRateLimitIsHeldUntilAudioIsRead(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	client.SetRetryPolicy(NoRetryPolicy())
+	client.SetRateLimit(fake.name, RateLimit{MaxConcurrency: 1, FailFast: true})
+
+	options := testOptions()
+	options.Provider = fake.name
+	audioData, _, err := client.executeT2S(context.Background(), fake, "Hello", "", options)
+	if err != nil {
+		t.Fatalf("executeT2S returned error: %s", err.Error())
+	}
+	if _, _, err = client.executeT2S(context.Background(), fake, "Hello", "", options); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Request was sent while the audio of another request was not read: %v", err)
+	}
+	if _, err = io.ReadAll(audioData); err != nil {
+		t.Fatalf("ReadAll returned error: %s", err.Error())
+	}
+	if _, _, err = client.executeT2S(context.Background(), fake, "Hello", "", options); err != nil {
+		t.Errorf("Request was rejected after the audio was read: %s", err.Error())
+	}
+}
+
+func TestSpeechMarksNotSupported(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	options := testOptions()
+	options.Provider = fake.name
+	options.SpeechMarkTypes = []SpeechMarkType{SpeechMarkTypeWord}
+
+	if _, _, err := client.executeT2S(context.Background(), minimalProvider{fake}, "Hello", "", options); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Wrong error when requesting speech marks from provider without speech marks: %v", err)
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 0 {
+		t.Errorf("%d requests were sent, wanted 0", syntheses)
+	}
+}
+
+func TestChunkingWithoutTextLengthLimit(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	options := testOptions()
+	options.Provider = fake.name
+	options.SplitLongText = true
+	text := strings.Repeat("Sentence. ", 400)
+
+	// the text exceeds the limit of the fake provider, which isn't known without TextLengthLimitProvider
+	if _, _, err := client.executeT2SInChunks(context.Background(), minimalProvider{fake}, text, "", options); err != nil {
+		t.Fatalf("executeT2SInChunks returned error: %s", err.Error())
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 1 {
+		t.Errorf("Synthesized %d chunks, wanted 1", syntheses)
+	}
+	options.MaxChunkLength = 2000
+	if _, _, err := client.executeT2SInChunks(context.Background(), minimalProvider{fake}, text, "", options); err != nil {
+		t.Fatalf("executeT2SInChunks returned error: %s", err.Error())
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 3 {
+		t.Errorf("Synthesized %d chunks in total, wanted 3", syntheses)
+	}
+}
+
+func TestFailoverToOtherProvider(t *testing.T) {
+	down := newFakeProvider("FAKE_DOWN")
+	down.failWith = errFakeThrottling
+	up := newFakeProvider("FAKE_UP")
+	registerFakeProviders(t, down, up)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	client.SetRetryPolicy(NoRetryPolicy())
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	options := testOptions()
+	options.Provider = down.name
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", destination, options); !errors.Is(err, errFakeThrottling) {
+		t.Errorf("Error was not returned without failover: %v", err)
+	}
+
+	options.Failover = true
+	result, err := client.T2SDirectWithResult(context.Background(), "Hello", destination, options)
+	if err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	if (result.Provider != up.name) || (result.VoiceIdConfig.VoiceId != "FAKE_UP-voice") {
+		t.Errorf("Wrong provider or voice after failover: %s, %s", result.Provider, result.VoiceIdConfig.VoiceId)
+	}
+	if (len(result.FailedProviders) != 1) || (result.FailedProviders[0] != down.name) {
+		t.Errorf("Failed providers were not reported: %v", result.FailedProviders)
+	}
+}
+
+func TestErrorsAreClassified(t *testing.T) {
+	registerFakeProviders(t, newFakeProvider("FAKE"))
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	options := testOptions()
+	options.Provider = "NOT_REGISTERED"
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", destination, options); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Unregistered provider returned %v, wanted ErrProviderUnavailable", err)
+	}
+
+	options = testOptions()
+	options.TextType = TextTypeSsml
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", destination, options); !errors.Is(err, ErrInvalidSSML) {
+		t.Errorf("SSML text without <speak>-tags returned %v, wanted ErrInvalidSSML", err)
+	}
+
+	options = testOptions()
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", filepath.Join(t.TempDir(), "missing", "audio.mp3"), options); !errors.Is(err, ErrUploadFailed) {
+		t.Errorf("Invalid local destination returned %v, wanted ErrUploadFailed", err)
+	}
+}
+
+// recordingLogger records the messages and fields of all log calls.
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(level string, msg string, args []any) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprint(append([]any{level, msg}, args...)...))
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.record("DEBUG", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.record("INFO", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.record("WARN", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.record("ERROR", msg, args) }
+
+func (l *recordingLogger) contains(substring string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, message := range l.messages {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoggerRedactsText(t *testing.T) {
+	registerFakeProviders(t, newFakeProvider("FAKE"))
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	logger := &recordingLogger{}
+	client.SetLogger(logger)
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	if _, err := client.T2SDirectWithResult(context.Background(), "Secret text", destination, testOptions()); err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	if !logger.contains("Stored synthesized speech") || !logger.contains("FAKE-voice") {
+		t.Errorf("Synthesis was not logged: %v", logger.messages)
+	}
+	if logger.contains("Secret text") {
+		t.Errorf("Text was logged although it should be redacted: %v", logger.messages)
+	}
+
+	client.SetLogUserText(true)
+	if _, err := client.T2SDirectWithResult(context.Background(), "Secret text", destination, testOptions()); err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	if !logger.contains("Secret text") {
+		t.Errorf("Text was not logged although it was enabled: %v", logger.messages)
+	}
+}
+
+func TestDefaultLoggerIsSilent(t *testing.T) {
+	registerFakeProviders(t, newFakeProvider("FAKE"))
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe returned error: %s", err.Error())
+	}
+	os.Stdout = writer
+	_, t2sErr := client.T2SDirectWithResult(context.Background(), "Secret text", destination, testOptions())
+	os.Stdout = stdout
+	_ = writer.Close()
+	output, _ := io.ReadAll(reader)
+
+	if t2sErr != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", t2sErr.Error())
+	}
+	if len(output) > 0 {
+		t.Errorf("Default client printed to stdout: %s", output)
+	}
+}
+
+func TestChunkConcurrencyIsLimited(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	options := testOptions()
+	options.Provider = fake.name
+	options.SplitLongText = true
+	options.MaxChunkLength = 10
+	options.MaxChunkConcurrency = 2
+	if _, err := client.T2SDirectWithResult(context.Background(), strings.Repeat("Sentence. ", 20), destination, options); err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 20 {
+		t.Errorf("Synthesized %d chunks, wanted 20", syntheses)
+	}
+	if maxInFlight := atomic.LoadInt32(fake.maxInFlight); maxInFlight > 2 {
+		t.Errorf("Synthesized %d chunks at the same time, wanted at most 2", maxInFlight)
+	}
+}
@@ -0,0 +1,133 @@
+package GoText2Speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"io"
+	"os"
+)
+
+// T2SManifest synthesizes all entries of the manifest at the given local path (see ManifestEntry and ManifestFormat)
+// as a batch (see T2SBatch). The sources and destinations of the entries can have all locations that T2S and
+// T2SDirect support. After each entry is finished, its result is appended to the checkpoint file at the given local
+// path (see ManifestCheckpoint). If the checkpoint file already exists, the entries that are done according to it are
+// skipped, so that an interrupted job can be resumed by calling T2SManifest again with the same paths.
+// Entries that failed are synthesized again.
+// Returns a summary of the job and the errors of all entries that failed.
+func (a *GoT2SClient) T2SManifest(manifestPath string, checkpointPath string, opts ManifestOptions) (*ManifestSummary, error) {
+	return a.T2SManifestWithContext(context.Background(), manifestPath, checkpointPath, opts)
+}
+
+// T2SManifestWithContext is the same as T2SManifest, but the given context is passed to the synthesis of all entries
+// (see T2SBatchWithContext). Entries that weren't finished when the context is canceled are resumed by the next run.
+func (a *GoT2SClient) T2SManifestWithContext(ctx context.Context, manifestPath string, checkpointPath string, opts ManifestOptions) (*ManifestSummary, error) {
+	ctx = a.contextWithLogger(ctx)
+	entries, err := ReadManifestFile(manifestPath, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := ReadManifestCheckpointFile(checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+	checkpointFile, err := os.OpenFile(checkpointPath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while opening checkpoint file %s", checkpointPath)), err)
+	}
+	defer checkpointFile.Close()
+	if err = repairManifestCheckpointFile(checkpointFile); err != nil {
+		return nil, err
+	}
+
+	options := GetDefaultTextToSpeechOptions()
+	if opts.Options != nil {
+		options = opts.Options
+	}
+
+	summary := &ManifestSummary{Total: len(entries)}
+	var entryErrors []error
+	// the manifest entries of the batch items, i.e. batchEntries[i] belongs to items[i]
+	var batchEntries []ManifestEntry
+	var items []BatchItem
+	for i, entry := range entries {
+		if checkpoints[entry.Key()].Done {
+			summary.AlreadyDone++
+			continue
+		}
+		item, itemErr := entry.BatchItem(*options)
+		if itemErr != nil {
+			summary.Failed++
+			itemErr = errors.Join(errors.New(fmt.Sprintf("invalid manifest entry %d (%s)", i+1, entry.Key())), itemErr)
+			entryErrors = append(entryErrors, itemErr)
+			if writeErr := writeManifestCheckpoint(checkpointFile, ManifestCheckpointOf(entry, nil, itemErr)); writeErr != nil {
+				return summary, writeErr
+			}
+			continue
+		}
+		batchEntries = append(batchEntries, entry)
+		items = append(items, item)
+	}
+	if (len(entryErrors) > 0) && opts.Batch.StopOnError {
+		return summary, errors.Join(entryErrors...)
+	}
+
+	LoggerFromContext(ctx).Info("Processing manifest", "manifest", manifestPath, "entries", summary.Total,
+		"alreadyDone", summary.AlreadyDone)
+	for result := range a.T2SBatchWithContext(ctx, items, opts.Batch) {
+		entry := batchEntries[result.Index]
+		if result.Err != nil {
+			summary.Failed++
+			entryErrors = append(entryErrors, errors.Join(errors.New(fmt.Sprintf("manifest entry %s failed", entry.Key())), result.Err))
+		} else {
+			summary.Succeeded++
+		}
+		// items that weren't started are not recorded, so that they are resumed without being reported as failed
+		if errors.Is(result.Err, ErrBatchItemSkipped) || ((result.Err != nil) && (ctx.Err() != nil)) {
+			continue
+		}
+		if writeErr := writeManifestCheckpoint(checkpointFile, ManifestCheckpointOf(entry, result.Result, result.Err)); writeErr != nil {
+			// the job continues, the entry is just synthesized again if the job is resumed
+			entryErrors = append(entryErrors, writeErr)
+		}
+	}
+	return summary, errors.Join(entryErrors...)
+}
+
+// repairManifestCheckpointFile terminates the last line of the given checkpoint file if it isn't terminated, which
+// happens if a previous run was killed while writing a checkpoint. Otherwise, the next checkpoint would be appended to
+// that line. If the unterminated line isn't a valid checkpoint, it is removed, i.e. its entry is synthesized again.
+func repairManifestCheckpointFile(file *os.File) error {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return errors.Join(errors.New(fmt.Sprintf("error while reading checkpoint file %s", file.Name())), err)
+	}
+	if (len(content) == 0) || (content[len(content)-1] == '\n') {
+		return nil
+	}
+	lastLineStart := bytes.LastIndexByte(content, '\n') + 1
+	if json.Valid(bytes.TrimSpace(content[lastLineStart:])) {
+		_, err = file.Write([]byte{'\n'})
+	} else {
+		err = file.Truncate(int64(lastLineStart))
+	}
+	if err != nil {
+		return errors.Join(errors.New(fmt.Sprintf("error while repairing checkpoint file %s", file.Name())), err)
+	}
+	return nil
+}
+
+// writeManifestCheckpoint appends the given checkpoint as a single line to the given checkpoint file.
+func writeManifestCheckpoint(file *os.File, checkpoint ManifestCheckpoint) error {
+	line, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.Join(errors.New("error while encoding checkpoint"), err)
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return errors.Join(errors.New(fmt.Sprintf("error while writing checkpoint file %s", file.Name())), err)
+	}
+	return nil
+}
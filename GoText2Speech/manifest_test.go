@@ -0,0 +1,98 @@
+package GoText2Speech
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestT2SManifestResumes(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	failing := newFakeProvider("FAKE_FAILING")
+	failing.failWith = errFakeSynthesis
+	registerFakeProviders(t, fake, failing)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	dir := t.TempDir()
+	manifest := "text,destination,provider,languageCode,gender\n"
+	for i, provider := range []fakeProvider{fake, failing, fake} {
+		manifest += fmt.Sprintf("Text number %d,%s,%s,en-US,female\n", i, filepath.Join(dir, fmt.Sprintf("audio_%d.mp3", i)), provider.name)
+	}
+	manifestPath := filepath.Join(dir, "manifest.csv")
+	checkpointPath := filepath.Join(dir, "checkpoint.jsonl")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := client.T2SManifest(manifestPath, checkpointPath, ManifestOptions{Batch: BatchOptions{Concurrency: 2}})
+	if !errors.Is(err, errFakeSynthesis) {
+		t.Errorf("Error of failed entry was not returned: %v", err)
+	}
+	if (summary == nil) || (*summary != ManifestSummary{Total: 3, Succeeded: 2, Failed: 1}) {
+		t.Fatalf("Wrong summary of first run: %+v", summary)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "audio_2.mp3")); string(content) != "Text number 2" {
+		t.Errorf("Wrong audio data: %s", string(content))
+	}
+
+	// the next run only synthesizes the failed entry
+	summary, _ = client.T2SManifest(manifestPath, checkpointPath, ManifestOptions{})
+	if (summary == nil) || (*summary != ManifestSummary{Total: 3, Failed: 1, AlreadyDone: 2}) {
+		t.Errorf("Wrong summary of resumed run: %+v", summary)
+	}
+	checkpoints, err := ReadManifestCheckpointFile(checkpointPath)
+	if err != nil {
+		t.Fatalf("ReadManifestCheckpointFile returned error: %s", err.Error())
+	}
+	failed := checkpoints[filepath.Join(dir, "audio_1.mp3")]
+	if (len(checkpoints) != 3) || failed.Done || (failed.Error == "") {
+		t.Errorf("Wrong checkpoints: %+v", checkpoints)
+	}
+}
+
+func TestT2SManifestResumesAfterTruncatedCheckpoint(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	dir := t.TempDir()
+	manifest := "text,destination,provider,languageCode,gender\n"
+	for i := 0; i < 3; i++ {
+		manifest += fmt.Sprintf("Text number %d,%s,%s,en-US,female\n", i, filepath.Join(dir, fmt.Sprintf("audio_%d.mp3", i)), fake.name)
+	}
+	manifestPath := filepath.Join(dir, "manifest.csv")
+	checkpointPath := filepath.Join(dir, "checkpoint.jsonl")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.T2SManifest(manifestPath, checkpointPath, ManifestOptions{}); err != nil {
+		t.Fatalf("T2SManifest returned error: %s", err.Error())
+	}
+
+	// simulates a run that was killed while writing the checkpoint of the last entry
+	content, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(checkpointPath, content[:len(content)-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first resumed run synthesizes the entry of the truncated checkpoint again, the second one nothing
+	for run, expected := range []ManifestSummary{{Total: 3, Succeeded: 1, AlreadyDone: 2}, {Total: 3, AlreadyDone: 3}} {
+		summary, err := client.T2SManifest(manifestPath, checkpointPath, ManifestOptions{})
+		if err != nil {
+			t.Fatalf("T2SManifest returned error in resumed run %d: %s", run+1, err.Error())
+		}
+		if (summary == nil) || (*summary != expected) {
+			t.Errorf("Wrong summary of resumed run %d: %+v", run+1, summary)
+		}
+	}
+	checkpoints, err := ReadManifestCheckpointFile(checkpointPath)
+	if (err != nil) || (len(checkpoints) != 3) {
+		t.Errorf("Wrong checkpoints: %+v (error: %v)", checkpoints, err)
+	}
+}
@@ -10,6 +10,9 @@ const (
 
 var allProviders = []Provider{ProviderAWS, ProviderGCP}
 
+// GetAllProviders returns all built-in providers.
+// Providers that were added using shared.RegisterProvider are not included.
+// Use shared.GetRegisteredProviders to get all providers that take part in the automatic provider selection.
 func GetAllProviders() []Provider {
 	return allProviders
 }
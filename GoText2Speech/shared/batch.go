@@ -0,0 +1,43 @@
+package shared
+
+import "errors"
+
+// ErrBatchItemSkipped is returned for batch items that were not synthesized,
+// because BatchOptions.StopOnError is set and a previous item failed.
+var ErrBatchItemSkipped = errors.New("batch item was skipped, because a previous item failed")
+
+// DefaultBatchConcurrency The number of items that are synthesized at the same time if BatchOptions.Concurrency is
+// not specified.
+const DefaultBatchConcurrency = 4
+
+// BatchItem is a single synthesis of a batch (see GoT2SClient.T2SBatch).
+// Exactly one of Text and Source must be specified.
+type BatchItem struct {
+	// Text The text that is synthesized (see GoT2SClient.T2SDirect).
+	Text string
+	// Source The location of the file that contains the text that is synthesized (see GoT2SClient.T2S).
+	Source      string
+	Destination string
+	Options     TextToSpeechOptions
+}
+
+// BatchOptions Defines how the items of a batch are synthesized.
+type BatchOptions struct {
+	// Concurrency The maximum number of items that are synthesized at the same time.
+	// If Concurrency is 0 or negative, DefaultBatchConcurrency is used.
+	Concurrency int
+	// StopOnError If true, no further items are started after an item failed. Items that are in progress are
+	// canceled, and items that weren't started yet are reported with ErrBatchItemSkipped.
+	// If false, all items are synthesized regardless of errors.
+	StopOnError bool
+}
+
+// BatchResult is the result of a single item of a batch.
+type BatchResult struct {
+	// Index The index of the item in the batch.
+	Index int
+	Item  BatchItem
+	// Result Information about the synthesis. nil if Err is not nil.
+	Result *T2SResult
+	Err    error
+}
@@ -0,0 +1,177 @@
+package shared
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"strings"
+	"unicode/utf8"
+)
+
+// BillingPolicy describes how a provider bills the text of a synthesis request (see BillingPolicyProvider).
+type BillingPolicy struct {
+	// Tier The pricing tier of the voice, e.g. "standard" or "neural" on AWS and "Standard", "Wavenet", "Neural2" or
+	// "Studio" on GCP. The rate of the tier is looked up in the PriceTable.
+	Tier string
+	// BillSSMLTags If true, the tags of SSML texts count as billed characters (e.g. on GCP).
+	// Otherwise, only the text between the tags is billed (e.g. on AWS).
+	BillSSMLTags bool
+	// UnbilledSSMLElements The names of the SSML elements whose tags are not billed, even if BillSSMLTags is true
+	// (e.g. <mark> on GCP).
+	UnbilledSSMLElements []string
+	// BillSpeechMarks If true, the speech marks are requested separately and billed like another synthesis of the
+	// text (e.g. on AWS).
+	BillSpeechMarks bool
+}
+
+// BilledCharacters returns the number of characters that the provider bills for synthesizing the given text with the
+// given options in a single request.
+func (p BillingPolicy) BilledCharacters(text string, options TextToSpeechOptions) int {
+	billed := utf8.RuneCountInString(text)
+	if options.TextType == TextTypeSsml {
+		billed = 0
+		for _, token := range ssml.Tokenize(text) {
+			if (token.Kind == ssml.TokenText) || (p.BillSSMLTags && !p.isUnbilledElement(token.Name)) {
+				billed += utf8.RuneCountInString(token.Raw)
+			}
+		}
+	}
+	if p.BillSpeechMarks && (len(options.SpeechMarkTypes) > 0) {
+		billed *= 2
+	}
+	return billed
+}
+
+func (p BillingPolicy) isUnbilledElement(name string) bool {
+	for _, element := range p.UnbilledSSMLElements {
+		if strings.EqualFold(element, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// BillingPolicyOf returns the billing policy of the given provider for the given options if the provider implements
+// BillingPolicyProvider. Otherwise, the zero BillingPolicy is returned: the characters of the text are billed without
+// the SSML tags, and the cost is not estimated, because the policy has no tier (see BillingRecord.Priced).
+func BillingPolicyOf(provider T2SProvider, options TextToSpeechOptions) BillingPolicy {
+	if policyProvider, ok := provider.(BillingPolicyProvider); ok {
+		return policyProvider.GetBillingPolicy(options)
+	}
+	return BillingPolicy{}
+}
+
+// PriceTable contains the rates of the providers per million billed characters, by provider and pricing tier
+// (see BillingPolicy.Tier). The rates can be adjusted to the actual prices of an account (e.g. negotiated discounts)
+// with SetRate. The free tiers of the providers are not taken into account.
+type PriceTable struct {
+	// Currency The currency of the rates, e.g. "USD".
+	Currency string
+	// Rates The prices per million billed characters by provider and tier. Tiers are compared case-insensitively.
+	Rates map[providers.Provider]map[string]float64
+}
+
+// GetDefaultPriceTable returns the list prices of AWS Polly and GCP Text-to-Speech in USD per million characters.
+// Prices change over time, so the rates should be checked against the current price lists
+// (https://aws.amazon.com/polly/pricing/ and https://cloud.google.com/text-to-speech/pricing).
+func GetDefaultPriceTable() PriceTable {
+	return PriceTable{
+		Currency: "USD",
+		Rates: map[providers.Provider]map[string]float64{
+			providers.ProviderAWS: {
+				"standard":   4,
+				"neural":     16,
+				"long-form":  100,
+				"generative": 30,
+			},
+			providers.ProviderGCP: {
+				"Standard":  4,
+				"Wavenet":   16,
+				"Neural2":   16,
+				"Polyglot":  16,
+				"News":      16,
+				"Studio":    160,
+				"Journey":   30,
+				"Chirp-HD":  30,
+				"Chirp3-HD": 30,
+			},
+		},
+	}
+}
+
+// SetRate sets the price per million billed characters of the given tier on the given provider.
+func (t *PriceTable) SetRate(provider providers.Provider, tier string, rate float64) {
+	if t.Rates == nil {
+		t.Rates = make(map[providers.Provider]map[string]float64)
+	}
+	if t.Rates[provider] == nil {
+		t.Rates[provider] = make(map[string]float64)
+	}
+	for existingTier := range t.Rates[provider] {
+		if strings.EqualFold(existingTier, tier) {
+			delete(t.Rates[provider], existingTier)
+		}
+	}
+	t.Rates[provider][tier] = rate
+}
+
+// Rate returns the price per million billed characters of the given tier on the given provider.
+// Returns false if the price table doesn't contain a rate for the tier.
+func (t PriceTable) Rate(provider providers.Provider, tier string) (float64, bool) {
+	for existingTier, rate := range t.Rates[provider] {
+		if strings.EqualFold(existingTier, tier) {
+			return rate, true
+		}
+	}
+	return 0, false
+}
+
+// Clone returns a deep copy of the price table.
+func (t PriceTable) Clone() PriceTable {
+	clone := PriceTable{Currency: t.Currency, Rates: make(map[providers.Provider]map[string]float64, len(t.Rates))}
+	for provider, rates := range t.Rates {
+		clone.Rates[provider] = make(map[string]float64, len(rates))
+		for tier, rate := range rates {
+			clone.Rates[provider][tier] = rate
+		}
+	}
+	return clone
+}
+
+// BillingRecord contains the billed characters and the estimated cost of a synthesis
+// (see T2SResult.Billing and GoT2SClient.EstimateCost).
+type BillingRecord struct {
+	// Tenant The tenant the synthesis was requested for (see TextToSpeechOptions.Tenant).
+	Tenant   string
+	Provider providers.Provider
+	// Tier The pricing tier of the voice (see BillingPolicy.Tier).
+	Tier string
+	// BilledCharacters The characters that the provider bills. Audio that was taken from the synthesis cache is not
+	// billed.
+	BilledCharacters int
+	// Requests The number of billed requests that were sent to the provider (e.g. one per chunk, see
+	// TextToSpeechOptions.SplitLongText).
+	Requests int
+	// Currency The currency of EstimatedCost (see PriceTable.Currency).
+	Currency string
+	// EstimatedCost The cost of the billed characters according to the price table of the client.
+	EstimatedCost float64
+	// Priced False if the price table doesn't contain a rate for the tier. EstimatedCost is 0 in that case.
+	Priced bool
+}
+
+// NewBillingRecord creates a record of the given billed characters and calculates their cost with the given price table.
+func NewBillingRecord(prices PriceTable, tenant string, provider providers.Provider, tier string, billedCharacters int, requests int) BillingRecord {
+	record := BillingRecord{
+		Tenant:           tenant,
+		Provider:         provider,
+		Tier:             tier,
+		BilledCharacters: billedCharacters,
+		Requests:         requests,
+		Currency:         prices.Currency,
+	}
+	if rate, ok := prices.Rate(provider, tier); ok {
+		record.EstimatedCost = float64(billedCharacters) * rate / 1000000
+		record.Priced = true
+	}
+	return record
+}
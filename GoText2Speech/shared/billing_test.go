@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"math"
+	"testing"
+)
+
+func TestBilledCharacters(t *testing.T) {
+	text := `<speak>Hello <mark name="a"/>world</speak>`
+	options := GetDefaultTextToSpeechOptions()
+	options.TextType = TextTypeSsml
+
+	withoutTags := BillingPolicy{BillSSMLTags: false}
+	if billed := withoutTags.BilledCharacters(text, *options); billed != 11 {
+		t.Errorf("Billed %d characters without SSML tags, wanted 11", billed)
+	}
+	withTags := BillingPolicy{BillSSMLTags: true, UnbilledSSMLElements: []string{"mark"}}
+	if billed := withTags.BilledCharacters(text, *options); billed != 26 {
+		t.Errorf("Billed %d characters with SSML tags except <mark>, wanted 26", billed)
+	}
+
+	options.TextType = TextTypeText
+	options.SpeechMarkTypes = []SpeechMarkType{SpeechMarkTypeWord}
+	speechMarks := BillingPolicy{BillSpeechMarks: true}
+	if billed := speechMarks.BilledCharacters("Grüße", *options); billed != 10 {
+		t.Errorf("Billed %d characters with speech marks, wanted 10", billed)
+	}
+}
+
+func TestPriceTable(t *testing.T) {
+	prices := GetDefaultPriceTable()
+	if rate, ok := prices.Rate(providers.ProviderGCP, "WAVENET"); !ok || (rate != 16) {
+		t.Errorf("Rate of GCP WaveNet voices is %f (%t), wanted 16", rate, ok)
+	}
+	clone := prices.Clone()
+	clone.SetRate(providers.ProviderGCP, "wavenet", 12)
+	if rate, _ := clone.Rate(providers.ProviderGCP, "Wavenet"); rate != 12 {
+		t.Errorf("Rate of GCP WaveNet voices is %f after SetRate, wanted 12", rate)
+	}
+	if rate, _ := prices.Rate(providers.ProviderGCP, "Wavenet"); rate != 16 {
+		t.Errorf("SetRate on a clone changed the original price table")
+	}
+	if _, ok := prices.Rate(providers.ProviderAWS, "unknown"); ok {
+		t.Errorf("Rate of an unknown tier was found")
+	}
+
+	record := NewBillingRecord(clone, "tenant-a", providers.ProviderGCP, "Wavenet", 500000, 1)
+	if !record.Priced || (math.Abs(record.EstimatedCost-6) > 1e-9) || (record.Currency != "USD") {
+		t.Errorf("Unexpected billing record: %+v", record)
+	}
+	if record := NewBillingRecord(PriceTable{}, "", providers.ProviderAWS, "neural", 100, 1); record.Priced || (record.EstimatedCost != 0) {
+		t.Errorf("Record without rate is priced: %+v", record)
+	}
+}
+
+func TestBillingPolicyWithoutPolicyProvider(t *testing.T) {
+	options := GetDefaultTextToSpeechOptions()
+	policy := BillingPolicyOf(testProvider{}, *options)
+	if (policy.Tier != "") || policy.BillSSMLTags || policy.BillSpeechMarks {
+		t.Errorf("Unexpected billing policy: %+v", policy)
+	}
+	if billed := policy.BilledCharacters("Hello", *options); billed != 5 {
+		t.Errorf("Billed %d characters, wanted 5", billed)
+	}
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+)
+
+// The following errors classify why a synthesis failed. All errors that are returned by GoT2SClient and the built-in
+// providers wrap at least one of them, so that callers can check the kind of an error with errors.Is
+// (e.g. to map it to an HTTP status code), without parsing the error message.
+var (
+	// ErrVoiceNotFound No voice matches the voice parameters of the options.
+	ErrVoiceNotFound = errors.New("no matching voice found")
+	// ErrUnsupportedFormat The output format is not supported by the provider.
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	// ErrInvalidSSML The SSML text is malformed or uses tags that the provider doesn't support.
+	ErrInvalidSSML = errors.New("invalid SSML")
+	// ErrInvalidOptions The options can't be used for the synthesis (e.g. a voice ID or speech mark type that the
+	// provider doesn't offer).
+	ErrInvalidOptions = errors.New("invalid text-to-speech options")
+	// ErrInvalidDestination The destination is not a valid location (e.g. an invalid S3 URI or a missing bucket).
+	ErrInvalidDestination = errors.New("invalid destination")
+	// ErrTextTooLong The text exceeds the text length limit of the provider.
+	ErrTextTooLong = errors.New("text is too long")
+	// ErrThrottled The provider rejected the request because of throttling or an exhausted quota.
+	ErrThrottled = errors.New("request was throttled by the provider")
+	// ErrAuthentication The credentials are missing, invalid or don't grant access to the requested resource.
+	ErrAuthentication = errors.New("authentication with the provider failed")
+	// ErrProviderUnavailable The provider is not registered, its service client couldn't be created or the service
+	// is temporarily unavailable.
+	ErrProviderUnavailable = errors.New("provider is unavailable")
+	// ErrSynthesisFailed The provider failed to synthesize the text for a reason that isn't classified otherwise.
+	ErrSynthesisFailed = errors.New("synthesis failed")
+	// ErrUploadFailed The audio file (or subtitle file) couldn't be stored at the destination.
+	ErrUploadFailed = errors.New("upload failed")
+	// ErrSourceUnavailable The source file of the text couldn't be read or downloaded (see GoT2SClient.T2S).
+	ErrSourceUnavailable = errors.New("source file is unavailable")
+)
+
+// Operations of a provider that are reported in ProviderError.Op.
+const (
+	OpListVoices   = "ListVoices"
+	OpSynthesize   = "Synthesize"
+	OpSpeechMarks  = "SpeechMarks"
+	OpUpload       = "Upload"
+	OpCreateClient = "CreateClient"
+	OpStartJob     = "StartJob"
+	OpGetJob       = "GetJob"
+	OpCancelJob    = "CancelJob"
+)
+
+// ProviderError is returned if a request to a provider failed. Kind is one of the errors above (e.g. ErrThrottled)
+// and Cause is the original error of the provider's SDK. Both can be checked with errors.Is and errors.As, e.g.
+//
+//	var providerErr *ProviderError
+//	if errors.As(err, &providerErr) && providerErr.Retryable { ... }
+type ProviderError struct {
+	Provider providers.Provider
+	// Op The operation that failed (e.g. OpSynthesize).
+	Op string
+	// Retryable True if the provider classified the error as retryable (see RetryableErrorClassifier).
+	Retryable bool
+	Kind      error
+	Cause     error
+}
+
+// NewProviderError creates a ProviderError. If kind is nil, ErrSynthesisFailed is used.
+func NewProviderError(provider providers.Provider, op string, kind error, retryable bool, cause error) *ProviderError {
+	if kind == nil {
+		kind = ErrSynthesisFailed
+	}
+	return &ProviderError{
+		Provider:  provider,
+		Op:        op,
+		Retryable: retryable,
+		Kind:      kind,
+		Cause:     cause,
+	}
+}
+
+func (e *ProviderError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%s %s: %s", e.Provider, e.Op, e.Kind)
+	}
+	return fmt.Sprintf("%s %s: %s: %s", e.Provider, e.Op, e.Kind, e.Cause)
+}
+
+// Unwrap returns the kind and the cause of the error, so that errors.Is and errors.As check both.
+func (e *ProviderError) Unwrap() []error {
+	return []error{e.Kind, e.Cause}
+}
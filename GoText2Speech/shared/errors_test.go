@@ -0,0 +1,44 @@
+package shared
+
+import (
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"testing"
+)
+
+func TestProviderErrorUnwrap(t *testing.T) {
+	cause := errors.New("quota exceeded")
+	err := error(NewProviderError(providers.ProviderGCP, OpSynthesize, ErrThrottled, true, cause))
+	wrapped := errors.Join(errors.New("error while synthesizing chunk 1 of 2"), err)
+
+	if !errors.Is(wrapped, ErrThrottled) {
+		t.Errorf("errors.Is doesn't find the kind in %s", wrapped.Error())
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Errorf("errors.Is doesn't find the cause in %s", wrapped.Error())
+	}
+	if errors.Is(wrapped, ErrAuthentication) {
+		t.Errorf("errors.Is finds a wrong kind in %s", wrapped.Error())
+	}
+	var providerErr *ProviderError
+	if !errors.As(wrapped, &providerErr) || (providerErr.Provider != providers.ProviderGCP) || !providerErr.Retryable {
+		t.Errorf("errors.As doesn't find the ProviderError in %s", wrapped.Error())
+	}
+	if want := "GCP Synthesize: request was throttled by the provider: quota exceeded"; err.Error() != want {
+		t.Errorf("Error() returned '%s', wanted '%s'", err.Error(), want)
+	}
+}
+
+func TestNewProviderErrorDefaultKind(t *testing.T) {
+	err := NewProviderError(providers.ProviderAWS, OpSynthesize, nil, false, errors.New("unknown"))
+	if !errors.Is(err, ErrSynthesisFailed) {
+		t.Errorf("ProviderError without kind is not ErrSynthesisFailed: %s", err.Error())
+	}
+}
+
+func TestFindVoiceInListNotFound(t *testing.T) {
+	options := GetDefaultTextToSpeechOptions()
+	if _, err := FindVoiceInList(nil, *options); !errors.Is(err, ErrVoiceNotFound) {
+		t.Errorf("FindVoiceInList returned %v, wanted ErrVoiceNotFound", err)
+	}
+}
@@ -0,0 +1,143 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger receives the diagnostics of GoT2SClient and the providers (see GoT2SClient.SetLogger). args are alternating
+// keys and values, e.g. logger.Info("Synthesized speech", LogKeyProvider, "AWS", LogKeyBytes, 1024).
+// The methods are the same as those of *slog.Logger (Go 1.21 or later), so a *slog.Logger can be used as Logger.
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Keys of the structured fields that are logged.
+const (
+	LogKeyProvider    = "provider"
+	LogKeyVoice       = "voice"
+	LogKeyBytes       = "bytes"
+	LogKeyDuration    = "duration"
+	LogKeyText        = "text"
+	LogKeyDestination = "destination"
+	LogKeyError       = "error"
+)
+
+// NopLogger is a Logger that discards all messages. It is the default logger of GoT2SClient.
+type NopLogger struct{}
+
+func (NopLogger) Debug(msg string, args ...any) {}
+func (NopLogger) Info(msg string, args ...any)  {}
+func (NopLogger) Warn(msg string, args ...any)  {}
+func (NopLogger) Error(msg string, args ...any) {}
+
+// LogLevel The severity of a log message.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return ""
+	}
+}
+
+// WriterLogger is a Logger that writes each message as one line in logfmt format to a writer, e.g.
+// `time=2024-01-02T15:04:05Z level=INFO msg="Synthesized speech" provider=AWS duration=1.2s`.
+// It is meant for environments in which log/slog is not available.
+type WriterLogger struct {
+	minLevel LogLevel
+	mutex    sync.Mutex
+	writer   io.Writer
+}
+
+// NewWriterLogger creates a WriterLogger that writes messages with at least the given level to the given writer.
+func NewWriterLogger(writer io.Writer, minLevel LogLevel) *WriterLogger {
+	return &WriterLogger{writer: writer, minLevel: minLevel}
+}
+
+func (l *WriterLogger) Debug(msg string, args ...any) { l.log(LogLevelDebug, msg, args) }
+func (l *WriterLogger) Info(msg string, args ...any)  { l.log(LogLevelInfo, msg, args) }
+func (l *WriterLogger) Warn(msg string, args ...any)  { l.log(LogLevelWarn, msg, args) }
+func (l *WriterLogger) Error(msg string, args ...any) { l.log(LogLevelError, msg, args) }
+
+func (l *WriterLogger) log(level LogLevel, msg string, args []any) {
+	if level < l.minLevel {
+		return
+	}
+	var line strings.Builder
+	line.WriteString("time=" + time.Now().UTC().Format(time.RFC3339))
+	line.WriteString(" level=" + level.String())
+	line.WriteString(" msg=" + logfmtValue(msg))
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) { // value without key
+			line.WriteString(" !BADKEY=" + logfmtValue(fmt.Sprint(args[i])))
+			break
+		}
+		line.WriteString(" " + fmt.Sprint(args[i]) + "=" + logfmtValue(fmt.Sprint(args[i+1])))
+	}
+	line.WriteString("\n")
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, _ = io.WriteString(l.writer, line.String())
+}
+
+// logfmtValue quotes the given value if it contains spaces, quotes or equal signs.
+func logfmtValue(value string) string {
+	if (value == "") || strings.ContainsAny(value, " \"=\n\t") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// loggerContextKey is the key of the logger in a context (see ContextWithLogger).
+type loggerContextKey struct{}
+
+// ContextWithLogger returns a copy of the given context that carries the given logger. GoT2SClient passes its logger
+// to the providers this way. A logger in the context of a request takes precedence over the logger of the client,
+// so that e.g. a logger with the ID of the request can be used.
+func ContextWithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger of the given context (see ContextWithLogger), or NopLogger if the context
+// doesn't carry a logger.
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(Logger); ok && (logger != nil) {
+		return logger
+	}
+	return NopLogger{}
+}
+
+// RedactText returns the given text if logText is true. Otherwise, only the length of the text is returned, so that
+// the text of users doesn't end up in the logs.
+func RedactText(text string, logText bool) string {
+	if logText {
+		return text
+	}
+	return fmt.Sprintf("[redacted, %d bytes]", len(text))
+}
@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWriterLogger(t *testing.T) {
+	var output bytes.Buffer
+	logger := NewWriterLogger(&output, LogLevelInfo)
+	logger.Debug("Not logged")
+	logger.Info("Synthesized speech", LogKeyProvider, "AWS", LogKeyBytes, 1024, LogKeyText, "Hello world")
+	logger.Error("Missing value", "key")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Logged %d lines, wanted 2: %s", len(lines), output.String())
+	}
+	if want := ` level=INFO msg="Synthesized speech" provider=AWS bytes=1024 text="Hello world"`; !strings.HasSuffix(lines[0], want) {
+		t.Errorf("Logged '%s', wanted suffix '%s'", lines[0], want)
+	}
+	if want := ` level=ERROR msg="Missing value" !BADKEY=key`; !strings.HasSuffix(lines[1], want) {
+		t.Errorf("Logged '%s', wanted suffix '%s'", lines[1], want)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	if _, ok := LoggerFromContext(context.Background()).(NopLogger); !ok {
+		t.Errorf("Context without logger didn't return NopLogger")
+	}
+	logger := NewWriterLogger(&bytes.Buffer{}, LogLevelDebug)
+	if LoggerFromContext(ContextWithLogger(context.Background(), logger)) != logger {
+		t.Errorf("Logger of context was not returned")
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	if got := RedactText("secret text", false); strings.Contains(got, "secret") {
+		t.Errorf("Text was not redacted: %s", got)
+	}
+	if got := RedactText("secret text", true); got != "secret text" {
+		t.Errorf("Text was redacted although logging text was enabled: %s", got)
+	}
+}
@@ -0,0 +1,355 @@
+package shared
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFormat The file format of a manifest (see ReadManifest).
+type ManifestFormat int
+
+const (
+	// ManifestFormatAuto The format is inferred from the file extension (see ManifestFormatOfFile).
+	ManifestFormatAuto ManifestFormat = iota
+	// ManifestFormatCSV A CSV file with a header row that contains the column names (the JSON names of the fields of
+	// ManifestEntry, case-insensitive). Columns may be omitted and appear in any order.
+	ManifestFormatCSV
+	// ManifestFormatJSONL A file with one JSON object (see ManifestEntry) per line. Empty lines are ignored.
+	ManifestFormatJSONL
+)
+
+// ManifestEntry is a single row of a manifest, i.e. a single synthesis of a manifest-driven batch job
+// (see GoT2SClient.T2SManifest). Exactly one of Source and Text must be specified. All fields except Destination are
+// optional. Fields that are empty keep the value of the options the manifest is processed with.
+type ManifestEntry struct {
+	// Id Identifies the entry in the checkpoint file. If Id is empty, the entry is identified by its Destination.
+	Id string `json:"id,omitempty"`
+	// Source The location of the file that contains the text (see GoT2SClient.T2S for the supported locations).
+	Source string `json:"source,omitempty"`
+	// Text The text that is synthesized. Use Source for long texts.
+	Text        string `json:"text,omitempty"`
+	Destination string `json:"destination"`
+	// Provider The name of the provider (e.g. "AWS" or "GCP", case-insensitive).
+	Provider     string `json:"provider,omitempty"`
+	VoiceId      string `json:"voiceId,omitempty"`
+	Engine       string `json:"engine,omitempty"`
+	LanguageCode string `json:"languageCode,omitempty"`
+	// Gender The name of the voice gender (see VoiceGender.UnmarshalText).
+	Gender string `json:"gender,omitempty"`
+	// OutputFormat One of the audio formats of GetAllAudioFormats (case-insensitive).
+	OutputFormat string `json:"outputFormat,omitempty"`
+}
+
+// Key returns the key that identifies the entry in the checkpoint file, i.e. its Id or (if empty) its Destination.
+func (e ManifestEntry) Key() string {
+	if e.Id != "" {
+		return e.Id
+	}
+	return e.Destination
+}
+
+// ApplyTo returns the given options with the provider, voice and output format of the entry.
+// If the entry specifies a voice ID, it replaces the voice of the given options.
+func (e ManifestEntry) ApplyTo(options TextToSpeechOptions) (TextToSpeechOptions, error) {
+	if e.Provider != "" {
+		options.Provider = providers.Provider(strings.ToUpper(e.Provider))
+	}
+	if e.VoiceId != "" {
+		options.VoiceConfig.VoiceIdConfig = VoiceIdConfig{VoiceId: e.VoiceId, Engine: e.Engine}
+	} else if e.Engine != "" {
+		options.VoiceConfig.VoiceParamsConfig.Engine = e.Engine
+	}
+	if e.LanguageCode != "" {
+		options.VoiceConfig.VoiceParamsConfig.LanguageCode = e.LanguageCode
+	}
+	if e.Gender != "" {
+		if err := options.VoiceConfig.VoiceParamsConfig.Gender.UnmarshalText([]byte(e.Gender)); err != nil {
+			return options, err
+		}
+	}
+	if e.OutputFormat != "" {
+		outputFormat := AudioFormatUnspecified
+		for _, format := range GetAllAudioFormats() {
+			if strings.EqualFold(string(format), e.OutputFormat) {
+				outputFormat = format
+			}
+		}
+		if outputFormat == AudioFormatUnspecified {
+			return options, errors.New(fmt.Sprintf("unknown output format '%s'", e.OutputFormat))
+		}
+		options.OutputFormat = outputFormat
+	}
+	return options, nil
+}
+
+// BatchItem returns the batch item that synthesizes the entry with the given options (see ApplyTo).
+func (e ManifestEntry) BatchItem(options TextToSpeechOptions) (BatchItem, error) {
+	if (e.Source == "") == (e.Text == "") {
+		return BatchItem{}, errors.New("exactly one of source and text must be specified")
+	}
+	if e.Destination == "" {
+		return BatchItem{}, errors.New("no destination specified")
+	}
+	entryOptions, err := e.ApplyTo(options)
+	if err != nil {
+		return BatchItem{}, err
+	}
+	return BatchItem{Text: e.Text, Source: e.Source, Destination: e.Destination, Options: entryOptions}, nil
+}
+
+// ManifestFormatOfFile infers the manifest format from the file extension of the given path
+// (".csv" or ".jsonl"/".ndjson", case-insensitive).
+func ManifestFormatOfFile(path string) (ManifestFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ManifestFormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return ManifestFormatJSONL, nil
+	default:
+		return ManifestFormatAuto, errors.New(fmt.Sprintf("unknown manifest format of file %s", path))
+	}
+}
+
+// ReadManifest reads all entries of a manifest in the given format (ManifestFormatAuto is not allowed here).
+// Returns an error if two entries have the same key (see ManifestEntry.Key).
+func ReadManifest(r io.Reader, format ManifestFormat) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	var err error
+	switch format {
+	case ManifestFormatCSV:
+		entries, err = readManifestCSV(r)
+	case ManifestFormatJSONL:
+		entries, err = readManifestJSONL(r)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported manifest format %d", format))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		if previous, exists := keys[entry.Key()]; exists {
+			return nil, errors.New(fmt.Sprintf("manifest entries %d and %d have the same key '%s'", previous+1, i+1, entry.Key()))
+		}
+		keys[entry.Key()] = i
+	}
+	return entries, nil
+}
+
+// ReadManifestFile reads the manifest at the given local path (see ReadManifest).
+// If format is ManifestFormatAuto, the format is inferred from the file extension.
+func ReadManifestFile(path string, format ManifestFormat) ([]ManifestEntry, error) {
+	if format == ManifestFormatAuto {
+		var err error
+		format, err = ManifestFormatOfFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while opening manifest %s", path)), err)
+	}
+	defer file.Close()
+	entries, err := ReadManifest(file, format)
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while reading manifest %s", path)), err)
+	}
+	return entries, nil
+}
+
+// readManifestCSV reads a manifest in the format ManifestFormatCSV.
+func readManifestCSV(r io.Reader) ([]ManifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the manifest has no header row")
+	}
+
+	columns := make([]string, len(records[0]))
+	for i, column := range records[0] {
+		columns[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+	entries := make([]ManifestEntry, 0, len(records)-1)
+	for row, record := range records[1:] {
+		var entry ManifestEntry
+		for i, value := range record {
+			field := manifestEntryField(&entry, columns[i])
+			if field == nil {
+				return nil, errors.New(fmt.Sprintf("unknown column '%s'", records[0][i]))
+			}
+			*field = strings.TrimSpace(value)
+		}
+		if entry == (ManifestEntry{}) {
+			continue
+		}
+		if entry.Destination == "" {
+			return nil, errors.New(fmt.Sprintf("row %d has no destination", row+2))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// manifestEntryField returns the field of the given entry that belongs to the given lower-case column name.
+// Returns nil if there is no such field.
+func manifestEntryField(entry *ManifestEntry, column string) *string {
+	switch column {
+	case "id":
+		return &entry.Id
+	case "source":
+		return &entry.Source
+	case "text":
+		return &entry.Text
+	case "destination":
+		return &entry.Destination
+	case "provider":
+		return &entry.Provider
+	case "voiceid":
+		return &entry.VoiceId
+	case "engine":
+		return &entry.Engine
+	case "languagecode":
+		return &entry.LanguageCode
+	case "gender":
+		return &entry.Gender
+	case "outputformat":
+		return &entry.OutputFormat
+	default:
+		return nil
+	}
+}
+
+// readManifestJSONL reads a manifest in the format ManifestFormatJSONL.
+func readManifestJSONL(r io.Reader) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		var entry ManifestEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, errors.Join(errors.New(fmt.Sprintf("invalid entry in line %d", line)), err)
+		}
+		if entry.Destination == "" {
+			return nil, errors.New(fmt.Sprintf("entry in line %d has no destination", line))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ManifestCheckpoint is a line of the checkpoint file of a manifest-driven batch job. It records the result of a
+// single manifest entry. The checkpoint file has one JSON object per line and is only ever appended to, so that
+// a later line for the same key replaces the previous ones (e.g. if a failed entry succeeds when the job is resumed).
+type ManifestCheckpoint struct {
+	// Key The key of the manifest entry (see ManifestEntry.Key).
+	Key string `json:"key"`
+	// Done true if the entry was synthesized successfully. Entries that are done are skipped when the job is resumed.
+	Done bool `json:"done"`
+	// Error The error message if the synthesis failed.
+	Error      string             `json:"error,omitempty"`
+	Provider   providers.Provider `json:"provider,omitempty"`
+	VoiceId    string             `json:"voiceId,omitempty"`
+	FinishedAt time.Time          `json:"finishedAt"`
+	// Destination The location the audio file was stored at (see T2SResult.Destination).
+	Destination string `json:"destination,omitempty"`
+}
+
+// ManifestCheckpointOf creates the checkpoint of the given manifest entry from the given result of its synthesis.
+func ManifestCheckpointOf(entry ManifestEntry, result *T2SResult, err error) ManifestCheckpoint {
+	checkpoint := ManifestCheckpoint{Key: entry.Key(), Done: err == nil, FinishedAt: time.Now().UTC()}
+	if err != nil {
+		checkpoint.Error = err.Error()
+	}
+	if result != nil {
+		checkpoint.Provider = result.Provider
+		checkpoint.VoiceId = result.VoiceIdConfig.VoiceId
+		checkpoint.Destination = result.Destination
+	}
+	return checkpoint
+}
+
+// ReadManifestCheckpoints reads a checkpoint file and returns the last checkpoint of each key.
+// An incomplete last line (e.g. because the job was interrupted while writing it) is ignored.
+func ReadManifestCheckpoints(r io.Reader) (map[string]ManifestCheckpoint, error) {
+	checkpoints := make(map[string]ManifestCheckpoint)
+	scanner := bufio.NewScanner(r)
+	var lineErr error
+	for line := 1; scanner.Scan(); line++ {
+		if lineErr != nil { // the invalid line was not the last line
+			return nil, lineErr
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var checkpoint ManifestCheckpoint
+		if err := json.Unmarshal([]byte(text), &checkpoint); err != nil {
+			lineErr = errors.Join(errors.New(fmt.Sprintf("invalid checkpoint in line %d", line)), err)
+			continue
+		}
+		checkpoints[checkpoint.Key] = checkpoint
+	}
+	return checkpoints, scanner.Err()
+}
+
+// ReadManifestCheckpointFile reads the checkpoint file at the given local path (see ReadManifestCheckpoints).
+// If the file doesn't exist, no checkpoints are returned.
+func ReadManifestCheckpointFile(path string) (map[string]ManifestCheckpoint, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]ManifestCheckpoint), nil
+	}
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while opening checkpoint file %s", path)), err)
+	}
+	defer file.Close()
+	checkpoints, err := ReadManifestCheckpoints(file)
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while reading checkpoint file %s", path)), err)
+	}
+	return checkpoints, nil
+}
+
+// ManifestOptions Defines how a manifest is processed (see GoT2SClient.T2SManifest).
+type ManifestOptions struct {
+	// Format The format of the manifest file. By default, the format is inferred from the file extension.
+	Format ManifestFormat
+	// Options The options for all entries, which the fields of each entry are applied to (see ManifestEntry.ApplyTo).
+	// If Options is nil, GetDefaultTextToSpeechOptions is used.
+	Options *TextToSpeechOptions
+	// Batch Defines how many entries are synthesized at the same time and whether the job stops after the first
+	// failed entry.
+	Batch BatchOptions
+}
+
+// ManifestSummary contains the number of entries of a manifest-driven batch job by outcome.
+type ManifestSummary struct {
+	Total int
+	// Succeeded The number of entries that were synthesized successfully by this run.
+	Succeeded int
+	// Failed The number of entries that failed (including invalid and skipped entries).
+	Failed int
+	// AlreadyDone The number of entries that were skipped, because they were done according to the checkpoint file.
+	AlreadyDone int
+}
@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"strings"
+	"testing"
+)
+
+func TestReadManifestCSV(t *testing.T) {
+	manifest := "Source, destination,voiceId,provider,outputFormat\n" +
+		"s3://bucket/a.txt,s3://bucket/a.mp3,Joanna,aws,MP3\n" +
+		"\"https://example.com/b.txt\",b.ogg,,,ogg\n"
+	entries, err := ReadManifest(strings.NewReader(manifest), ManifestFormatCSV)
+	if err != nil {
+		t.Fatalf("ReadManifest returned error: %s", err.Error())
+	}
+	expected := []ManifestEntry{
+		{Source: "s3://bucket/a.txt", Destination: "s3://bucket/a.mp3", VoiceId: "Joanna", Provider: "aws", OutputFormat: "MP3"},
+		{Source: "https://example.com/b.txt", Destination: "b.ogg", OutputFormat: "ogg"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Read %d entries, wanted %d", len(entries), len(expected))
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("Wrong entry %d: %+v", i, entries[i])
+		}
+	}
+
+	if _, err = ReadManifest(strings.NewReader("source,destination,voice\na,b,c\n"), ManifestFormatCSV); err == nil {
+		t.Errorf("Unknown column didn't cause an error")
+	}
+	if _, err = ReadManifest(strings.NewReader("source,destination\na,b\nc,b\n"), ManifestFormatCSV); err == nil {
+		t.Errorf("Duplicate keys didn't cause an error")
+	}
+}
+
+func TestReadManifestJSONL(t *testing.T) {
+	manifest := `{"id":"1","text":"Hello","destination":"a.mp3","gender":"female","languageCode":"de-DE"}
+
+{"id":"2","source":"gs://bucket/b.txt","destination":"a.mp3"}
+`
+	entries, err := ReadManifest(strings.NewReader(manifest), ManifestFormatJSONL)
+	if err != nil {
+		t.Fatalf("ReadManifest returned error: %s", err.Error())
+	}
+	if (len(entries) != 2) || (entries[0].Text != "Hello") || (entries[1].Key() != "2") {
+		t.Errorf("Wrong entries: %+v", entries)
+	}
+
+	if _, err = ReadManifest(strings.NewReader(`{"text":"Hello","destination":"a.mp3","voice":"Joanna"}`), ManifestFormatJSONL); err == nil {
+		t.Errorf("Unknown field didn't cause an error")
+	}
+}
+
+func TestManifestEntryBatchItem(t *testing.T) {
+	entry := ManifestEntry{Text: "Hello", Destination: "a.mp3", Provider: "gcp", LanguageCode: "de-DE", Gender: "male", OutputFormat: "OGG"}
+	item, err := entry.BatchItem(*GetDefaultTextToSpeechOptions())
+	if err != nil {
+		t.Fatalf("BatchItem returned error: %s", err.Error())
+	}
+	options := item.Options
+	if (options.Provider != providers.ProviderGCP) || (options.OutputFormat != AudioFormatOgg) ||
+		(options.VoiceConfig.VoiceParamsConfig != VoiceParamsConfig{LanguageCode: "de-DE", Gender: VoiceGenderMale}) {
+		t.Errorf("Entry was not applied to options: %+v", options)
+	}
+
+	invalidEntries := []ManifestEntry{
+		{Destination: "a.mp3"},
+		{Text: "Hello", Source: "a.txt", Destination: "a.mp3"},
+		{Text: "Hello", Destination: "a.mp3", Gender: "unknown"},
+		{Text: "Hello", Destination: "a.mp3", OutputFormat: "wav"},
+	}
+	for _, invalid := range invalidEntries {
+		if _, err = invalid.BatchItem(*GetDefaultTextToSpeechOptions()); err == nil {
+			t.Errorf("Invalid entry didn't cause an error: %+v", invalid)
+		}
+	}
+}
+
+func TestReadManifestCheckpoints(t *testing.T) {
+	entry := ManifestEntry{Text: "Hello", Destination: "a.mp3"}
+	failed := ManifestCheckpointOf(entry, nil, errors.New("fake error"))
+	done := ManifestCheckpointOf(entry, &T2SResult{Provider: providers.ProviderAWS, Destination: "a.mp3"}, nil)
+	var lines []string
+	for _, checkpoint := range []ManifestCheckpoint{failed, done} {
+		line, _ := json.Marshal(checkpoint)
+		lines = append(lines, string(line))
+	}
+	// the job was interrupted while writing the last line
+	lines = append(lines, `{"key":"b.mp3","do`)
+
+	checkpoints, err := ReadManifestCheckpoints(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("ReadManifestCheckpoints returned error: %s", err.Error())
+	}
+	if (len(checkpoints) != 1) || !checkpoints["a.mp3"].Done || (checkpoints["a.mp3"].Provider != providers.ProviderAWS) {
+		t.Errorf("Wrong checkpoints: %+v", checkpoints)
+	}
+
+	lines[0], lines[2] = lines[2], lines[0]
+	if _, err = ReadManifestCheckpoints(strings.NewReader(strings.Join(lines, "\n"))); err == nil {
+		t.Errorf("Invalid line in the middle of the checkpoint file didn't cause an error")
+	}
+}
@@ -3,7 +3,11 @@
 package shared
 
 import (
+	"errors"
+	"fmt"
 	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"strings"
+	"time"
 )
 
 type VoiceGender int16
@@ -39,6 +43,22 @@ func (voiceGender VoiceGender) String() string {
 	}
 }
 
+// MarshalText encodes the gender as its name (see String), e.g. in JSON voice catalog snapshots.
+func (voiceGender VoiceGender) MarshalText() ([]byte, error) {
+	return []byte(voiceGender.String()), nil
+}
+
+// UnmarshalText Reverse of MarshalText. The comparison is case-insensitive.
+func (voiceGender *VoiceGender) UnmarshalText(text []byte) error {
+	for _, gender := range []VoiceGender{VoiceGenderUnspecified, VoiceGenderMale, VoiceGenderFemale, VoiceGenderNeutral} {
+		if strings.EqualFold(gender.String(), string(text)) {
+			*voiceGender = gender
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("unknown voice gender '%s'", string(text)))
+}
+
 // VoiceIdConfig Defines the voice ID and Engine that should be used for speech synthesis.
 // A voice ID indirectly specifies the gender and language of a voice.
 // For example, the voice ID "Joanna" is a female en-US voice for AWS.
@@ -84,16 +104,20 @@ func (t TextType) String() string {
 // When VoiceIdConfig is undefined or empty (see VoiceIdConfig.IsEmpty function), then a voice based on the
 // VoiceParamsConfig is selected.
 // VoiceParamsConfig specifies the language, gender and engine of the voice that should be selected. The T2S function automatically
-// chooses the first voice id with the specified language, gender and engine parameters.
+// chooses the best voice id with the specified language, gender and engine parameters.
 //
 // If VoiceParamsConfig is undefined as well, the default value from GetDefaultVoiceParamsConfig is used.
 // If one of the properties of VoiceParamsConfig is undefined (empty string for LanguageCode and VoiceGenderUnspecified
 // for Gender), the corresponding value from GetDefaultVoiceParamsConfig is used.
 // If the Engine parameter is undefined (empty string), engine will be ignored for choosing voice.
+//
+// If multiple voices match the VoiceParamsConfig, the voice is chosen based on VoicePreferences (see RankVoices).
+// By default, no engines are preferred. Use GetQualityVoicePreferences to prefer neural voices.
 type VoiceConfig struct {
 	_                 struct{}
 	VoiceIdConfig     VoiceIdConfig
 	VoiceParamsConfig VoiceParamsConfig
+	VoicePreferences  VoicePreferences
 }
 
 // AudioFormat See which output formats are available on each provider in the respective documentation:
@@ -159,6 +183,73 @@ func AudioFormatToFileExtension(audioFormat AudioFormat) string {
 	}
 }
 
+// SubtitleFormat The file format of the subtitles that are generated alongside the audio file.
+type SubtitleFormat string
+
+const (
+	// SubtitleFormatNone No subtitles are generated.
+	SubtitleFormatNone SubtitleFormat = ""
+	// SubtitleFormatSrt SubRip subtitles (.srt)
+	SubtitleFormatSrt SubtitleFormat = "srt"
+	// SubtitleFormatVtt WebVTT subtitles (.vtt)
+	SubtitleFormatVtt SubtitleFormat = "vtt"
+)
+
+func SubtitleFormatToFileExtension(subtitleFormat SubtitleFormat) string {
+	switch subtitleFormat {
+	case SubtitleFormatSrt:
+		return ".srt"
+	case SubtitleFormatVtt:
+		return ".vtt"
+	case SubtitleFormatNone:
+		fallthrough
+	default:
+		return ""
+	}
+}
+
+// SubtitleOptions Defines if and how subtitles are generated from the speech marks of the synthesized audio.
+// If one of the numeric properties is 0, the corresponding value from GetDefaultSubtitleOptions is used.
+type SubtitleOptions struct {
+	// Format If SubtitleFormatNone, no subtitles are generated.
+	Format SubtitleFormat
+	// MaxLineLength The maximum number of characters per line. Words that are longer than this are not broken up.
+	MaxLineLength int
+	// MaxLinesPerCue The maximum number of lines that are displayed at the same time.
+	MaxLinesPerCue int
+	// MaxCueDuration The maximum time a single cue is displayed.
+	MaxCueDuration time.Duration
+}
+
+// GetDefaultSubtitleOptions The default value for SubtitleOptions. Subtitles are disabled by default.
+func GetDefaultSubtitleOptions() SubtitleOptions {
+	return SubtitleOptions{
+		Format:         SubtitleFormatNone,
+		MaxLineLength:  42,
+		MaxLinesPerCue: 2,
+		MaxCueDuration: 7 * time.Second,
+	}
+}
+
+// SSMLValidationMode Defines how SSML texts are checked against the SSML profile of the chosen provider
+// (see SSMLProfileProvider) before they are synthesized.
+type SSMLValidationMode string
+
+const (
+	// SSMLValidationOff SSML texts are sent to the provider unchecked. This is the default mode.
+	SSMLValidationOff SSMLValidationMode = ""
+	// SSMLValidationStrict SSML texts that contain elements or attributes that are not supported by the chosen provider
+	// are rejected with an *ssml.ValidationError before they are sent to the provider.
+	SSMLValidationStrict SSMLValidationMode = "strict"
+	// SSMLValidationTranslate Unsupported elements and attributes are rewritten or stripped (see ssml.Profile.Translate),
+	// so that the same SSML text can be synthesized on all providers.
+	SSMLValidationTranslate SSMLValidationMode = "translate"
+)
+
+// DefaultMaxChunkConcurrency The number of chunks that are synthesized at the same time if
+// TextToSpeechOptions.MaxChunkConcurrency is not set.
+const DefaultMaxChunkConcurrency = 4
+
 type TextToSpeechOptions struct {
 	_           struct{}
 	Provider    providers.Provider
@@ -193,6 +284,46 @@ type TextToSpeechOptions struct {
 	// AddFileExtension If true, the appropriate file extension for the chosen OutputFormat is automatically appended
 	// to the file name (only if that exact file extension is not already the suffix of the filename).
 	AddFileExtension bool
+	// SplitLongText If true, texts that are too long to be synthesized in a single request on the chosen provider are
+	// split into multiple chunks. Plain text is split at sentence boundaries, SSML text is split at sentence and element
+	// boundaries (each chunk is a valid SSML document, see SplitTextIntoChunks).
+	// The chunks are synthesized concurrently and the resulting audio is returned as one continuous audio stream.
+	// If SplitLongText is false, the whole text is sent in a single request, which fails if the text is too long.
+	SplitLongText bool
+	// MaxChunkLength The maximum length of a single chunk if SplitLongText is true. The length is measured the same
+	// way the chosen provider measures it (see TextLengthLimitProvider).
+	// If MaxChunkLength is 0 or exceeds the limit of the provider, the limit of the provider is used.
+	MaxChunkLength int
+	// MaxChunkConcurrency The maximum number of chunks that are synthesized at the same time if SplitLongText is true.
+	// If MaxChunkConcurrency is 0 or negative, DefaultMaxChunkConcurrency is used.
+	MaxChunkConcurrency int
+	// RawAudioOutput Only relevant for uncompressed audio formats (pcm, linear16, mulaw and alaw).
+	// If false, the audio samples are stored in a WAV container (i.e. a RIFF/WAVE header is added if the provider
+	// doesn't return one), so that the resulting .wav file can be played.
+	// If true, the raw audio samples are returned without a WAV header.
+	RawAudioOutput bool
+	// SpeechMarkTypes If not empty, speech marks of the given types are determined in addition to the audio.
+	// The speech marks are returned as part of the T2SResult (see GoT2SClient.T2SDirectWithResult).
+	// Depending on the provider, this might require an additional request (e.g. on AWS).
+	// Speech marks can't be combined with SplitLongText if the text actually needs to be split.
+	SpeechMarkTypes []SpeechMarkType
+	// Subtitles If Subtitles.Format is not SubtitleFormatNone, a subtitle file is generated from the word and sentence
+	// speech marks and stored next to the audio file (i.e. at the same destination with the file extension of the
+	// subtitle format). If SpeechMarkTypes contains neither word nor sentence speech marks, both are added.
+	Subtitles SubtitleOptions
+	// SSMLValidation Defines how SSML texts are checked against the SSML subset that the chosen provider supports.
+	// By default (SSMLValidationOff), SSML texts are sent unchecked. With SSMLValidationStrict, unsupported SSML is
+	// rejected before a request is sent to the provider.
+	SSMLValidation SSMLValidationMode
+	// Failover If true and the synthesis fails on the chosen provider with a retryable error (e.g. throttling, after
+	// all retries of the client's RetryPolicy) or the provider is unavailable, a voice is chosen on the remaining
+	// registered providers based on VoiceParamsConfig, until the synthesis succeeds on one of them.
+	// The provider that synthesized the audio is reported in T2SResult.Provider.
+	// Failover is not possible if a voice ID is specified (see VoiceConfig), because voice IDs are provider-specific.
+	Failover bool
+	// Tenant An optional identifier of the tenant the synthesis is requested for. It is included in the billing record
+	// (see T2SResult.Billing) and the cost metric, so that the spending can be tracked per tenant.
+	Tenant string
 }
 
 func GetDefaultTextToSpeechOptions() *TextToSpeechOptions {
@@ -201,15 +332,24 @@ func GetDefaultTextToSpeechOptions() *TextToSpeechOptions {
 		TextType: TextTypeAuto,
 		VoiceConfig: VoiceConfig{
 			VoiceParamsConfig: GetDefaultVoiceParamsConfig(),
+			VoicePreferences:  GetDefaultVoicePreferences(),
 		},
-		SpeakingRate:     1.0,
-		Pitch:            0,
-		Volume:           0,
-		AudioEffects:     nil,
-		SampleRate:       0,
-		OutputFormat:     AudioFormatUnspecified,
-		OutputFormatRaw:  nil,
-		AddFileExtension: true,
+		SpeakingRate:        1.0,
+		Pitch:               0,
+		Volume:              0,
+		AudioEffects:        nil,
+		SampleRate:          0,
+		OutputFormat:        AudioFormatUnspecified,
+		OutputFormatRaw:     nil,
+		AddFileExtension:    true,
+		SplitLongText:       false,
+		MaxChunkLength:      0,
+		MaxChunkConcurrency: DefaultMaxChunkConcurrency,
+		RawAudioOutput:      false,
+		SpeechMarkTypes:     nil,
+		Subtitles:           GetDefaultSubtitleOptions(),
+		SSMLValidation:      SSMLValidationOff,
+		Failover:            false,
 	}
 }
 
@@ -0,0 +1,101 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"sync"
+)
+
+// T2SProviderFactory creates a new T2SProvider instance. The returned instance doesn't need to have a service client yet,
+// because T2SProvider.CreateServiceClient is called before the instance is used.
+type T2SProviderFactory func() T2SProvider
+
+type providerRegistration struct {
+	provider providers.Provider
+	factory  T2SProviderFactory
+}
+
+var (
+	registryMutex sync.RWMutex
+	// registry contains all registered providers in the order in which they were registered.
+	registry []providerRegistration
+)
+
+// RegisterProvider registers a T2SProvider implementation under the given name.
+// Registered providers take part in the automatic provider selection of GoT2SClient the same way the built-in
+// providers do. Providers are considered in the order in which they were registered.
+// If a provider with the given name is already registered, the factory of the existing registration is replaced.
+func RegisterProvider(provider providers.Provider, factory T2SProviderFactory) error {
+	if provider == providers.ProviderUnspecified {
+		return errors.New("can't register provider: the provider name must not be empty")
+	}
+	if factory == nil {
+		return errors.New(fmt.Sprintf("can't register provider %s: the factory must not be nil", provider))
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	for i, registration := range registry {
+		if registration.provider == provider {
+			registry[i].factory = factory
+			return nil
+		}
+	}
+	registry = append(registry, providerRegistration{provider: provider, factory: factory})
+	return nil
+}
+
+// UnregisterProvider removes the provider with the given name from the registry.
+// Returns true if the provider was registered, false otherwise.
+func UnregisterProvider(provider providers.Provider) bool {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	for i, registration := range registry {
+		if registration.provider == provider {
+			registry = append(registry[:i], registry[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsProviderRegistered returns true if a provider with the given name is registered.
+func IsProviderRegistered(provider providers.Provider) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for _, registration := range registry {
+		if registration.provider == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRegisteredProviders returns the names of all registered providers in the order in which they were registered.
+func GetRegisteredProviders() []providers.Provider {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	registeredProviders := make([]providers.Provider, len(registry))
+	for i, registration := range registry {
+		registeredProviders[i] = registration.provider
+	}
+	return registeredProviders
+}
+
+// NewRegisteredProviderInstance creates a new instance of the provider with the given name using its registered factory.
+// If no provider with the given name is registered, an error is returned.
+func NewRegisteredProviderInstance(provider providers.Provider) (T2SProvider, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for _, registration := range registry {
+		if registration.provider == provider {
+			instance := registration.factory()
+			if instance == nil {
+				return nil, errors.Join(ErrProviderUnavailable, errors.New(fmt.Sprintf("the factory of provider %s returned nil", provider)))
+			}
+			return instance, nil
+		}
+	}
+	return nil, errors.Join(ErrProviderUnavailable, errors.New(fmt.Sprintf("the provider %s is not registered", provider)))
+}
@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"context"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"io"
+	"testing"
+)
+
+type testProvider struct {
+	name string
+}
+
+func (p testProvider) TransformOptions(text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error) {
+	return text, options, nil
+}
+
+func (p testProvider) FindVoice(ctx context.Context, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	return &VoiceIdConfig{VoiceId: p.name}, nil
+}
+
+func (p testProvider) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
+	return nil, nil
+}
+
+func (p testProvider) SelectVoice(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	return FindVoiceInList(voices, options)
+}
+
+func (p testProvider) CreateServiceClient(credentials CredentialsHolder, region string) (T2SProvider, error) {
+	return p, nil
+}
+
+func (p testProvider) ExecuteT2SDirect(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, error) {
+	return nil, nil
+}
+
+func (p testProvider) UploadFile(ctx context.Context, file io.Reader, destination string) error {
+	return nil
+}
+
+func (p testProvider) IsURLonOwnStorage(url string) bool {
+	return false
+}
+
+func (p testProvider) GetSupportedAudioFormats() []AudioFormat {
+	return []AudioFormat{AudioFormatMp3}
+}
+
+func (p testProvider) CloseServiceClient() error {
+	return nil
+}
+
+func (p testProvider) AddFileExtensionToDestinationIfNeeded(options TextToSpeechOptions, outputFormatRaw any, destination string) (string, error) {
+	return destination, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	name := providers.Provider("TEST_REGISTER")
+	defer UnregisterProvider(name)
+
+	err := RegisterProvider(name, func() T2SProvider { return testProvider{name: "first"} })
+	if err != nil {
+		t.Fatalf("RegisterProvider returned error: %s", err.Error())
+	}
+	if !IsProviderRegistered(name) {
+		t.Fatal("Provider was not registered")
+	}
+
+	instance, err := NewRegisteredProviderInstance(name)
+	if err != nil {
+		t.Fatalf("NewRegisteredProviderInstance returned error: %s", err.Error())
+	}
+	if instance.(testProvider).name != "first" {
+		t.Errorf("Wrong provider instance was created. Got: %s", instance.(testProvider).name)
+	}
+
+	// registering the same name again replaces the factory, but keeps the position
+	countBefore := len(GetRegisteredProviders())
+	_ = RegisterProvider(name, func() T2SProvider { return testProvider{name: "second"} })
+	if len(GetRegisteredProviders()) != countBefore {
+		t.Errorf("Provider was registered twice")
+	}
+	instance, _ = NewRegisteredProviderInstance(name)
+	if instance.(testProvider).name != "second" {
+		t.Errorf("Factory was not replaced. Got: %s", instance.(testProvider).name)
+	}
+}
+
+func TestRegisterProviderInvalid(t *testing.T) {
+	if RegisterProvider(providers.ProviderUnspecified, func() T2SProvider { return testProvider{} }) == nil {
+		t.Error("No error was returned for an empty provider name")
+	}
+	if RegisterProvider(providers.Provider("TEST_NIL"), nil) == nil {
+		t.Error("No error was returned for a nil factory")
+	}
+}
+
+func TestGetRegisteredProvidersOrder(t *testing.T) {
+	names := []providers.Provider{"TEST_ORDER_1", "TEST_ORDER_2", "TEST_ORDER_3"}
+	for _, name := range names {
+		_ = RegisterProvider(name, func() T2SProvider { return testProvider{} })
+		defer UnregisterProvider(name)
+	}
+
+	var registered []providers.Provider
+	for _, provider := range GetRegisteredProviders() {
+		for _, name := range names {
+			if provider == name {
+				registered = append(registered, provider)
+			}
+		}
+	}
+	if len(registered) != len(names) {
+		t.Fatalf("Expected %d registered providers, got %d", len(names), len(registered))
+	}
+	for i := range names {
+		if registered[i] != names[i] {
+			t.Errorf("Providers were not returned in registration order. Wanted: %v, Got: %v", names, registered)
+			break
+		}
+	}
+}
+
+func TestUnregisterProvider(t *testing.T) {
+	name := providers.Provider("TEST_UNREGISTER")
+	_ = RegisterProvider(name, func() T2SProvider { return testProvider{} })
+
+	if !UnregisterProvider(name) {
+		t.Error("UnregisterProvider returned false for a registered provider")
+	}
+	if IsProviderRegistered(name) {
+		t.Error("Provider was still registered after UnregisterProvider")
+	}
+	if _, err := NewRegisteredProviderInstance(name); err == nil {
+		t.Error("NewRegisteredProviderInstance didn't return an error for an unregistered provider")
+	}
+}
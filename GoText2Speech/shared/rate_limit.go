@@ -0,0 +1,241 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned if RateLimit.FailFast is set and a request can't be sent immediately,
+// because the rate limit or the concurrency limit of the provider is exhausted.
+var ErrRateLimitExceeded = errors.New("the rate limit or concurrency limit of the provider is exhausted")
+
+// RateLimit Defines how many requests may be sent to a provider (see GoT2SClient.SetRateLimit).
+type RateLimit struct {
+	// RequestsPerSecond The number of requests per second that may be sent on average (token bucket).
+	// If RequestsPerSecond is 0 or negative, the rate is not limited.
+	RequestsPerSecond float64
+	// Burst The number of requests that may be sent at once before the rate is limited, i.e. the size of the token
+	// bucket. If Burst is less than 1, a burst of 1 is used.
+	Burst int
+	// MaxConcurrency The maximum number of requests that may be in flight at the same time.
+	// If MaxConcurrency is 0 or negative, the concurrency is not limited.
+	MaxConcurrency int
+	// FailFast If true, requests that exceed the limits fail immediately with ErrRateLimitExceeded.
+	// Otherwise, requests wait until they may be sent (or the context is canceled).
+	FailFast bool
+}
+
+// RateLimiter limits the requests to a single provider according to a RateLimit.
+// A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	limit RateLimit
+	mutex sync.Mutex
+	// tokens The number of available tokens. Can be negative if tokens are reserved by waiting requests.
+	tokens     float64
+	lastRefill time.Time
+	// slots contains one element for each request in flight. nil if the concurrency is not limited.
+	slots chan struct{}
+	// slotsMutex is held while multiple slots are taken at once (see AcquireN), so that concurrent callers don't
+	// block each other with partially taken slots.
+	slotsMutex chan struct{}
+	// now returns the current time. Can be replaced in tests.
+	now func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with a full token bucket.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	limiter := &RateLimiter{
+		limit:      limit,
+		tokens:     float64(limit.Burst),
+		lastRefill: time.Now(),
+		now:        time.Now,
+	}
+	if limit.MaxConcurrency > 0 {
+		limiter.slots = make(chan struct{}, limit.MaxConcurrency)
+		limiter.slotsMutex = make(chan struct{}, 1)
+	}
+	return limiter
+}
+
+// Limit returns the RateLimit of the limiter.
+func (l *RateLimiter) Limit() RateLimit {
+	return l.limit
+}
+
+// Acquire waits until a request may be sent. The returned function must be called when the request has finished,
+// so that the next request may be sent (if the concurrency is limited).
+// If RateLimit.FailFast is set, ErrRateLimitExceeded is returned instead of waiting.
+// If the context is canceled while waiting, the error of the context is returned.
+func (l *RateLimiter) Acquire(ctx context.Context) (func(), error) {
+	return l.AcquireN(ctx, 1)
+}
+
+// AcquireN is the same as Acquire, but waits until n requests may be sent at once (e.g. if a synthesis sends a
+// separate request for the speech marks). The returned function releases all n requests.
+// n is limited to RateLimit.MaxConcurrency and RateLimit.Burst, so that the requests can be sent at all.
+func (l *RateLimiter) AcquireN(ctx context.Context, n int) (func(), error) {
+	if n < 1 {
+		n = 1
+	}
+	release := func() {}
+	if l.slots != nil {
+		slots, err := l.takeSlots(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { l.releaseSlots(slots) })
+		}
+	}
+
+	if err := l.waitForTokens(ctx, n); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// takeSlots takes n slots (at most MaxConcurrency) and returns the number of slots taken.
+func (l *RateLimiter) takeSlots(ctx context.Context, n int) (int, error) {
+	if n > cap(l.slots) {
+		n = cap(l.slots)
+	}
+	if l.limit.FailFast {
+		for i := 0; i < n; i++ {
+			select {
+			case l.slots <- struct{}{}:
+			default:
+				l.releaseSlots(i)
+				return 0, errors.Join(ErrRateLimitExceeded,
+					errors.New(fmt.Sprintf("%d requests are already in flight", l.limit.MaxConcurrency)))
+			}
+		}
+		return n, nil
+	}
+
+	if n > 1 {
+		select {
+		case l.slotsMutex <- struct{}{}:
+			defer func() { <-l.slotsMutex }()
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.releaseSlots(i)
+			return 0, ctx.Err()
+		}
+	}
+	return n, nil
+}
+
+// releaseSlots releases n slots that were taken with takeSlots.
+func (l *RateLimiter) releaseSlots(n int) {
+	for i := 0; i < n; i++ {
+		<-l.slots
+	}
+}
+
+// waitForTokens takes n tokens (at most Burst) from the bucket, waiting until they are available.
+func (l *RateLimiter) waitForTokens(ctx context.Context, n int) error {
+	if l.limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	tokens := math.Min(float64(n), float64(l.limit.Burst))
+
+	l.mutex.Lock()
+	now := l.now()
+	elapsed := now.Sub(l.lastRefill).Seconds()
+	l.tokens = math.Min(l.tokens+elapsed*l.limit.RequestsPerSecond, float64(l.limit.Burst))
+	l.lastRefill = now
+	if (l.tokens < tokens) && l.limit.FailFast {
+		l.mutex.Unlock()
+		return errors.Join(ErrRateLimitExceeded,
+			errors.New(fmt.Sprintf("more than %.2f requests per second", l.limit.RequestsPerSecond)))
+	}
+	// the tokens are reserved now, so that concurrent requests wait in the order they arrived
+	l.tokens -= tokens
+	wait := time.Duration(math.Max(-l.tokens, 0) / l.limit.RequestsPerSecond * float64(time.Second))
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the reserved tokens back
+		l.mutex.Lock()
+		l.tokens += tokens
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// ProviderRateLimiters holds the RateLimiter of each provider. Providers without a RateLimiter are not limited.
+// ProviderRateLimiters is safe for concurrent use.
+type ProviderRateLimiters struct {
+	mutex    sync.RWMutex
+	limiters map[providers.Provider]*RateLimiter
+}
+
+// NewProviderRateLimiters creates a ProviderRateLimiters without any limits.
+func NewProviderRateLimiters() *ProviderRateLimiters {
+	return &ProviderRateLimiters{limiters: make(map[providers.Provider]*RateLimiter)}
+}
+
+// Set replaces the limit of the given provider. Requests that are already waiting keep the old limit.
+func (r *ProviderRateLimiters) Set(provider providers.Provider, limit RateLimit) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.limiters[provider] = NewRateLimiter(limit)
+}
+
+// Remove removes the limit of the given provider.
+func (r *ProviderRateLimiters) Remove(provider providers.Provider) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.limiters, provider)
+}
+
+// Get returns the RateLimiter of the given provider, or nil if the provider is not limited.
+func (r *ProviderRateLimiters) Get(provider providers.Provider) *RateLimiter {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.limiters[provider]
+}
+
+// Acquire waits until a request may be sent to the given provider (see RateLimiter.Acquire).
+// If the provider is not limited, returns immediately.
+func (r *ProviderRateLimiters) Acquire(ctx context.Context, provider providers.Provider) (func(), error) {
+	return r.AcquireN(ctx, provider, 1)
+}
+
+// AcquireN waits until n requests may be sent to the given provider at once (see RateLimiter.AcquireN).
+// If the provider is not limited, returns immediately.
+func (r *ProviderRateLimiters) AcquireN(ctx context.Context, provider providers.Provider, n int) (func(), error) {
+	limiter := r.Get(provider)
+	if limiter == nil {
+		return func() {}, nil
+	}
+	release, err := limiter.AcquireN(ctx, n)
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("request to provider %s was not sent", provider)), err)
+	}
+	return release, nil
+}
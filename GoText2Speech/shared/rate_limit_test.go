@@ -0,0 +1,155 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterFailFastWhenTokensAreExhausted(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 2, Burst: 2, FailFast: true})
+	limiter.now = func() time.Time { return now }
+	limiter.lastRefill = now
+
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatalf("Request %d within burst was rejected: %s", i+1, err.Error())
+		}
+	}
+	if _, err := limiter.Acquire(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Request exceeding burst was not rejected: %v", err)
+	}
+
+	// after 0.5 seconds, one token is refilled
+	now = now.Add(500 * time.Millisecond)
+	if _, err := limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Request after refill was rejected: %s", err.Error())
+	}
+	if _, err := limiter.Acquire(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Request exceeding refilled tokens was not rejected: %v", err)
+	}
+}
+
+func TestRateLimiterWaitsForToken(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 50, Burst: 1})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire returned error: %s", err.Error())
+		}
+	}
+	// the first request is sent immediately, the other two wait 20ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Requests were not rate limited (took %s)", elapsed)
+	}
+}
+
+func TestRateLimiterWaitIsCanceled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 0.001, Burst: 1})
+	_, _ = limiter.Acquire(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Waiting request was not canceled: %v", err)
+	}
+}
+
+func TestRateLimiterLimitsConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{MaxConcurrency: 2})
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire returned error: %s", err.Error())
+				return
+			}
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 2 {
+		t.Errorf("%d requests were in flight at the same time, wanted at most 2", maxInFlight)
+	}
+}
+
+func TestRateLimiterFailFastWhenConcurrencyIsExhausted(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{MaxConcurrency: 1, FailFast: true})
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire returned error: %s", err.Error())
+	}
+	if _, err = limiter.Acquire(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Request exceeding concurrency was not rejected: %v", err)
+	}
+	release()
+	release() // releasing twice must not free another slot
+	if _, err = limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Request after release was rejected: %s", err.Error())
+	}
+	if _, err = limiter.Acquire(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Double release freed another slot: %v", err)
+	}
+}
+
+func TestRateLimiterAcquireN(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 0.001, Burst: 3, MaxConcurrency: 3, FailFast: true})
+	release, err := limiter.AcquireN(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("AcquireN returned error: %s", err.Error())
+	}
+	if _, err = limiter.AcquireN(context.Background(), 2); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Requests exceeding concurrency were not rejected: %v", err)
+	}
+	if _, err = limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Request within limits was rejected: %s", err.Error())
+	}
+	release()
+	if _, err = limiter.Acquire(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Request exceeding rate was not rejected: %v", err)
+	}
+
+	// n is limited to the concurrency, so that the requests can be sent at all
+	limiter = NewRateLimiter(RateLimit{MaxConcurrency: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err = limiter.AcquireN(ctx, 2); err != nil {
+		t.Errorf("AcquireN returned error for more requests than the concurrency: %s", err.Error())
+	}
+}
+
+func TestProviderRateLimitersOnlyLimitConfiguredProviders(t *testing.T) {
+	limiters := NewProviderRateLimiters()
+	limiters.Set(providers.ProviderAWS, RateLimit{MaxConcurrency: 1, FailFast: true})
+	for i := 0; i < 2; i++ {
+		if _, err := limiters.Acquire(context.Background(), providers.ProviderGCP); err != nil {
+			t.Errorf("Provider without limit was limited: %s", err.Error())
+		}
+	}
+	_, _ = limiters.Acquire(context.Background(), providers.ProviderAWS)
+	if _, err := limiters.Acquire(context.Background(), providers.ProviderAWS); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Limited provider was not limited: %v", err)
+	}
+	limiters.Remove(providers.ProviderAWS)
+	if _, err := limiters.Acquire(context.Background(), providers.ProviderAWS); err != nil {
+		t.Errorf("Limit was not removed: %s", err.Error())
+	}
+}
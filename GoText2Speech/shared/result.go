@@ -0,0 +1,26 @@
+package shared
+
+import "github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+
+// T2SResult contains information about a finished speech synthesis.
+type T2SResult struct {
+	// Provider The provider that synthesized the audio.
+	Provider providers.Provider
+	// VoiceIdConfig The voice that was used to synthesize the audio.
+	VoiceIdConfig VoiceIdConfig
+	// Destination The location the audio file was stored at. If TextToSpeechOptions.AddFileExtension is true,
+	// this includes the added file extension.
+	Destination string
+	// SpeechMarks The speech marks of the types specified in TextToSpeechOptions.SpeechMarkTypes, sorted by time.
+	// Empty if no speech mark types were specified.
+	SpeechMarks []SpeechMark
+	// SubtitleDestination The location the subtitle file was stored at.
+	// Empty if no subtitles were generated (see TextToSpeechOptions.Subtitles).
+	SubtitleDestination string
+	// FailedProviders The providers on which the synthesis failed before Provider synthesized the audio,
+	// in the order they were tried. Empty if the first chosen provider succeeded (see TextToSpeechOptions.Failover).
+	FailedProviders []providers.Provider
+	// Billing The characters that Provider billed for the synthesis and their estimated cost according to the price
+	// table of the client (see GoT2SClient.SetPriceTable). Characters billed by FailedProviders are not included.
+	Billing BillingRecord
+}
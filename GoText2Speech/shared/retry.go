@@ -0,0 +1,130 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetryPolicy Defines how often and after which delay failed requests to a provider (i.e. synthesis and uploads)
+// are retried. Only errors that the provider classifies as retryable (see RetryableErrorClassifier) are retried,
+// e.g. throttling errors. The delay before the n-th retry is InitialBackoff * Multiplier^(n-1), but at most
+// MaxBackoff. The delay is reduced by a random part of up to Jitter (see RetryPolicy.Backoff), so that concurrent
+// requests don't retry at the same time.
+//
+// The retries of the provider SDKs are disabled for the requests that are retried according to the policy (synthesis
+// requests, uploads to S3 and the requests of T2S jobs), so that their attempts are not multiplied. Other requests
+// (e.g. listing voices) and uploads to Cloud Storage still use the retries of the SDKs.
+type RetryPolicy struct {
+	// MaxAttempts The maximum number of attempts (including the first one). If MaxAttempts is 0 or 1, failed
+	// requests are not retried.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier If Multiplier is less than 1, the backoff is not increased.
+	Multiplier float64
+	// Jitter The fraction of the backoff that is randomized, between 0 (no jitter) and 1 (full jitter).
+	Jitter float64
+}
+
+// GetDefaultRetryPolicy The default RetryPolicy: up to 4 attempts with a backoff starting at 200ms.
+func GetDefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// NoRetryPolicy A RetryPolicy that doesn't retry failed requests.
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// RetryableErrors returns a function that tells Retry whether an error of the given provider is retryable: the
+// provider's IsRetryableError if it implements RetryableErrorClassifier, otherwise no error is retryable.
+func RetryableErrors(provider T2SProvider) func(err error) bool {
+	if classifier, ok := provider.(RetryableErrorClassifier); ok {
+		return classifier.IsRetryableError
+	}
+	return func(err error) bool {
+		return false
+	}
+}
+
+// retryRandom returns a random number in [0,1) for the jitter. Can be replaced in tests.
+var retryRandom = rand.Float64
+
+// Backoff returns the delay before the given retry (starting at 1 for the first retry, i.e. the second attempt).
+// random must be in [0,1) and determines the jitter: the delay is reduced by random * Jitter * delay.
+func (p RetryPolicy) Backoff(retry int, random float64) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if (p.MaxBackoff > 0) && (backoff > float64(p.MaxBackoff)) {
+		backoff = float64(p.MaxBackoff)
+	}
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	return time.Duration(backoff * (1 - jitter*random))
+}
+
+// Retry executes the given operation until it succeeds, returns an error that is not retryable according to the
+// given function, or the maximum number of attempts of the policy is reached. Errors caused by the cancellation of
+// the context are never retried. Returns the error of the last attempt.
+func Retry(ctx context.Context, policy RetryPolicy, isRetryable func(err error) bool, operation func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil {
+			return nil
+		}
+		if (attempt >= policy.MaxAttempts) || (ctx.Err() != nil) || !isRetryable(err) {
+			if attempt > 1 {
+				return errors.Join(errors.New(fmt.Sprintf("giving up after %d attempts", attempt)), err)
+			}
+			return err
+		}
+
+		backoff := policy.Backoff(attempt, retryRandom())
+		LoggerFromContext(ctx).Warn("Retrying failed request", "attempt", attempt, "maxAttempts", policy.MaxAttempts,
+			"backoff", backoff, LogKeyError, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// RetryWithReader is the same as Retry, but the given operation consumes the data of the given reader
+// (e.g. an upload). The data is read once, and each attempt gets a new reader that starts at the beginning of the data.
+func RetryWithReader(ctx context.Context, policy RetryPolicy, isRetryable func(err error) bool, data io.Reader, operation func(data io.Reader) error) error {
+	if policy.MaxAttempts <= 1 {
+		return operation(data)
+	}
+	buffer, err := io.ReadAll(data)
+	if err != nil {
+		return errors.Join(errors.New("error while reading data"), err)
+	}
+	return Retry(ctx, policy, isRetryable, func() error {
+		return operation(bytes.NewReader(buffer))
+	})
+}
+
+// IsTemporaryNetworkError returns true if the given error is a network timeout,
+// which providers usually treat as retryable.
+func IsTemporaryNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
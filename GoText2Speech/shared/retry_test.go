@@ -0,0 +1,136 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+var errRetryable = errors.New("throttled")
+
+func isTestErrorRetryable(err error) bool {
+	return errors.Is(err, errRetryable)
+}
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Multiplier: 2, Jitter: 1}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 3, Jitter: 0.5}
+	tests := []struct {
+		retry  int
+		random float64
+		want   time.Duration
+	}{
+		{1, 0, 100 * time.Millisecond},
+		{2, 0, 300 * time.Millisecond},
+		{3, 0, 900 * time.Millisecond},
+		{4, 0, time.Second},
+		{2, 0.5, 225 * time.Millisecond},
+		{4, 0.99, 505 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := policy.Backoff(test.retry, test.random); got != test.want {
+			t.Errorf("Wrong backoff for retry %d and random %f. Wanted: %s, Got: %s", test.retry, test.random, test.want, got)
+		}
+	}
+}
+
+func TestRetryRetriesRetryableErrors(t *testing.T) {
+	attempts := 0
+	err := Retry(context.Background(), testRetryPolicy(), isTestErrorRetryable, func() error {
+		attempts++
+		if attempts < 3 {
+			return errRetryable
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Retry returned error: %s", err.Error())
+	}
+	if attempts != 3 {
+		t.Errorf("Wrong number of attempts. Wanted: 3, Got: %d", attempts)
+	}
+}
+
+func TestRetryStopsAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	err := Retry(context.Background(), testRetryPolicy(), isTestErrorRetryable, func() error {
+		attempts++
+		return errRetryable
+	})
+	if !errors.Is(err, errRetryable) {
+		t.Errorf("Error of last attempt was not returned: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Wrong number of attempts. Wanted: 3, Got: %d", attempts)
+	}
+}
+
+func TestRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	permanentErr := errors.New("invalid voice")
+	attempts := 0
+	err := Retry(context.Background(), testRetryPolicy(), isTestErrorRetryable, func() error {
+		attempts++
+		return permanentErr
+	})
+	if (err != permanentErr) || (attempts != 1) {
+		t.Errorf("Permanent error was retried (%d attempts): %v", attempts, err)
+	}
+
+	attempts = 0
+	_ = Retry(context.Background(), NoRetryPolicy(), isTestErrorRetryable, func() error {
+		attempts++
+		return errRetryable
+	})
+	if attempts != 1 {
+		t.Errorf("Error was retried although retries are disabled (%d attempts)", attempts)
+	}
+}
+
+func TestRetryStopsWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := testRetryPolicy()
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	attempts := 0
+	err := Retry(ctx, policy, isTestErrorRetryable, func() error {
+		attempts++
+		cancel()
+		return errRetryable
+	})
+	if !errors.Is(err, errRetryable) || (attempts != 1) {
+		t.Errorf("Retry didn't stop after cancellation (%d attempts): %v", attempts, err)
+	}
+}
+
+func TestRetryWithReaderRecreatesReader(t *testing.T) {
+	var received []string
+	err := RetryWithReader(context.Background(), testRetryPolicy(), isTestErrorRetryable, bytes.NewBufferString("audio"), func(data io.Reader) error {
+		content, err := io.ReadAll(data)
+		if err != nil {
+			return err
+		}
+		received = append(received, string(content))
+		if len(received) < 2 {
+			return errRetryable
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RetryWithReader returned error: %s", err.Error())
+	}
+	if (len(received) != 2) || (received[0] != "audio") || (received[1] != "audio") {
+		t.Errorf("Data was not provided to every attempt: %v", received)
+	}
+}
+
+func TestRetryableErrorsWithoutClassifier(t *testing.T) {
+	if RetryableErrors(testProvider{})(errRetryable) {
+		t.Errorf("Error of a provider without RetryableErrorClassifier was classified as retryable")
+	}
+}
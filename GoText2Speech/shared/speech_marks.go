@@ -0,0 +1,207 @@
+// This file defines provider-independent speech mark types and helpers for providers that only report
+// timepoints of SSML <mark> tags.
+
+package shared
+
+import (
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type SpeechMarkType string
+
+const (
+	// SpeechMarkTypeWord marks the beginning of a word. Available on AWS and GCP.
+	SpeechMarkTypeWord SpeechMarkType = "word"
+	// SpeechMarkTypeSentence marks the beginning of a sentence. Available on AWS and GCP.
+	SpeechMarkTypeSentence SpeechMarkType = "sentence"
+	// SpeechMarkTypeSsml marks the position of an SSML <mark> tag. Available on AWS and GCP.
+	SpeechMarkTypeSsml SpeechMarkType = "ssml"
+	// SpeechMarkTypeViseme marks the position of a viseme (i.e. the position of the face and mouth). Available only on AWS.
+	SpeechMarkTypeViseme SpeechMarkType = "viseme"
+)
+
+// SpeechMark describes the point in time at which a word, sentence, SSML <mark> or viseme is spoken in the audio.
+type SpeechMark struct {
+	Type SpeechMarkType
+	// Time The offset from the beginning of the audio stream.
+	Time time.Duration
+	// Start The byte offset of the beginning of the object in the input text (not including viseme marks).
+	// The offset refers to the text that was sent to the provider, i.e. after TransformOptions was applied.
+	// -1 if the offset is unknown.
+	Start int
+	// End The byte offset of the end of the object in the input text (not including viseme marks). -1 if unknown.
+	End int
+	// Value For word and sentence marks, the word or sentence. For SSML marks, the name of the mark.
+	// For viseme marks, the viseme name.
+	Value string
+}
+
+// IncludesSpeechMarkType returns true if the given SpeechMarkType array contains the given SpeechMarkType, false otherwise.
+func IncludesSpeechMarkType(markTypes []SpeechMarkType, markType SpeechMarkType) bool {
+	for _, t := range markTypes {
+		if t == markType {
+			return true
+		}
+	}
+	return false
+}
+
+// SortSpeechMarks sorts the given speech marks by time. Speech marks with the same time keep their order.
+func SortSpeechMarks(marks []SpeechMark) {
+	sort.SliceStable(marks, func(i, j int) bool {
+		return marks[i].Time < marks[j].Time
+	})
+}
+
+// speechMarkTagPrefix is the prefix of the names of all <mark> tags that are added by AddSpeechMarkTags.
+const speechMarkTagPrefix = "gt2s_"
+
+// AddSpeechMarkTags is used for providers that only report the timepoints of SSML <mark> tags.
+// It inserts a <mark> tag in front of every word and/or sentence of the given text (depending on the given markTypes),
+// so that the timepoints of words and sentences can be determined.
+// Plain text is escaped and transformed into SSML. The content of elements that can't contain <mark> tags
+// (e.g. <say-as>, <sub> or <phoneme>) is not marked.
+// Returns the SSML text with the inserted <mark> tags and a map from the names of the inserted tags to the speech
+// mark they represent. Start and End of these speech marks refer to the given text, not the returned text.
+// The map can be used with ResolveSpeechMarkTimepoints once the timepoints are known.
+func AddSpeechMarkTags(text string, textType TextType, markTypes []SpeechMarkType) (string, map[string]SpeechMark) {
+	marker := speechMarkTagInserter{
+		marks:         make(map[string]SpeechMark),
+		markWords:     IncludesSpeechMarkType(markTypes, SpeechMarkTypeWord),
+		markSentences: IncludesSpeechMarkType(markTypes, SpeechMarkTypeSentence),
+	}
+
+	if textType != TextTypeSsml {
+		marker.builder.WriteString("<speak>")
+		marker.markText(text, 0, true)
+		marker.builder.WriteString("</speak>")
+		return marker.builder.String(), marker.marks
+	}
+
+	offset := 0
+	atomicDepth := 0
+	rootFound := false
+	for _, token := range ssml.Tokenize(text) {
+		switch token.Kind {
+		case ssml.TokenStartTag:
+			rootFound = true
+			if (atomicDepth > 0) || isAtomicSSMLElement(token.Name) {
+				atomicDepth++
+			}
+			marker.builder.WriteString(token.Raw)
+		case ssml.TokenEndTag:
+			if atomicDepth > 0 {
+				atomicDepth--
+			}
+			marker.builder.WriteString(token.Raw)
+		case ssml.TokenText:
+			if rootFound && (atomicDepth == 0) {
+				marker.markText(token.Raw, offset, false)
+			} else {
+				marker.builder.WriteString(token.Raw)
+			}
+		default:
+			marker.builder.WriteString(token.Raw)
+		}
+		offset += len(token.Raw)
+	}
+	return marker.builder.String(), marker.marks
+}
+
+type speechMarkTagInserter struct {
+	builder       strings.Builder
+	marks         map[string]SpeechMark
+	markWords     bool
+	markSentences bool
+}
+
+func (m *speechMarkTagInserter) addMark(markType SpeechMarkType, start int, value string) {
+	name := fmt.Sprintf("%s%s_%d", speechMarkTagPrefix, markType, len(m.marks))
+	m.marks[name] = SpeechMark{Type: markType, Start: start, End: start + len(value), Value: value}
+	m.builder.WriteString("<mark name=\"" + name + "\"/>")
+}
+
+// markText writes the given text with <mark> tags in front of every word and/or sentence into the builder.
+// offset is the position of the given text in the original text. If escape is true, the text is plain text
+// that needs to be escaped for SSML.
+func (m *speechMarkTagInserter) markText(text string, offset int, escape bool) {
+	write := func(s string) {
+		if escape {
+			s = EscapeTextForSSML(s)
+		}
+		m.builder.WriteString(s)
+	}
+
+	for _, sentence := range splitIntoSentences(text) {
+		trimmedSentence := strings.TrimSpace(sentence)
+		if m.markSentences && (trimmedSentence != "") {
+			leadingSpace := len(sentence) - len(strings.TrimLeftFunc(sentence, unicode.IsSpace))
+			write(sentence[:leadingSpace])
+			m.addMark(SpeechMarkTypeSentence, offset+leadingSpace, trimmedSentence)
+			sentence = sentence[leadingSpace:]
+			offset += leadingSpace
+		}
+
+		if !m.markWords {
+			write(sentence)
+			offset += len(sentence)
+			continue
+		}
+
+		wordStart := -1
+		for i, r := range sentence {
+			isSpace := unicode.IsSpace(r)
+			if !isSpace && (wordStart < 0) {
+				wordStart = i
+			} else if isSpace && (wordStart >= 0) {
+				m.addMark(SpeechMarkTypeWord, offset+wordStart, sentence[wordStart:i])
+				write(sentence[wordStart:i])
+				wordStart = -1
+			}
+			if isSpace {
+				write(string(r))
+			}
+		}
+		if wordStart >= 0 {
+			m.addMark(SpeechMarkTypeWord, offset+wordStart, sentence[wordStart:])
+			write(sentence[wordStart:])
+		}
+		offset += len(sentence)
+	}
+}
+
+// SSMLMarkTimepoint is the point in time at which an SSML <mark> tag is reached in the audio.
+type SSMLMarkTimepoint struct {
+	MarkName string
+	Time     time.Duration
+}
+
+// ResolveSpeechMarkTimepoints creates speech marks from the given timepoints.
+// Timepoints of marks that were inserted by AddSpeechMarkTags are turned into the word and sentence speech marks
+// they represent. Timepoints of all other marks are turned into SpeechMarkTypeSsml speech marks, if markTypes
+// contains SpeechMarkTypeSsml. The returned speech marks are sorted by time.
+func ResolveSpeechMarkTimepoints(timepoints []SSMLMarkTimepoint, insertedMarks map[string]SpeechMark, markTypes []SpeechMarkType) []SpeechMark {
+	includeSsmlMarks := IncludesSpeechMarkType(markTypes, SpeechMarkTypeSsml)
+	var marks []SpeechMark
+	for _, timepoint := range timepoints {
+		if mark, ok := insertedMarks[timepoint.MarkName]; ok {
+			mark.Time = timepoint.Time
+			marks = append(marks, mark)
+		} else if includeSsmlMarks && !strings.HasPrefix(timepoint.MarkName, speechMarkTagPrefix) {
+			marks = append(marks, SpeechMark{
+				Type:  SpeechMarkTypeSsml,
+				Time:  timepoint.Time,
+				Start: -1,
+				End:   -1,
+				Value: timepoint.MarkName,
+			})
+		}
+	}
+	SortSpeechMarks(marks)
+	return marks
+}
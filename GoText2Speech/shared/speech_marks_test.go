@@ -0,0 +1,77 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddSpeechMarkTagsPlainText(t *testing.T) {
+	input := "Hi & bye. Next one."
+	ssmlText, marks := AddSpeechMarkTags(input, TextTypeText, []SpeechMarkType{SpeechMarkTypeWord})
+
+	if !HasSpeakTag(ssmlText) {
+		t.Errorf("Plain text was not transformed into SSML. Got: %s", ssmlText)
+	}
+	if !strings.Contains(ssmlText, "&amp;") {
+		t.Errorf("Plain text was not escaped. Got: %s", ssmlText)
+	}
+	if len(marks) != 5 {
+		t.Fatalf("Expected 5 word marks, got %d", len(marks))
+	}
+	for _, mark := range marks {
+		if mark.Type != SpeechMarkTypeWord {
+			t.Errorf("Wrong speech mark type %s", mark.Type)
+		}
+		if input[mark.Start:mark.End] != mark.Value {
+			t.Errorf("Offsets of mark '%s' don't match the input text: '%s'", mark.Value, input[mark.Start:mark.End])
+		}
+	}
+}
+
+func TestAddSpeechMarkTagsSSML(t *testing.T) {
+	input := "<speak>First sentence. <say-as interpret-as=\"date\">2024-01-01</say-as> Second sentence.</speak>"
+	ssmlText, marks := AddSpeechMarkTags(input, TextTypeSsml, []SpeechMarkType{SpeechMarkTypeSentence})
+
+	if len(marks) != 2 {
+		t.Fatalf("Expected 2 sentence marks, got %d: %s", len(marks), ssmlText)
+	}
+	if strings.Contains(ssmlText, "<say-as interpret-as=\"date\"><mark") {
+		t.Errorf("Mark was inserted into <say-as> element: %s", ssmlText)
+	}
+	for _, mark := range marks {
+		if input[mark.Start:mark.End] != mark.Value {
+			t.Errorf("Offsets of mark '%s' don't match the input text: '%s'", mark.Value, input[mark.Start:mark.End])
+		}
+	}
+}
+
+func TestResolveSpeechMarkTimepoints(t *testing.T) {
+	_, insertedMarks := AddSpeechMarkTags("Hello World", TextTypeText, []SpeechMarkType{SpeechMarkTypeWord})
+
+	var timepoints []SSMLMarkTimepoint
+	i := 0
+	for name := range insertedMarks {
+		timepoints = append(timepoints, SSMLMarkTimepoint{MarkName: name, Time: time.Duration(i) * time.Second})
+		i++
+	}
+	timepoints = append(timepoints, SSMLMarkTimepoint{MarkName: "custom", Time: 500 * time.Millisecond})
+
+	marks := ResolveSpeechMarkTimepoints(timepoints, insertedMarks, []SpeechMarkType{SpeechMarkTypeWord})
+	if len(marks) != 2 {
+		t.Fatalf("Expected 2 speech marks, got %d", len(marks))
+	}
+
+	marks = ResolveSpeechMarkTimepoints(timepoints, insertedMarks, []SpeechMarkType{SpeechMarkTypeWord, SpeechMarkTypeSsml})
+	if len(marks) != 3 {
+		t.Fatalf("Expected 3 speech marks, got %d", len(marks))
+	}
+	for j := 1; j < len(marks); j++ {
+		if marks[j].Time < marks[j-1].Time {
+			t.Errorf("Speech marks are not sorted by time")
+		}
+	}
+	if marks[1].Type != SpeechMarkTypeSsml || marks[1].Value != "custom" {
+		t.Errorf("Custom mark was not resolved correctly: %+v", marks[1])
+	}
+}
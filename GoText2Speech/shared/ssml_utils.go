@@ -1,8 +1,9 @@
 package shared
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
 	"strconv"
 	"strings"
 )
@@ -15,13 +16,21 @@ func HasSpeakTag(text string) bool {
 }
 
 // GetOpeningTagOfSSMLText Returns the opening tag (i.e. opening of root node) from SSML text (i.e. <speak>).
+// Everything in front of the opening tag (e.g. an XML declaration) is included.
 // This function expects there to be an opening tag, i.e. <speak>.
 // If it cannot be expected that the given text always contains an opening tag, use HasSpeakTag to check for an opening tag.
 // Example 1: "<speak>...</speak>" -> "<speak>"
 // Example 2: "<speak attr1="test">...</speak>" -> "<speak attr1="test">"
 // Example 3: "Hello World" -> "Hello World"
 func GetOpeningTagOfSSMLText(text string) string {
-	return strings.SplitAfter(text, ">")[0]
+	end := 0
+	for _, token := range ssml.Tokenize(text) {
+		end += len(token.Raw)
+		if token.Kind == ssml.TokenStartTag {
+			return text[:end]
+		}
+	}
+	return text
 }
 
 func RemoveClosingSpeakTagOfSSMLText(text string) string {
@@ -30,50 +39,89 @@ func RemoveClosingSpeakTagOfSSMLText(text string) string {
 }
 
 // RemoveOpeningTagOfSSMLText Returns the given SSML text without the opening tag (i.e. opening of root node, <speak>).
-// Requires that an opening tag exists. Otherwise, will return unexpected value.
+// Requires that an opening tag exists. Otherwise, an empty string is returned.
 func RemoveOpeningTagOfSSMLText(text string) string {
-	return strings.SplitAfterN(text, ">", 2)[1]
+	return text[len(GetOpeningTagOfSSMLText(text)):]
 }
 
 func VolumeToSSMLAttribute(volume float64) string {
-	return fmt.Sprintf("volume=\"%.3fdB\"", volume)
+	return fmt.Sprintf("volume=\"%s\"", volumeToSSMLValue(volume))
 }
 
 func PitchToSSMLAttribute(pitch float64) string {
-	return fmt.Sprintf("pitch=\"%.3f%%\"", pitch*100.0)
+	return fmt.Sprintf("pitch=\"%s\"", factorToSSMLValue(pitch))
 }
 
 func RateToSSMLAttribute(rate float64) string {
-	return fmt.Sprintf("rate=\"%.3f%%\"", rate*100.0)
+	return fmt.Sprintf("rate=\"%s\"", factorToSSMLValue(rate))
+}
+
+func volumeToSSMLValue(volume float64) string {
+	return fmt.Sprintf("%.3fdB", volume)
+}
+
+func factorToSSMLValue(factor float64) string {
+	return fmt.Sprintf("%.3f%%", factor*100.0)
+}
+
+// CreateProsodyElement creates a <prosody> element with the volume, pitch and rate of the given options.
+func CreateProsodyElement(options TextToSpeechOptions) *ssml.Element {
+	return ssml.NewElement(string(ssml.ElementTypeProsody)).
+		SetAttribute("volume", volumeToSSMLValue(options.Volume)).
+		SetAttribute("pitch", factorToSSMLValue(options.Pitch)).
+		SetAttribute("rate", factorToSSMLValue(options.SpeakingRate))
 }
 
 func CreateProsodyTag(options TextToSpeechOptions) string {
-	return fmt.Sprintf("<prosody %s %s %s>", VolumeToSSMLAttribute(options.Volume), PitchToSSMLAttribute(options.Pitch), RateToSSMLAttribute(options.SpeakingRate))
+	return CreateProsodyElement(options).StartTag()
 }
 
 // TransformTextIntoSSML escapes special characters and wraps text into speak-tag with volume, pitch and rate parameters.
-// Example: Hello World -> <speak volume="0dB" rate="0%" pitch="0%">Hello World</speak>
+// Example: Hello World -> <speak><prosody volume="0.000dB" pitch="0.000%" rate="100.000%">Hello World</prosody></speak>
 func TransformTextIntoSSML(text string, options TextToSpeechOptions) string {
-	text = EscapeTextForSSML(text)
-	prosodyTag := CreateProsodyTag(options)
-	return "<speak>" + prosodyTag + text + "</prosody></speak>"
+	speak := ssml.NewElement(string(ssml.ElementTypeSpeak), CreateProsodyElement(options).AppendChild(ssml.NewText(text)))
+	return ssml.NewDocument(speak).String()
 }
 
 // EscapeTextForSSML In order to use text in SSML, it needs to be escaped.
 // These reserved characters are the same for GCP and AWS.
+// SSML texts that are created with ssml.New are escaped automatically.
 func EscapeTextForSSML(text string) string {
-	replacements := [][]string{
-		{"&", "&amp;"},
-		{"\"", "&quot;"},
-		{"'", "&apos;"},
-		{"<", "&lt;"},
-		{">", "&gt;"},
-	}
-	for _, replacement := range replacements {
-		text = strings.Replace(text, replacement[0], replacement[1], -1)
+	return ssml.EscapeText(text)
+}
+
+// SSMLProfileOf returns the SSML profile of the given provider if it implements SSMLProfileProvider.
+// Otherwise, an empty profile is returned, which doesn't restrict the SSML text.
+func SSMLProfileOf(provider T2SProvider) ssml.Profile {
+	if profileProvider, ok := provider.(SSMLProfileProvider); ok {
+		return profileProvider.GetSSMLProfile()
 	}
+	return ssml.Profile{}
+}
 
-	return text
+// ApplySSMLProfile checks the given SSML text against the given profile, depending on the given mode:
+// * SSMLValidationStrict: Returns an error if the text is not supported by the profile.
+// * SSMLValidationTranslate: Returns the text translated into the SSML subset of the profile (see ssml.Profile.Translate).
+// If the text is already supported by the profile, it is returned unchanged.
+// * SSMLValidationOff: Returns the text unchanged.
+func ApplySSMLProfile(text string, profile ssml.Profile, mode SSMLValidationMode) (string, error) {
+	if mode == SSMLValidationOff {
+		return text, nil
+	}
+	document, err := ssml.Parse(text)
+	if err != nil {
+		return text, errors.Join(ErrInvalidSSML, err)
+	}
+	if mode == SSMLValidationTranslate {
+		if violations := profile.Translate(document); len(violations) > 0 {
+			return document.String(), nil
+		}
+		return text, nil
+	}
+	if err = profile.Validate(document); err != nil {
+		return text, errors.Join(ErrInvalidSSML, err)
+	}
+	return text, nil
 }
 
 // IntegrateVolumeAttributeValueIntoTag integrates a value for the volume attribute into an existing <speak>-tag.
@@ -110,24 +158,20 @@ func IntegratePitchAttributeValueIntoTag(openingTag string, pitchValue float64)
 }
 
 // integrateAttributeValueIntoTag integrates a value for a given attribute into an existing <speak>-tag.
+// If the tag can't be parsed, it is returned unchanged.
 func integrateAttributeValueIntoTag(openingTag string, value float64, attributeName string, attributeUnit string) string {
-	regexWithUnit, _ := regexp.Compile(fmt.Sprintf("%s=\"(?P<%s>.*)%s\"", attributeName, attributeName, attributeUnit))
-	submatchesWithUnit := regexWithUnit.FindStringSubmatch(openingTag)
-	if submatchesWithUnit != nil {
-		predefinedValueStr := submatchesWithUnit[1]
-		predefinedValue, _ := strconv.ParseFloat(predefinedValueStr, 64)
-		value += predefinedValue
-		return regexWithUnit.ReplaceAllString(openingTag, fmt.Sprintf("%s=\"%f%s\"", attributeName, value, attributeUnit))
-	} else { // check if attribute exists without unit -> overwrite
-		regexWithoutUnit, _ := regexp.Compile(fmt.Sprintf("%s=\"(?P<%s>.*)\"", attributeName, attributeName))
-		submatchesWithoutUnit := regexWithoutUnit.FindStringSubmatch(openingTag)
-		if submatchesWithoutUnit != nil {
-			return regexWithoutUnit.ReplaceAllString(openingTag, fmt.Sprintf("%s=\"%f%s\"", attributeName, value, attributeUnit))
-		} else {
-			// attribute doesn't exist -> add it
-			openingTag = openingTag[:len(openingTag)-1] // remove last >
-			openingTag += fmt.Sprintf(" %s=\"%f%s\">", attributeName, value, attributeUnit)
-			return openingTag
+	element, err := ssml.ParseStartTag(openingTag)
+	if err != nil {
+		return openingTag
+	}
+	if predefinedValueStr, ok := element.Attribute(attributeName); ok {
+		// if the attribute exists with unit -> add values, otherwise overwrite
+		if numberStr, hasUnit := strings.CutSuffix(predefinedValueStr, attributeUnit); hasUnit {
+			if predefinedValue, parseErr := strconv.ParseFloat(numberStr, 64); parseErr == nil {
+				value += predefinedValue
+			}
 		}
 	}
+	element.SetAttribute(attributeName, fmt.Sprintf("%f%s", value, attributeUnit))
+	return element.StartTag()
 }
@@ -144,6 +144,7 @@ func TestIntegrateVolumeAttributeValueIntoTag(t *testing.T) {
 		{"Additional Attribute", "<speak rate=\"10%\">", fmt.Sprintf("<speak rate=\"10%%\" volume=\"%fdB\">", volumeValueDb)},
 		{"Integrate and add", "<speak volume=\"5dB\">", fmt.Sprintf("<speak volume=\"%fdB\">", volumeValueDb+5)},
 		{"Integrate and overwrite", "<speak volume=\"loud\">", fmt.Sprintf("<speak volume=\"%fdB\">", volumeValueDb)},
+		{"Attribute containing >", "<speak alias=\"a>b\" volume=\"5dB\">", fmt.Sprintf("<speak alias=\"a&gt;b\" volume=\"%fdB\">", volumeValueDb+5)},
 	}
 
 	for _, testcase := range inputs {
@@ -193,3 +194,14 @@ func TestIntegrateSpeakingRateAttributeValueIntoTag(t *testing.T) {
 		})
 	}
 }
+
+func TestApplySSMLProfileWithoutProfileProvider(t *testing.T) {
+	input := "<speak><unknown-element>Hello</unknown-element></speak>"
+	result, err := ApplySSMLProfile(input, SSMLProfileOf(testProvider{}), SSMLValidationStrict)
+	if err != nil {
+		t.Fatalf("ApplySSMLProfile returned error for provider without SSML profile: %s", err.Error())
+	}
+	if result != input {
+		t.Errorf("SSML text was changed.\nWanted:\t%s\nGot:\t%s", input, result)
+	}
+}
@@ -0,0 +1,290 @@
+package shared
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoStorage/gostorage"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SynthesisCache stores synthesized audio by the key of the synthesis (see SynthesisCacheKey), so that the same text
+// doesn't need to be synthesized again (see GoT2SClient.SetSynthesisCache).
+// Implementations must be safe for concurrent use.
+type SynthesisCache interface {
+	// Get returns the audio that is stored for the given key. Returns false if the cache doesn't contain the key.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Put stores the given audio for the given key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// synthesisCacheKeyVersion Is part of each key, so that the keys change if the audio of a synthesis would change
+// (e.g. because the options are transformed differently).
+const synthesisCacheKeyVersion = 1
+
+// synthesisCacheKeyInput Contains everything that determines the synthesized audio.
+type synthesisCacheKeyInput struct {
+	Version         int
+	Text            string
+	TextType        TextType
+	Provider        string
+	VoiceId         string
+	Engine          string
+	OutputFormat    AudioFormat
+	OutputFormatRaw string
+	SampleRate      int32
+	SpeakingRate    float64
+	Pitch           float64
+	Volume          float64
+	AudioEffects    []string
+	RawAudioOutput  bool
+}
+
+// SynthesisCacheKey returns the key of the synthesis of the given text with the given options, i.e. the hex-encoded
+// SHA-256 hash of the text, provider, voice ID, engine, output format, sample rate and prosody options.
+// The text and options must be the final ones that are sent to the provider (i.e. after T2SProvider.TransformOptions),
+// so that options that a provider expresses in the text (e.g. the speaking rate as SSML on AWS) are included.
+func SynthesisCacheKey(text string, options TextToSpeechOptions) string {
+	input := synthesisCacheKeyInput{
+		Version:         synthesisCacheKeyVersion,
+		Text:            text,
+		TextType:        options.TextType,
+		Provider:        string(options.Provider),
+		VoiceId:         options.VoiceConfig.VoiceIdConfig.VoiceId,
+		Engine:          options.VoiceConfig.VoiceIdConfig.Engine,
+		OutputFormat:    options.OutputFormat,
+		OutputFormatRaw: fmt.Sprintf("%T:%v", options.OutputFormatRaw, options.OutputFormatRaw),
+		SampleRate:      options.SampleRate,
+		SpeakingRate:    options.SpeakingRate,
+		Pitch:           options.Pitch,
+		Volume:          options.Volume,
+		AudioEffects:    options.AudioEffects,
+		RawAudioOutput:  options.RawAudioOutput,
+	}
+	// encoding a struct without maps can't fail and always results in the same JSON
+	encoded, _ := json.Marshal(input)
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
+
+// MemorySynthesisCache is a SynthesisCache that keeps the audio in memory. If the cache is full, the least recently
+// used audio is removed.
+type MemorySynthesisCache struct {
+	maxEntries int
+	maxBytes   int64
+
+	mutex sync.Mutex
+	size  int64
+	// order contains the keys, the most recently used key first
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// memorySynthesisCacheEntry is an element of MemorySynthesisCache.order.
+type memorySynthesisCacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewMemorySynthesisCache creates a MemorySynthesisCache that holds at most maxEntries audio files with at most
+// maxBytes in total. If maxEntries or maxBytes is 0 or negative, the respective size is not limited.
+// Audio that is larger than maxBytes is not cached.
+func NewMemorySynthesisCache(maxEntries int, maxBytes int64) *MemorySynthesisCache {
+	return &MemorySynthesisCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *MemorySynthesisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memorySynthesisCacheEntry).data, true, nil
+}
+
+func (c *MemorySynthesisCache) Put(ctx context.Context, key string, data []byte) error {
+	if (c.maxBytes > 0) && (int64(len(data)) > c.maxBytes) {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+	c.entries[key] = c.order.PushFront(&memorySynthesisCacheEntry{key: key, data: data})
+	c.size += int64(len(data))
+
+	for ((c.maxEntries > 0) && (c.order.Len() > c.maxEntries)) || ((c.maxBytes > 0) && (c.size > c.maxBytes)) {
+		c.removeElement(c.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of audio files in the cache.
+func (c *MemorySynthesisCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// removeElement removes the given element. The mutex must be locked.
+func (c *MemorySynthesisCache) removeElement(element *list.Element) {
+	entry := c.order.Remove(element).(*memorySynthesisCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.data))
+}
+
+// DirectorySynthesisCache is a SynthesisCache that stores the audio as files in a local directory
+// (one file per key, in subdirectories named after the first two characters of the key).
+// Files are never removed by the cache.
+type DirectorySynthesisCache struct {
+	directory string
+}
+
+// NewDirectorySynthesisCache creates a DirectorySynthesisCache in the given directory,
+// which is created if it doesn't exist yet.
+func NewDirectorySynthesisCache(directory string) (*DirectorySynthesisCache, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while creating cache directory %s", directory)), err)
+	}
+	return &DirectorySynthesisCache{directory: directory}, nil
+}
+
+// path returns the path of the file of the given key.
+func (c *DirectorySynthesisCache) path(key string) string {
+	if len(key) < 2 {
+		return filepath.Join(c.directory, key)
+	}
+	return filepath.Join(c.directory, key[:2], key)
+}
+
+func (c *DirectorySynthesisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Join(errors.New(fmt.Sprintf("error while reading cached audio %s", key)), err)
+	}
+	return data, true, nil
+}
+
+// Put writes the audio to a temporary file first, so that concurrent readers never see incomplete audio.
+func (c *DirectorySynthesisCache) Put(ctx context.Context, key string, data []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Join(errors.New("error while creating cache directory"), err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), key+".tmp")
+	if err != nil {
+		return errors.Join(errors.New("error while creating cache file"), err)
+	}
+	_, err = tmpFile.Write(data)
+	err = errors.Join(err, tmpFile.Close())
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Join(errors.New(fmt.Sprintf("error while writing cached audio %s", key)), err)
+	}
+	return nil
+}
+
+// StorageSynthesisCache is a SynthesisCache that stores the audio on S3 or Google Cloud Storage via GoStorage
+// (one object per key below a prefix). Objects are never removed by the cache.
+type StorageSynthesisCache struct {
+	prefix  string
+	storage *gostorage.GoStorage
+}
+
+// NewStorageSynthesisCache creates a StorageSynthesisCache that stores the audio below the given prefix, which is an
+// S3 or Cloud Storage URL (e.g. "s3://bucket/t2s-cache/" or "https://storage.cloud.google.com/bucket/t2s-cache/").
+func NewStorageSynthesisCache(credentials CredentialsHolder, prefix string) (*StorageSynthesisCache, error) {
+	if !IsAWSUrl(prefix) && !IsGoogleUrl(prefix) {
+		return nil, errors.New(fmt.Sprintf("the cache prefix %s is neither an S3 nor a Cloud Storage URL", prefix))
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &StorageSynthesisCache{prefix: prefix, storage: &gostorage.GoStorage{Credentials: credentials}}, nil
+}
+
+// Get GoStorage doesn't report why an object couldn't be opened, so objects that can't be opened (e.g. because they
+// don't exist) and empty objects are treated as missing. Errors while reading an opened object are returned.
+// GoStorage doesn't accept a context, so the context is only checked before the download.
+func (c *StorageSynthesisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	source, err := ParseUrlToGoStorageObject(c.prefix + key)
+	if err != nil {
+		return nil, false, err
+	}
+	reader := c.storage.DownloadFileAsReader(source)
+	if reader == nil {
+		return nil, false, nil
+	}
+	if closer, isCloser := reader.(io.Closer); isCloser {
+		defer closer.Close()
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, errors.Join(errors.New(fmt.Sprintf("error while reading cached audio %s", key)), err)
+	}
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+// Put uploads the audio via a temporary local file, because GoStorage only uploads files.
+// GoStorage doesn't report whether the upload succeeded, so only errors of the temporary file are returned.
+// GoStorage doesn't accept a context, so the context is only checked before the upload.
+func (c *StorageSynthesisCache) Put(ctx context.Context, key string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp("", "t2s-cache")
+	if err != nil {
+		return errors.Join(errors.New("error while creating file for temporarily storing cached audio"), err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if err = StoreAudioToLocalFile(bytes.NewReader(data), tmpFile); err != nil {
+		_ = tmpFile.Close()
+		return errors.Join(errors.New("error while writing to temporary file"), err)
+	}
+	if err = tmpFile.Close(); err != nil {
+		return errors.Join(errors.New("error while closing tmp file"), err)
+	}
+
+	target, err := ParseUrlToGoStorageObject(c.prefix + key)
+	if err != nil {
+		return err
+	}
+	c.storage.UploadFile(gostorage.GoStorageObject{
+		Bucket:        target.Bucket,
+		Key:           target.Key,
+		Region:        target.Region,
+		IsLocal:       true,
+		LocalFilePath: tmpFile.Name(),
+		ProviderType:  target.ProviderType,
+	})
+	return nil
+}
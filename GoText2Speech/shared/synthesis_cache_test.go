@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSynthesisCacheKey(t *testing.T) {
+	options := *GetDefaultTextToSpeechOptions()
+	options.Provider = "AWS"
+	options.VoiceConfig.VoiceIdConfig = VoiceIdConfig{VoiceId: "Joanna", Engine: "neural"}
+	options.OutputFormatRaw = "mp3"
+	key := SynthesisCacheKey("Hello", options)
+	if (len(key) != 64) || (key != SynthesisCacheKey("Hello", options)) {
+		t.Errorf("Key is not a stable SHA-256 hash: %s", key)
+	}
+
+	changes := []func(o *TextToSpeechOptions){
+		func(o *TextToSpeechOptions) { o.Provider = "GCP" },
+		func(o *TextToSpeechOptions) { o.VoiceConfig.VoiceIdConfig.VoiceId = "Matthew" },
+		func(o *TextToSpeechOptions) { o.VoiceConfig.VoiceIdConfig.Engine = "standard" },
+		func(o *TextToSpeechOptions) { o.OutputFormatRaw = "ogg_vorbis" },
+		func(o *TextToSpeechOptions) { o.SampleRate = 8000 },
+		func(o *TextToSpeechOptions) { o.Pitch = 0.1 },
+		func(o *TextToSpeechOptions) { o.AudioEffects = []string{"telephony-class-application"} },
+	}
+	for i, change := range changes {
+		changed := options
+		change(&changed)
+		if SynthesisCacheKey("Hello", changed) == key {
+			t.Errorf("Change %d didn't change the key", i)
+		}
+	}
+	if SynthesisCacheKey("Hello!", options) == key {
+		t.Errorf("Different text didn't change the key")
+	}
+}
+
+func TestMemorySynthesisCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemorySynthesisCache(2, 10)
+	_ = cache.Put(ctx, "a", []byte("aaa"))
+	_ = cache.Put(ctx, "b", []byte("bbb"))
+	_, _, _ = cache.Get(ctx, "a")
+	_ = cache.Put(ctx, "c", []byte("ccc"))
+	if _, found, _ := cache.Get(ctx, "b"); found {
+		t.Errorf("Least recently used entry was not removed")
+	}
+	if data, found, _ := cache.Get(ctx, "a"); !found || (string(data) != "aaa") {
+		t.Errorf("Recently used entry was removed")
+	}
+
+	// exceeds the byte limit together with the other entries
+	_ = cache.Put(ctx, "d", []byte("dddddddd"))
+	if cache.Len() != 1 {
+		t.Errorf("Cache has %d entries, wanted 1", cache.Len())
+	}
+	_ = cache.Put(ctx, "e", []byte("too large for the cache"))
+	if _, found, _ := cache.Get(ctx, "e"); found {
+		t.Errorf("Audio larger than the cache was cached")
+	}
+}
+
+func TestDirectorySynthesisCache(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewDirectorySynthesisCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirectorySynthesisCache returned error: %s", err.Error())
+	}
+	if _, found, err := cache.Get(ctx, "abcdef"); found || (err != nil) {
+		t.Errorf("Missing entry was found: %v", err)
+	}
+	if err = cache.Put(ctx, "abcdef", []byte("audio")); err != nil {
+		t.Fatalf("Put returned error: %s", err.Error())
+	}
+	if data, found, err := cache.Get(ctx, "abcdef"); !found || (err != nil) || (string(data) != "audio") {
+		t.Errorf("Stored entry was not found: %s, %v", string(data), err)
+	}
+}
+
+func TestStorageSynthesisCacheChecksContext(t *testing.T) {
+	cache, err := NewStorageSynthesisCache(CredentialsHolder{}, "s3://bucket/t2s-cache")
+	if err != nil {
+		t.Fatalf("NewStorageSynthesisCache returned error: %s", err.Error())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err = cache.Get(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get didn't return the error of the canceled context: %v", err)
+	}
+	if err = cache.Put(ctx, "key", []byte("audio")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Put didn't return the error of the canceled context: %v", err)
+	}
+}
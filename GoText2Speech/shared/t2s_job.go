@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"time"
+)
+
+// ErrT2SJobsNotSupported is returned if the provider doesn't support asynchronous synthesis jobs (see T2SJobProvider).
+var ErrT2SJobsNotSupported = errors.New("asynchronous synthesis jobs are not supported by the provider")
+
+// ErrT2SJobCancelNotSupported is returned by providers that can't cancel asynchronous synthesis jobs
+// (e.g. AWS Polly synthesis tasks run until they are finished).
+var ErrT2SJobCancelNotSupported = errors.New("asynchronous synthesis jobs can't be canceled on the provider")
+
+// ErrT2SJobFailed is returned if an asynchronous synthesis job failed or was canceled (see GoT2SClient.WaitForT2SJob).
+var ErrT2SJobFailed = errors.New("asynchronous synthesis job didn't succeed")
+
+// DefaultT2SJobPollInterval The interval in which the status of an asynchronous synthesis job is requested while
+// waiting for it, if no interval is specified.
+const DefaultT2SJobPollInterval = 5 * time.Second
+
+// T2SJobStatus The status of an asynchronous synthesis job.
+type T2SJobStatus int
+
+const (
+	// T2SJobStatusPending The job was accepted by the provider, but the synthesis hasn't started yet.
+	T2SJobStatusPending T2SJobStatus = iota
+	// T2SJobStatusRunning The text is being synthesized.
+	T2SJobStatusRunning
+	// T2SJobStatusSucceeded The audio file was written to the destination of the job.
+	T2SJobStatusSucceeded
+	// T2SJobStatusFailed The synthesis failed (see T2SJob.StatusReason).
+	T2SJobStatusFailed
+	// T2SJobStatusCanceled The job was canceled before it was finished.
+	T2SJobStatusCanceled
+)
+
+func (status T2SJobStatus) String() string {
+	switch status {
+	case T2SJobStatusPending:
+		return "Pending"
+	case T2SJobStatusRunning:
+		return "Running"
+	case T2SJobStatusSucceeded:
+		return "Succeeded"
+	case T2SJobStatusFailed:
+		return "Failed"
+	case T2SJobStatusCanceled:
+		return "Canceled"
+	default:
+		return ""
+	}
+}
+
+// IsFinished returns true if the job won't change its status anymore.
+func (status T2SJobStatus) IsFinished() bool {
+	return status >= T2SJobStatusSucceeded
+}
+
+// T2SJob is the handle of an asynchronous synthesis job (see GoT2SClient.StartT2SJob). The provider writes the audio
+// file directly to its own storage service, so that long texts can be synthesized without splitting them into
+// chunks and without passing the audio through the local memory.
+// A job handle only contains plain data, so it can be stored and used in another process to continue waiting.
+type T2SJob struct {
+	Provider providers.Provider
+	// Id The ID of the job on the provider (e.g. the task ID on AWS or the operation name on GCP).
+	Id string
+	// Destination The location the audio file is written to. Depending on the provider, the final location might
+	// only be known after the job was started (e.g. AWS appends the task ID to the file name).
+	Destination   string
+	VoiceIdConfig VoiceIdConfig
+	Status        T2SJobStatus
+	// StatusReason The reason of the status reported by the provider, e.g. the error message if the job failed.
+	StatusReason string
+	// Progress The progress of the job between 0 and 1. -1 if the provider doesn't report the progress.
+	Progress  float64
+	CreatedAt time.Time
+}
@@ -1,16 +1,23 @@
 package shared
 
-import "io"
+import (
+	"context"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"io"
+)
 
 type T2SProvider interface {
 	// TransformOptions Transforms the given options object such that it can be used for the chosen provider.
 	TransformOptions(text string, options TextToSpeechOptions) (string, TextToSpeechOptions, error)
 	// FindVoice finds a voice that is available on the provider based on the given parameters (language, gender and optionally engine).
-	FindVoice(options TextToSpeechOptions) (*VoiceIdConfig, error)
+	FindVoice(ctx context.Context, options TextToSpeechOptions) (*VoiceIdConfig, error)
 	// CreateServiceClient creates t2s client for the chosen provider and stores it in the struct.
 	CreateServiceClient(credentials CredentialsHolder, region string) (T2SProvider, error)
-	ExecuteT2SDirect(text string, destination string, options TextToSpeechOptions) (io.Reader, error)
-	UploadFile(file io.Reader, destination string) error
+	// ExecuteT2SDirect synthesizes the given text on the provider and returns the audio data.
+	// The given context is passed to all requests that are sent to the provider.
+	ExecuteT2SDirect(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, error)
+	// UploadFile uploads the given file to the provider's own storage service.
+	UploadFile(ctx context.Context, file io.Reader, destination string) error
 	// IsURLonOwnStorage checks if the given URL references a file that is hosted on the provider's own storage service
 	// (i.e. S3 on AWS or Cloud Storage on GCP).
 	IsURLonOwnStorage(url string) bool
@@ -20,3 +27,71 @@ type T2SProvider interface {
 	CloseServiceClient() error
 	AddFileExtensionToDestinationIfNeeded(options TextToSpeechOptions, outputFormatRaw any, destination string) (string, error)
 }
+
+// SpeechMarksProvider is implemented by providers that can return speech marks (see
+// TextToSpeechOptions.SpeechMarkTypes). For providers that don't implement it, requesting speech marks or subtitles
+// fails with ErrInvalidOptions.
+type SpeechMarksProvider interface {
+	// ExecuteT2SWithSpeechMarks synthesizes the given text like T2SProvider.ExecuteT2SDirect and additionally returns
+	// the speech marks of the types specified in TextToSpeechOptions.SpeechMarkTypes, sorted by time.
+	ExecuteT2SWithSpeechMarks(ctx context.Context, text string, destination string, options TextToSpeechOptions) (io.Reader, []SpeechMark, error)
+}
+
+// VoiceListProvider is implemented by providers that can list their voices. Only the voices of these providers are
+// cached (see VoiceCatalog) and listed by the client. For providers that don't implement it, T2SProvider.FindVoice is
+// used to find a voice.
+type VoiceListProvider interface {
+	// ListVoices lists all voices that are available on the provider and match the given filter.
+	ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error)
+	// SelectVoice chooses a voice for the given options from the given voices of the provider (see ListVoices)
+	// without sending any requests. Is used instead of T2SProvider.FindVoice if the voices are cached
+	// (see VoiceCatalog).
+	SelectVoice(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error)
+}
+
+// TextLengthLimitProvider is implemented by providers that limit the length of a text that can be synthesized in a
+// single request. Texts for providers that don't implement it are only split if TextToSpeechOptions.MaxChunkLength is
+// set (see TextLengthLimitOf).
+type TextLengthLimitProvider interface {
+	// GetTextLengthLimit returns the maximum length of a text that can be synthesized in a single request.
+	GetTextLengthLimit() TextLengthLimit
+}
+
+// SSMLProfileProvider is implemented by providers that describe the subset of SSML they support
+// (see TextToSpeechOptions.SSMLValidation). SSML texts for providers that don't implement it are not restricted.
+type SSMLProfileProvider interface {
+	// GetSSMLProfile returns the subset of SSML that is supported by the provider.
+	GetSSMLProfile() ssml.Profile
+}
+
+// BillingPolicyProvider is implemented by providers that describe how they bill syntheses (see BillingPolicy).
+// For providers that don't implement it, the zero BillingPolicy is used (see BillingPolicyOf).
+type BillingPolicyProvider interface {
+	// GetBillingPolicy returns how the provider bills the synthesis of a text with the given options, e.g. the pricing
+	// tier of the chosen voice.
+	GetBillingPolicy(options TextToSpeechOptions) BillingPolicy
+}
+
+// RetryableErrorClassifier is implemented by providers that can tell temporary errors apart from permanent ones.
+// Failed requests to providers that don't implement it are not retried (see RetryPolicy).
+type RetryableErrorClassifier interface {
+	// IsRetryableError returns true if the given error of a request to the provider (e.g.
+	// T2SProvider.ExecuteT2SDirect or T2SProvider.UploadFile) is temporary, e.g. caused by throttling, so that the
+	// request can be retried.
+	IsRetryableError(err error) bool
+}
+
+// T2SJobProvider is implemented by providers that support asynchronous synthesis jobs (see T2SJob).
+// For providers that don't implement it, the jobs functions of the client return ErrT2SJobsNotSupported.
+type T2SJobProvider interface {
+	// StartT2SJob starts an asynchronous synthesis of the given text that writes the audio file directly to the given
+	// destination, which must be on the provider's own storage service (see T2SProvider.IsURLonOwnStorage).
+	// The text may be up to GetT2SJobTextLengthLimit long.
+	StartT2SJob(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SJob, error)
+	// GetT2SJob requests the current status of the given job and returns the updated job.
+	GetT2SJob(ctx context.Context, job T2SJob) (*T2SJob, error)
+	// CancelT2SJob cancels the given job. Providers that can't cancel jobs return ErrT2SJobCancelNotSupported.
+	CancelT2SJob(ctx context.Context, job T2SJob) error
+	// GetT2SJobTextLengthLimit returns the maximum length of a text that can be synthesized by a single asynchronous job.
+	GetT2SJobTextLengthLimit() TextLengthLimit
+}
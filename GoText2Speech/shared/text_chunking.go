@@ -0,0 +1,380 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/ssml"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TextLengthUnit int
+
+const (
+	// TextLengthUnitCharacters the text length is measured in characters (i.e. unicode code points).
+	TextLengthUnitCharacters TextLengthUnit = iota
+	// TextLengthUnitBytes the text length is measured in bytes of the UTF-8 encoded text.
+	TextLengthUnitBytes
+)
+
+// TextLengthLimit describes the maximum length of a text that can be synthesized in a single request on a provider.
+type TextLengthLimit struct {
+	// MaxLength The maximum length of the text, measured in Unit. 0 means that there is no limit.
+	MaxLength int
+	Unit      TextLengthUnit
+	// IgnoreSSMLTags If true, SSML tags don't count towards MaxLength (e.g. AWS doesn't bill SSML tags).
+	IgnoreSSMLTags bool
+	// MaxTotalLength The maximum length of the text including SSML tags, measured in Unit.
+	// This is only relevant if IgnoreSSMLTags is true. 0 means that there is no additional limit.
+	MaxTotalLength int
+}
+
+// Measure returns the length of the given text as it counts towards MaxLength.
+func (limit TextLengthLimit) Measure(text string, textType TextType) int {
+	if (textType == TextTypeSsml) && limit.IgnoreSSMLTags {
+		text = removeSSMLTags(text)
+	}
+	return limit.measureRaw(text)
+}
+
+// Fits returns true if the given text doesn't exceed the limit.
+func (limit TextLengthLimit) Fits(text string, textType TextType) bool {
+	if (limit.MaxLength > 0) && (limit.Measure(text, textType) > limit.MaxLength) {
+		return false
+	}
+	if (limit.MaxTotalLength > 0) && (limit.measureRaw(text) > limit.MaxTotalLength) {
+		return false
+	}
+	return true
+}
+
+func (limit TextLengthLimit) measureRaw(text string) int {
+	if limit.Unit == TextLengthUnitBytes {
+		return len(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// WithMaxLength returns a copy of the limit with the given maximum length, if it is stricter than the current one.
+// If maxLength is 0 or larger than the current maximum length, the limit is returned unchanged.
+func (limit TextLengthLimit) WithMaxLength(maxLength int) TextLengthLimit {
+	if (maxLength > 0) && ((limit.MaxLength == 0) || (maxLength < limit.MaxLength)) {
+		limit.MaxLength = maxLength
+	}
+	return limit
+}
+
+// TextLengthLimitOf returns the text length limit of the given provider if it implements TextLengthLimitProvider,
+// otherwise the zero TextLengthLimit, i.e. no limit.
+func TextLengthLimitOf(provider T2SProvider) TextLengthLimit {
+	if limitProvider, ok := provider.(TextLengthLimitProvider); ok {
+		return limitProvider.GetTextLengthLimit()
+	}
+	return TextLengthLimit{}
+}
+
+// removeSSMLTags returns the given SSML text without any tags.
+func removeSSMLTags(text string) string {
+	var builder strings.Builder
+	for _, token := range ssml.Tokenize(text) {
+		if token.Kind == ssml.TokenText {
+			builder.WriteString(token.Raw)
+		}
+	}
+	return builder.String()
+}
+
+// SplitTextIntoChunks splits the given text into chunks such that each chunk fits into the given limit.
+// Plain text is split at sentence boundaries. If a single sentence is too long, it is split at word boundaries
+// and, if that is not possible, at character boundaries.
+// SSML text is split at sentence and element boundaries. Every chunk is a valid SSML document, i.e. the elements
+// that are open at the split position (like <speak> and <prosody>) are closed at the end of the chunk and re-opened
+// at the beginning of the next chunk. Elements whose content can't be split without changing its meaning
+// (e.g. <say-as>, <sub> or <phoneme>) are never split.
+// If the text already fits into the limit, a slice containing only the given text is returned.
+func SplitTextIntoChunks(text string, textType TextType, limit TextLengthLimit) ([]string, error) {
+	if limit.Fits(text, textType) {
+		return []string{text}, nil
+	}
+	if textType == TextTypeSsml {
+		return splitSSMLIntoChunks(text, limit)
+	}
+	return splitPlainTextIntoChunks(text, limit)
+}
+
+func splitPlainTextIntoChunks(text string, limit TextLengthLimit) ([]string, error) {
+	var chunks []string
+	current := ""
+	for _, sentence := range splitIntoSentences(text) {
+		if limit.Fits(current+sentence, TextTypeText) {
+			current += sentence
+			continue
+		}
+		if strings.TrimSpace(current) != "" {
+			chunks = append(chunks, current)
+		}
+		current = ""
+		if limit.Fits(sentence, TextTypeText) {
+			current = sentence
+			continue
+		}
+		// sentence is too long on its own -> split into words
+		for _, piece := range splitIntoFittingPieces(sentence, TextTypeText, func(s string) bool { return limit.Fits(s, TextTypeText) }) {
+			if limit.Fits(current+piece, TextTypeText) {
+				current += piece
+			} else {
+				if strings.TrimSpace(current) != "" {
+					chunks = append(chunks, current)
+				}
+				current = piece
+			}
+		}
+	}
+	if strings.TrimSpace(current) != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// splitIntoSentences splits the given text after sentence terminators that are followed by whitespace and after
+// line breaks. The returned sentences still contain the trailing whitespace, so joining them results in the original text.
+func splitIntoSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		isBoundary := false
+		if r == '\n' {
+			isBoundary = true
+		} else if strings.ContainsRune(".!?…。！？", r) {
+			// include closing quotes and brackets that directly follow the terminator
+			for (i+1 < len(runes)) && strings.ContainsRune("\"')]»“”’", runes[i+1]) {
+				i++
+			}
+			isBoundary = (i+1 >= len(runes)) || unicode.IsSpace(runes[i+1])
+		}
+		if isBoundary {
+			// include trailing whitespace
+			for (i+1 < len(runes)) && unicode.IsSpace(runes[i+1]) {
+				i++
+			}
+			sentences = append(sentences, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// splitIntoFittingPieces splits the given text into pieces for which fits returns true.
+// The text is split at word boundaries. Words that are too long on their own are split at character boundaries.
+// For SSML text, character entities (like &amp;) are never split.
+func splitIntoFittingPieces(text string, textType TextType, fits func(string) bool) []string {
+	var pieces []string
+	current := ""
+	for _, word := range strings.SplitAfter(text, " ") {
+		if fits(current + word) {
+			current += word
+			continue
+		}
+		if current != "" {
+			pieces = append(pieces, current)
+			current = ""
+		}
+		if fits(word) {
+			current = word
+			continue
+		}
+		// word is too long on its own -> split at character boundaries
+		for word != "" {
+			cut := len(word)
+			for (cut > 0) && !fits(word[:cut]) {
+				_, size := utf8.DecodeLastRuneInString(word[:cut])
+				cut -= size
+			}
+			if textType == TextTypeSsml {
+				cut = avoidSplittingEntity(word, cut)
+			}
+			if cut <= 0 { // not even a single character fits
+				cut = len(word)
+			}
+			pieces = append(pieces, word[:cut])
+			word = word[cut:]
+		}
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+// avoidSplittingEntity moves the given cut position in front of a character entity (e.g. &amp;),
+// if the cut position would otherwise split it.
+func avoidSplittingEntity(text string, cut int) int {
+	ampersand := strings.LastIndex(text[:cut], "&")
+	if (ampersand >= 0) && !strings.Contains(text[ampersand:cut], ";") && (ampersand > 0) {
+		return ampersand
+	}
+	return cut
+}
+
+// atomicSSMLElements are elements whose content is never split into multiple chunks.
+var atomicSSMLElements = []string{"say-as", "sub", "phoneme", "w", "amazon:breath", "audio", "media"}
+
+// ssmlUnit is a part of an SSML text that is never split any further.
+type ssmlUnit struct {
+	raw    string
+	isText bool
+	// opens is the element that is opened by this unit (only for start tags of non-atomic elements).
+	opens *ssml.Token
+	// closes is true if this unit closes the innermost open element.
+	closes bool
+}
+
+func splitSSMLIntoChunks(text string, limit TextLengthLimit) ([]string, error) {
+	units, prefix, err := createSSMLUnits(ssml.Tokenize(text))
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []string
+	var openElements []ssml.Token
+	current := prefix
+	currentHasContent := false
+
+	reopenTags := func() string {
+		var builder strings.Builder
+		builder.WriteString(prefix)
+		for _, element := range openElements {
+			builder.WriteString(element.Raw)
+		}
+		return builder.String()
+	}
+	closeTags := func(elements []ssml.Token) string {
+		var builder strings.Builder
+		for i := len(elements) - 1; i >= 0; i-- {
+			builder.WriteString("</" + elements[i].Name + ">")
+		}
+		return builder.String()
+	}
+	elementsAfter := func(unit ssmlUnit) []ssml.Token {
+		elements := openElements
+		if unit.opens != nil {
+			elements = append(append([]ssml.Token{}, openElements...), *unit.opens)
+		} else if unit.closes && (len(elements) > 0) {
+			elements = elements[:len(elements)-1]
+		}
+		return elements
+	}
+	fits := func(candidate string, elements []ssml.Token) bool {
+		return limit.Fits(candidate+closeTags(elements), TextTypeSsml)
+	}
+	flush := func() {
+		chunks = append(chunks, current+closeTags(openElements))
+		current = reopenTags()
+		currentHasContent = false
+	}
+	add := func(unit ssmlUnit) {
+		current += unit.raw
+		openElements = elementsAfter(unit)
+		if unit.isText && (strings.TrimSpace(unit.raw) != "") {
+			currentHasContent = true
+		}
+	}
+
+	for _, unit := range units {
+		elements := elementsAfter(unit)
+		if fits(current+unit.raw, elements) {
+			add(unit)
+			continue
+		}
+		if currentHasContent {
+			flush()
+			if fits(current+unit.raw, elementsAfter(unit)) {
+				add(unit)
+				continue
+			}
+		}
+		if !unit.isText {
+			return nil, errors.Join(ErrTextTooLong, errors.New(fmt.Sprintf("can't split SSML text into chunks: the element '%s' is too long", unit.raw)))
+		}
+		// text unit is too long on its own -> split into smaller pieces
+		pieces := splitIntoFittingPieces(unit.raw, TextTypeSsml, func(piece string) bool {
+			return fits(reopenTags()+piece, openElements)
+		})
+		for _, piece := range pieces {
+			if !fits(current+piece, openElements) && currentHasContent {
+				flush()
+			}
+			add(ssmlUnit{raw: piece, isText: true})
+		}
+	}
+	if currentHasContent {
+		chunks = append(chunks, current+closeTags(openElements))
+	}
+	return chunks, nil
+}
+
+// createSSMLUnits groups the given tokens into units that are never split.
+// Text tokens are split into sentences. Atomic elements (see atomicSSMLElements) are combined into a single unit.
+// Everything in front of the root element (e.g. an XML declaration) is returned as prefix.
+func createSSMLUnits(tokens []ssml.Token) ([]ssmlUnit, string, error) {
+	var units []ssmlUnit
+	prefix := ""
+	rootFound := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !rootFound && (token.Kind != ssml.TokenStartTag) {
+			prefix += token.Raw
+			continue
+		}
+		rootFound = true
+
+		switch token.Kind {
+		case ssml.TokenText:
+			for _, sentence := range splitIntoSentences(token.Raw) {
+				units = append(units, ssmlUnit{raw: sentence, isText: true})
+			}
+		case ssml.TokenStartTag:
+			if !isAtomicSSMLElement(token.Name) {
+				t := token
+				units = append(units, ssmlUnit{raw: token.Raw, opens: &t})
+				continue
+			}
+			// combine the whole atomic element into one unit
+			raw := token.Raw
+			depth := 1
+			for (depth > 0) && (i+1 < len(tokens)) {
+				i++
+				raw += tokens[i].Raw
+				if tokens[i].Kind == ssml.TokenStartTag {
+					depth++
+				} else if tokens[i].Kind == ssml.TokenEndTag {
+					depth--
+				}
+			}
+			if depth > 0 {
+				return nil, "", errors.Join(ErrInvalidSSML, errors.New(fmt.Sprintf("invalid SSML text: the element '%s' is never closed", token.Name)))
+			}
+			units = append(units, ssmlUnit{raw: raw})
+		case ssml.TokenEndTag:
+			units = append(units, ssmlUnit{raw: token.Raw, closes: true})
+		default:
+			units = append(units, ssmlUnit{raw: token.Raw})
+		}
+	}
+	return units, prefix, nil
+}
+
+func isAtomicSSMLElement(name string) bool {
+	for _, element := range atomicSSMLElements {
+		if strings.EqualFold(element, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTextIntoChunksFits(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 100}
+	input := "Hello World! Lovely day, isn't it?"
+	chunks, err := SplitTextIntoChunks(input, TextTypeText, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	if (len(chunks) != 1) || (chunks[0] != input) {
+		t.Errorf("Text that fits into the limit was changed. Got: %v", chunks)
+	}
+}
+
+func TestSplitTextIntoChunksPlainText(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 30}
+	input := "This is the first sentence. This is the second one! Is this the third? Yes."
+	chunks, err := SplitTextIntoChunks(input, TextTypeText, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+
+	want := []string{"This is the first sentence. ", "This is the second one! ", "Is this the third? Yes."}
+	if len(chunks) != len(want) {
+		t.Fatalf("Wrong number of chunks.\nWanted:\t%q\nGot:\t%q", want, chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("Wrong chunk %d.\nWanted:\t%q\nGot:\t%q", i, want[i], chunks[i])
+		}
+	}
+	if strings.Join(chunks, "") != input {
+		t.Errorf("Joined chunks don't result in the original text")
+	}
+}
+
+func TestSplitTextIntoChunksLongSentence(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 10}
+	input := "one two three four five six"
+	chunks, err := SplitTextIntoChunks(input, TextTypeText, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	for _, chunk := range chunks {
+		if !limit.Fits(chunk, TextTypeText) {
+			t.Errorf("Chunk '%s' exceeds the limit", chunk)
+		}
+	}
+	if strings.Join(chunks, "") != input {
+		t.Errorf("Joined chunks don't result in the original text. Got: %q", chunks)
+	}
+}
+
+func TestSplitTextIntoChunksBytes(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 8, Unit: TextLengthUnitBytes}
+	input := "äöüäöüäöü"
+	chunks, err := SplitTextIntoChunks(input, TextTypeText, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 8 {
+			t.Errorf("Chunk '%s' exceeds the limit of 8 bytes", chunk)
+		}
+	}
+	if strings.Join(chunks, "") != input {
+		t.Errorf("Joined chunks don't result in the original text. Got: %q", chunks)
+	}
+}
+
+func TestSplitTextIntoChunksSSML(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 30, IgnoreSSMLTags: true}
+	input := "<speak><prosody rate=\"90%\">This is the first sentence. This is the second one!</prosody></speak>"
+	chunks, err := SplitTextIntoChunks(input, TextTypeSsml, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+
+	want := []string{
+		"<speak><prosody rate=\"90%\">This is the first sentence. </prosody></speak>",
+		"<speak><prosody rate=\"90%\">This is the second one!</prosody></speak>",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("Wrong number of chunks.\nWanted:\t%q\nGot:\t%q", want, chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("Wrong chunk %d.\nWanted:\t%s\nGot:\t%s", i, want[i], chunks[i])
+		}
+	}
+}
+
+func TestSplitTextIntoChunksSSMLCountTags(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 60, Unit: TextLengthUnitBytes}
+	input := "<speak><p>First sentence here. Second sentence here.</p><p>Third sentence here.</p></speak>"
+	chunks, err := SplitTextIntoChunks(input, TextTypeSsml, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Text was not split. Got: %q", chunks)
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 60 {
+			t.Errorf("Chunk '%s' exceeds the limit of 60 bytes", chunk)
+		}
+		if !HasSpeakTag(chunk) {
+			t.Errorf("Chunk '%s' is not wrapped in <speak>-tags", chunk)
+		}
+		if strings.Count(chunk, "<p>") != strings.Count(chunk, "</p>") {
+			t.Errorf("Chunk '%s' contains unbalanced <p>-tags", chunk)
+		}
+	}
+}
+
+func TestSplitTextIntoChunksSSMLAtomicElement(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 25, IgnoreSSMLTags: true}
+	input := "<speak>Call me. <say-as interpret-as=\"telephone\">+1 555 123 4567</say-as> Thanks.</speak>"
+	chunks, err := SplitTextIntoChunks(input, TextTypeSsml, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	found := false
+	for _, chunk := range chunks {
+		if strings.Contains(chunk, "<say-as interpret-as=\"telephone\">+1 555 123 4567</say-as>") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("<say-as> element was split. Got: %q", chunks)
+	}
+}
+
+func TestSplitTextIntoChunksSSMLAttributeWithGreaterThan(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 20, IgnoreSSMLTags: true}
+	input := "<speak><sub alias=\"a > b\">a gt b</sub> First sentence. Second sentence.</speak>"
+	chunks, err := SplitTextIntoChunks(input, TextTypeSsml, limit)
+	if err != nil {
+		t.Fatalf("SplitTextIntoChunks returned error: %s", err.Error())
+	}
+	if !strings.HasPrefix(chunks[0], "<speak><sub alias=\"a > b\">a gt b</sub>") {
+		t.Errorf("Attribute value containing '>' was not handled correctly. Got: %q", chunks)
+	}
+}
+
+func TestTextLengthLimitMeasure(t *testing.T) {
+	limit := TextLengthLimit{MaxLength: 10, IgnoreSSMLTags: true}
+	if limit.Measure("<speak><break time=\"1s\"/>Hello</speak>", TextTypeSsml) != 5 {
+		t.Error("SSML tags were counted, even though IgnoreSSMLTags was true")
+	}
+	if limit.Measure("<speak>Hello</speak>", TextTypeText) != 20 {
+		t.Error("Plain text was not measured completely")
+	}
+
+	limit.IgnoreSSMLTags = false
+	if limit.Measure("<speak>Hello</speak>", TextTypeSsml) != 20 {
+		t.Error("SSML tags were not counted, even though IgnoreSSMLTags was false")
+	}
+}
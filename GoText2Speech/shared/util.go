@@ -30,26 +30,20 @@ func IsGoogleUrl(urlString string) bool {
 	return strings.HasPrefix(urlString, "gs://") || strings.Contains(urlString, "storage.cloud.google.com")
 }
 
-// from GoStorage
-func checkErr(err interface{}, msg interface{}) {
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", msg)
-		os.Exit(1)
-	}
-}
-
 // ParseUrlToGoStorageObject parses Object/Bucket URLs from AWS and Google to extract information such as bucketName, key, region etc.
+// Other strings are treated as local file paths. Returns an error wrapping ErrSourceUnavailable if the local file
+// doesn't exist.
 // Taken from GoStorage
-func ParseUrlToGoStorageObject(urlString string) gostorage.GoStorageObject {
+func ParseUrlToGoStorageObject(urlString string) (gostorage.GoStorageObject, error) {
 	if IsAWSUrl(urlString) {
-		return parseAWSUrl(urlString)
+		return parseAWSUrl(urlString), nil
 	} else if IsGoogleUrl(urlString) {
-		return parseGoogleUrl(urlString)
+		return parseGoogleUrl(urlString), nil
 	} else {
 		if _, err := os.Stat(urlString); errors.Is(err, os.ErrNotExist) {
-			checkErr(err, fmt.Sprintf("unable to find local file from {%v}, Error: %v", urlString, err))
+			return gostorage.GoStorageObject{}, errors.Join(ErrSourceUnavailable, errors.New(fmt.Sprintf("unable to find local file %s", urlString)), err)
 		}
-		return gostorage.GoStorageObject{IsLocal: true, LocalFilePath: urlString}
+		return gostorage.GoStorageObject{IsLocal: true, LocalFilePath: urlString}, nil
 	}
 }
 
@@ -103,13 +97,14 @@ func StoreAudioToLocalFile(audioData io.Reader, file *os.File) error {
 	var bytes = make([]byte, 1024)
 	for {
 		numBytes, err := audioData.Read(bytes)
-		_, writeErr := file.Write(bytes)
-		if (numBytes < 1) || (err != nil) { // done reading
-			break
+		if numBytes > 0 {
+			if _, writeErr := file.Write(bytes[:numBytes]); writeErr != nil {
+				_ = os.Remove(file.Name())
+				return writeErr
+			}
 		}
-		if writeErr != nil {
-			_ = os.Remove(file.Name())
-			return writeErr
+		if err != nil { // done reading
+			break
 		}
 	}
 	return nil
@@ -1,6 +1,10 @@
 package shared
 
-import "testing"
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
 
 func TestIncludesAudioFormat(t *testing.T) {
 
@@ -19,3 +23,16 @@ func TestIncludesAudioFormat(t *testing.T) {
 		t.Error("IncludesAudioFormat returned true, even though given audio format was not in audio formats array.")
 	}
 }
+
+func TestParseUrlToGoStorageObjectMissingLocalFile(t *testing.T) {
+	if _, err := ParseUrlToGoStorageObject(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, ErrSourceUnavailable) {
+		t.Errorf("Wrong error for missing local file: %v", err)
+	}
+	storageObj, err := ParseUrlToGoStorageObject("gs://bucket/folder/file.txt")
+	if err != nil {
+		t.Fatalf("ParseUrlToGoStorageObject returned error: %s", err.Error())
+	}
+	if (storageObj.Bucket != "bucket") || (storageObj.Key != "folder/file.txt") {
+		t.Errorf("Wrong bucket or key: %s, %s", storageObj.Bucket, storageObj.Key)
+	}
+}
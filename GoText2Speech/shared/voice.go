@@ -0,0 +1,126 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"strings"
+)
+
+// ErrVoiceListNotSupported is returned if the voices of a provider are listed, but the provider can't list its voices
+// (see VoiceListProvider).
+var ErrVoiceListNotSupported = errors.New("listing voices is not supported by the provider")
+
+// Voice describes a voice that is available on a provider.
+type Voice struct {
+	Provider providers.Provider `json:"provider"`
+	// Id The voice ID that can be used as VoiceIdConfig.VoiceId (e.g. "Joanna" on AWS or "en-US-Wavenet-A" on GCP).
+	Id string `json:"id"`
+	// Name The human-readable name of the voice. On GCP, this is the same as Id.
+	Name string `json:"name"`
+	// LanguageCodes The languages the voice can speak. The first language code is the primary language of the voice.
+	LanguageCodes []string    `json:"languageCodes"`
+	Gender        VoiceGender `json:"gender"`
+	// SupportedEngines On AWS, the engines that support the voice (e.g. "standard" or "neural").
+	// GCP doesn't have engines. Instead, the voice type is given (e.g. "Standard", "Wavenet" or "Neural2").
+	SupportedEngines []string `json:"supportedEngines,omitempty"`
+	// NaturalSampleRate The natural sample rate of the voice in Hz. 0 if the provider doesn't report it (e.g. AWS).
+	NaturalSampleRate int32 `json:"naturalSampleRate,omitempty"`
+}
+
+// VoiceIdConfig returns a VoiceIdConfig that selects this voice with the given engine.
+// If engine is empty, the default engine of the provider is used.
+func (v Voice) VoiceIdConfig(engine string) VoiceIdConfig {
+	return VoiceIdConfig{VoiceId: v.Id, Engine: engine}
+}
+
+// SupportsLanguage returns true if the voice can speak the given language.
+// The language code can either be a full language code (e.g. "en-US") or only a language (e.g. "en").
+// The comparison is case-insensitive.
+func (v Voice) SupportsLanguage(languageCode string) bool {
+	for _, code := range v.LanguageCodes {
+		if strings.EqualFold(code, languageCode) ||
+			(!strings.Contains(languageCode, "-") && strings.HasPrefix(strings.ToLower(code), strings.ToLower(languageCode)+"-")) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsEngine returns true if the voice supports the given engine (case-insensitive).
+func (v Voice) SupportsEngine(engine string) bool {
+	for _, e := range v.SupportedEngines {
+		if strings.EqualFold(e, engine) {
+			return true
+		}
+	}
+	return false
+}
+
+// VoiceFilter Defines which voices are returned by ListVoices. Empty properties don't restrict the voices.
+type VoiceFilter struct {
+	// Provider If specified, only voices of this provider are listed.
+	Provider providers.Provider
+	// LanguageCode Either a full language code (e.g. "en-US") or only a language (e.g. "en").
+	LanguageCode string
+	Gender       VoiceGender
+	Engine       string
+}
+
+// Matches returns true if the given voice matches all properties of the filter.
+func (f VoiceFilter) Matches(voice Voice) bool {
+	if (f.Provider != providers.ProviderUnspecified) && (f.Provider != voice.Provider) {
+		return false
+	}
+	if (f.LanguageCode != "") && !voice.SupportsLanguage(f.LanguageCode) {
+		return false
+	}
+	if (f.Gender != VoiceGenderUnspecified) && (f.Gender != voice.Gender) {
+		return false
+	}
+	if (f.Engine != "") && !voice.SupportsEngine(f.Engine) {
+		return false
+	}
+	return true
+}
+
+// FilterVoices returns all voices that match the given filter.
+func FilterVoices(voices []Voice, filter VoiceFilter) []Voice {
+	var filtered []Voice
+	for _, voice := range voices {
+		if filter.Matches(voice) {
+			filtered = append(filtered, voice)
+		}
+	}
+	return filtered
+}
+
+// VoiceFilterOf returns the VoiceFilter that matches the voice parameters of the given options.
+// If VoicePreferences.AllowLocaleFallback is set, the filter matches all locales of the language.
+func VoiceFilterOf(options TextToSpeechOptions) VoiceFilter {
+	filter := VoiceFilter{
+		Provider:     options.Provider,
+		LanguageCode: options.VoiceConfig.VoiceParamsConfig.LanguageCode,
+		Gender:       options.VoiceConfig.VoiceParamsConfig.Gender,
+		Engine:       options.VoiceConfig.VoiceParamsConfig.Engine,
+	}
+	if options.VoiceConfig.VoicePreferences.AllowLocaleFallback {
+		filter.LanguageCode = LanguageOfLanguageCode(filter.LanguageCode)
+	}
+	return filter
+}
+
+// FindVoiceInList returns the VoiceIdConfig of the best voice in the given list for the voice parameters and
+// preferences of the given options (see RankVoices). If VoiceParamsConfig.Engine is empty, the most preferred engine
+// that the voice supports is set as engine. If no voice matches, an error is returned.
+func FindVoiceInList(voices []Voice, options TextToSpeechOptions) (*VoiceIdConfig, error) {
+	params := options.VoiceConfig.VoiceParamsConfig
+	rankedVoices := RankVoices(voices, params, options.VoiceConfig.VoicePreferences)
+	if len(rankedVoices) == 0 {
+		return nil, errors.Join(ErrVoiceNotFound, errors.New(fmt.Sprintf("error: No voice found for language %s and gender %s\n",
+			params.LanguageCode, params.Gender.String())))
+	}
+
+	voiceConfig := rankedVoices[0].VoiceIdConfig()
+	return &voiceConfig, nil
+}
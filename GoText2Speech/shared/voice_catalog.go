@@ -0,0 +1,173 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultVoiceCatalogTTL is the TTL of the voice catalog that is created by default for every client.
+const DefaultVoiceCatalogTTL = 1 * time.Hour
+
+// voiceCatalogSnapshotVersion is the version of the JSON format of voice catalog snapshots.
+const voiceCatalogSnapshotVersion = 1
+
+// VoiceCatalog caches the voices of the providers, so that a voice can be chosen without sending a request
+// to the provider every time. The voices of a provider are loaded with VoiceListProvider.ListVoices the first time
+// they are needed and are reloaded after the TTL has expired.
+// The catalog can be saved to and loaded from a JSON snapshot (see Save and Load), e.g. to avoid requests
+// on cold starts or to choose voices in tests without any provider.
+// A VoiceCatalog is safe for concurrent use.
+type VoiceCatalog struct {
+	// ttl If ttl is 0 or negative, cached voices never expire.
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[providers.Provider]voiceCatalogEntry
+	// now returns the current time. Can be replaced in tests.
+	now func() time.Time
+}
+
+type voiceCatalogEntry struct {
+	voices   []Voice
+	loadedAt time.Time
+}
+
+// voiceCatalogSnapshot is the JSON format of a voice catalog snapshot.
+type voiceCatalogSnapshot struct {
+	Version   int                            `json:"version"`
+	CreatedAt time.Time                      `json:"createdAt"`
+	Voices    map[providers.Provider][]Voice `json:"voices"`
+}
+
+// NewVoiceCatalog creates an empty voice catalog with the given TTL.
+// If ttl is 0 or negative, cached voices never expire.
+func NewVoiceCatalog(ttl time.Duration) *VoiceCatalog {
+	return &VoiceCatalog{
+		ttl:     ttl,
+		entries: make(map[providers.Provider]voiceCatalogEntry),
+		now:     time.Now,
+	}
+}
+
+// TTL returns the time after which the cached voices of a provider are reloaded.
+func (c *VoiceCatalog) TTL() time.Duration {
+	return c.ttl
+}
+
+// Voices returns the voices of the given provider. If the voices are not cached or have expired, they are
+// loaded from the given provider instance. If loading fails, the error is returned and the cache is not changed.
+func (c *VoiceCatalog) Voices(ctx context.Context, provider providers.Provider, instance VoiceListProvider) ([]Voice, error) {
+	if voices, ok := c.CachedVoices(provider); ok {
+		return voices, nil
+	}
+
+	// the lock is not held while loading, so that the voices of multiple providers can be loaded concurrently
+	voices, err := instance.ListVoices(ctx, VoiceFilter{Provider: provider})
+	if err != nil {
+		return nil, err
+	}
+	c.SetVoices(provider, voices)
+	return voices, nil
+}
+
+// CachedVoices returns the cached voices of the given provider and true,
+// or nil and false if the voices are not cached or have expired.
+func (c *VoiceCatalog) CachedVoices(provider providers.Provider) ([]Voice, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[provider]
+	if !ok || c.isExpired(entry) {
+		return nil, false
+	}
+	return entry.voices, true
+}
+
+// SetVoices replaces the cached voices of the given provider. The TTL of the voices starts now.
+func (c *VoiceCatalog) SetVoices(provider providers.Provider, voices []Voice) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[provider] = voiceCatalogEntry{voices: voices, loadedAt: c.now()}
+}
+
+// Invalidate removes the cached voices of the given provider,
+// so that they are reloaded the next time they are needed.
+func (c *VoiceCatalog) Invalidate(provider providers.Provider) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, provider)
+}
+
+// Clear removes the cached voices of all providers.
+func (c *VoiceCatalog) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[providers.Provider]voiceCatalogEntry)
+}
+
+// Save writes the cached voices of all providers (including expired ones) as JSON snapshot to the given writer.
+func (c *VoiceCatalog) Save(w io.Writer) error {
+	c.mutex.Lock()
+	snapshot := voiceCatalogSnapshot{
+		Version:   voiceCatalogSnapshotVersion,
+		CreatedAt: c.now().UTC(),
+		Voices:    make(map[providers.Provider][]Voice, len(c.entries)),
+	}
+	for provider, entry := range c.entries {
+		snapshot.Voices[provider] = entry.voices
+	}
+	c.mutex.Unlock()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snapshot); err != nil {
+		return errors.Join(errors.New("error while saving voice catalog snapshot"), err)
+	}
+	return nil
+}
+
+// Load reads a JSON snapshot that was created with Save from the given reader (e.g. a file that is embedded into
+// the binary with go:embed) and adds its voices to the catalog. Cached voices of providers that are contained in the
+// snapshot are replaced. The TTL of the loaded voices starts when they are loaded, not when the snapshot was created.
+func (c *VoiceCatalog) Load(r io.Reader) error {
+	var snapshot voiceCatalogSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return errors.Join(errors.New("error while loading voice catalog snapshot"), err)
+	}
+	if snapshot.Version != voiceCatalogSnapshotVersion {
+		return errors.New(fmt.Sprintf("error while loading voice catalog snapshot: unsupported version %d", snapshot.Version))
+	}
+	for provider, voices := range snapshot.Voices {
+		c.SetVoices(provider, voices)
+	}
+	return nil
+}
+
+// SaveFile is the same as Save, but writes the snapshot into the file at the given path.
+func (c *VoiceCatalog) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Join(errors.New("error while creating voice catalog snapshot file "+path), err)
+	}
+	defer file.Close()
+	return c.Save(file)
+}
+
+// LoadFile is the same as Load, but reads the snapshot from the file at the given path.
+func (c *VoiceCatalog) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Join(errors.New("error while opening voice catalog snapshot file "+path), err)
+	}
+	defer file.Close()
+	return c.Load(file)
+}
+
+func (c *VoiceCatalog) isExpired(entry voiceCatalogEntry) bool {
+	return (c.ttl > 0) && !c.now().Before(entry.loadedAt.Add(c.ttl))
+}
@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingProvider is a provider that counts how often its voices are listed.
+type countingProvider struct {
+	testProvider
+	voices []Voice
+	calls  *int
+	err    error
+}
+
+func (p countingProvider) ListVoices(ctx context.Context, filter VoiceFilter) ([]Voice, error) {
+	*p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return FilterVoices(p.voices, filter), nil
+}
+
+func TestVoiceCatalogCachesUntilTTLExpires(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	catalog := NewVoiceCatalog(time.Minute)
+	catalog.now = func() time.Time { return now }
+	calls := 0
+	provider := countingProvider{voices: testVoices, calls: &calls}
+
+	for i := 0; i < 3; i++ {
+		voices, err := catalog.Voices(context.Background(), providers.ProviderAWS, provider)
+		if err != nil {
+			t.Fatalf("Voices returned error: %s", err.Error())
+		}
+		if len(voices) != 3 {
+			t.Errorf("Wrong number of AWS voices. Wanted: 3, Got: %d", len(voices))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Voices were listed %d times, wanted 1", calls)
+	}
+
+	now = now.Add(time.Minute)
+	_, _ = catalog.Voices(context.Background(), providers.ProviderAWS, provider)
+	if calls != 2 {
+		t.Errorf("Voices were not reloaded after TTL expired (listed %d times)", calls)
+	}
+
+	catalog.Invalidate(providers.ProviderAWS)
+	_, _ = catalog.Voices(context.Background(), providers.ProviderAWS, provider)
+	if calls != 3 {
+		t.Errorf("Voices were not reloaded after invalidation (listed %d times)", calls)
+	}
+}
+
+func TestVoiceCatalogDoesNotCacheErrors(t *testing.T) {
+	catalog := NewVoiceCatalog(0)
+	calls := 0
+	provider := countingProvider{calls: &calls, err: errors.New("unavailable")}
+	for i := 0; i < 2; i++ {
+		if _, err := catalog.Voices(context.Background(), providers.ProviderGCP, provider); err == nil {
+			t.Error("No error was returned")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Voices were listed %d times, wanted 2", calls)
+	}
+}
+
+func TestVoiceCatalogSnapshotRoundTrip(t *testing.T) {
+	catalog := NewVoiceCatalog(time.Hour)
+	catalog.SetVoices(providers.ProviderAWS, testVoices[:3])
+	catalog.SetVoices(providers.ProviderGCP, testVoices[3:])
+
+	var snapshot bytes.Buffer
+	if err := catalog.Save(&snapshot); err != nil {
+		t.Fatalf("Save returned error: %s", err.Error())
+	}
+	if !strings.Contains(snapshot.String(), "\"gender\": \"Female\"") {
+		t.Errorf("Gender was not saved by name:\n%s", snapshot.String())
+	}
+
+	loaded := NewVoiceCatalog(0)
+	if err := loaded.Load(&snapshot); err != nil {
+		t.Fatalf("Load returned error: %s", err.Error())
+	}
+	// voices of the snapshot are used without listing the voices of the provider
+	calls := 0
+	voices, err := loaded.Voices(context.Background(), providers.ProviderGCP, countingProvider{calls: &calls})
+	if err != nil {
+		t.Fatalf("Voices returned error: %s", err.Error())
+	}
+	if calls != 0 {
+		t.Errorf("Voices were listed although they were loaded from the snapshot")
+	}
+	if (len(voices) != 1) || (voices[0].Id != "de-DE-Wavenet-A") || (voices[0].Gender != VoiceGenderFemale) ||
+		(voices[0].NaturalSampleRate != 24000) || (voices[0].Provider != providers.ProviderGCP) {
+		t.Errorf("GCP voices were not loaded correctly: %+v", voices)
+	}
+	awsVoices, _ := loaded.CachedVoices(providers.ProviderAWS)
+	if (len(awsVoices) != 3) || !awsVoices[1].SupportsEngine("neural") {
+		t.Errorf("AWS voices were not loaded correctly: %+v", awsVoices)
+	}
+}
+
+func TestVoiceCatalogLoadInvalidSnapshot(t *testing.T) {
+	inputs := []string{
+		"not json",
+		"{\"version\": 2, \"voices\": {}}",
+		"{\"version\": 1, \"voices\": {\"AWS\": [{\"id\": \"Joanna\", \"gender\": \"Robot\"}]}}",
+	}
+	for _, input := range inputs {
+		if err := NewVoiceCatalog(0).Load(strings.NewReader(input)); err == nil {
+			t.Errorf("No error was returned for invalid snapshot '%s'", input)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VoicePreferences Defines which voices are preferred when a voice is chosen based on VoiceParamsConfig
+// (see RankVoices). Unlike VoiceParamsConfig, preferences don't exclude voices (except ExcludedVoices), but only
+// change the order in which the matching voices are considered.
+type VoicePreferences struct {
+	// PreferredEngines Engines (on AWS, e.g. "neural") or voice types (on GCP, e.g. "Wavenet") in the order of
+	// preference. The comparison is case-insensitive. Voices that support an engine that appears earlier are preferred.
+	// If VoiceParamsConfig.Engine is empty, the most preferred engine that the chosen voice supports is used.
+	PreferredEngines []string
+	// AllowLocaleFallback If true, voices of the same language but a different locale are considered as well
+	// (e.g. en-GB if the language code is en-US), but voices of the requested locale are always preferred.
+	AllowLocaleFallback bool
+	// PreferredFamily If not empty, voices whose ID contains the given text (case-insensitive) are preferred over
+	// voices of a more preferred engine, e.g. "Neural2" or "en-US-Wavenet".
+	PreferredFamily string
+	// ExcludedVoices The IDs of voices that must not be chosen (case-insensitive).
+	ExcludedVoices []string
+	// PreferredSampleRate If greater than 0, voices with at least this natural sample rate (in Hz) are preferred.
+	// Voices whose natural sample rate is unknown (e.g. on AWS) are not preferred.
+	PreferredSampleRate int32
+}
+
+// GetDefaultVoicePreferences The default value for VoicePreferences. No engines are preferred, so the first matching
+// voice of the provider is chosen with its default engine. Locale fallback is disabled.
+func GetDefaultVoicePreferences() VoicePreferences {
+	return VoicePreferences{}
+}
+
+// GetQualityVoicePreferences Prefers neural voices on AWS and Studio, Neural2 and WaveNet voices on GCP over standard
+// voices. Note that these voices are billed at a higher rate than standard voices (see GetDefaultPriceTable).
+func GetQualityVoicePreferences() VoicePreferences {
+	return VoicePreferences{
+		PreferredEngines: []string{"neural", "Studio", "Neural2", "Wavenet", "standard"},
+	}
+}
+
+// RankedVoice is a voice that matches the voice parameters together with its score (see RankVoices).
+type RankedVoice struct {
+	Voice Voice
+	// Score Voices with a higher score are preferred. Scores are only meaningful in relation to each other.
+	Score int
+	// Engine The engine that should be used with the voice, i.e. VoiceParamsConfig.Engine if it is specified,
+	// otherwise the most preferred engine that the voice supports. Empty if the default engine should be used.
+	Engine string
+	// Reasons Human-readable explanations of the score, e.g. "speaks en-US as primary language".
+	Reasons []string
+}
+
+// VoiceIdConfig returns the VoiceIdConfig that selects the ranked voice with its engine.
+func (v RankedVoice) VoiceIdConfig() VoiceIdConfig {
+	return v.Voice.VoiceIdConfig(v.Engine)
+}
+
+// The weights of the ranking criteria. Each criterion outweighs all criteria with a lower weight combined.
+const (
+	rankWeightLocale     = 100000
+	rankWeightFamily     = 10000
+	rankWeightEngine     = 100
+	rankWeightSampleRate = 10
+)
+
+// RankVoices returns the voices that match the given parameters, ordered by preference (best voice first).
+// Voices that don't have the requested gender or engine or are excluded are left out, as well as voices that don't
+// speak the requested language (or locale, if AllowLocaleFallback is false).
+// The remaining voices are ranked by the following criteria (in descending importance):
+// * language: primary language matches, additional language matches, primary language matches only via
+// locale fallback, additional language matches only via locale fallback
+// * PreferredFamily
+// * PreferredEngines
+// * PreferredSampleRate
+// Voices with the same score keep their order.
+func RankVoices(voices []Voice, params VoiceParamsConfig, preferences VoicePreferences) []RankedVoice {
+	ranked := make([]RankedVoice, 0, len(voices))
+	for _, voice := range voices {
+		if rankedVoice, ok := rankVoice(voice, params, preferences); ok {
+			ranked = append(ranked, rankedVoice)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+// rankVoice calculates the score of the given voice. Returns false if the voice doesn't match the parameters.
+func rankVoice(voice Voice, params VoiceParamsConfig, preferences VoicePreferences) (RankedVoice, bool) {
+	ranked := RankedVoice{Voice: voice, Engine: params.Engine}
+	for _, excluded := range preferences.ExcludedVoices {
+		if strings.EqualFold(excluded, voice.Id) {
+			return ranked, false
+		}
+	}
+	if (params.Gender != VoiceGenderUnspecified) && (params.Gender != voice.Gender) {
+		return ranked, false
+	}
+	if (params.Engine != "") && !voice.SupportsEngine(params.Engine) {
+		return ranked, false
+	}
+
+	localeScore, localeReason := rankLocale(voice, params.LanguageCode, preferences.AllowLocaleFallback)
+	if localeScore == 0 {
+		return ranked, false
+	}
+	ranked.Score += localeScore * rankWeightLocale
+	ranked.Reasons = append(ranked.Reasons, localeReason)
+
+	if (preferences.PreferredFamily != "") &&
+		strings.Contains(strings.ToLower(voice.Id), strings.ToLower(preferences.PreferredFamily)) {
+		ranked.Score += rankWeightFamily
+		ranked.Reasons = append(ranked.Reasons, fmt.Sprintf("belongs to preferred family %s", preferences.PreferredFamily))
+	}
+
+	for i, engine := range preferences.PreferredEngines {
+		if voice.SupportsEngine(engine) && ((params.Engine == "") || strings.EqualFold(params.Engine, engine)) {
+			ranked.Score += (len(preferences.PreferredEngines) - i) * rankWeightEngine
+			ranked.Reasons = append(ranked.Reasons, fmt.Sprintf("supports preferred engine %s (preference %d of %d)",
+				engine, i+1, len(preferences.PreferredEngines)))
+			if params.Engine == "" {
+				ranked.Engine = engine
+			}
+			break
+		}
+	}
+
+	if (preferences.PreferredSampleRate > 0) && (voice.NaturalSampleRate >= preferences.PreferredSampleRate) {
+		ranked.Score += rankWeightSampleRate
+		ranked.Reasons = append(ranked.Reasons, fmt.Sprintf("natural sample rate of %d Hz is at least %d Hz",
+			voice.NaturalSampleRate, preferences.PreferredSampleRate))
+	}
+	return ranked, true
+}
+
+// rankLocale returns how well the given voice matches the given language code (0 if it doesn't match at all)
+// and the reason for it.
+func rankLocale(voice Voice, languageCode string, allowFallback bool) (int, string) {
+	if languageCode == "" {
+		return 1, "no language requested"
+	}
+	if len(voice.LanguageCodes) == 0 {
+		return 0, ""
+	}
+	primary := Voice{LanguageCodes: voice.LanguageCodes[:1]}
+	if primary.SupportsLanguage(languageCode) {
+		return 4, fmt.Sprintf("speaks %s as primary language", languageCode)
+	}
+	if voice.SupportsLanguage(languageCode) {
+		return 3, fmt.Sprintf("speaks %s as additional language", languageCode)
+	}
+	if !allowFallback {
+		return 0, ""
+	}
+	language := LanguageOfLanguageCode(languageCode)
+	if primary.SupportsLanguage(language) {
+		return 2, fmt.Sprintf("locale fallback: speaks %s as primary language instead of %s",
+			voice.LanguageCodes[0], languageCode)
+	}
+	if voice.SupportsLanguage(language) {
+		return 1, fmt.Sprintf("locale fallback: speaks language %s as additional language instead of %s",
+			language, languageCode)
+	}
+	return 0, ""
+}
+
+// LanguageOfLanguageCode returns the language part of the given language code, e.g. "en" for "en-US".
+func LanguageOfLanguageCode(languageCode string) string {
+	language, _, _ := strings.Cut(languageCode, "-")
+	return language
+}
@@ -0,0 +1,101 @@
+package shared
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"strings"
+	"testing"
+)
+
+var rankingTestVoices = []Voice{
+	{Provider: providers.ProviderGCP, Id: "en-US-Standard-A", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderMale, SupportedEngines: []string{"Standard"}, NaturalSampleRate: 24000},
+	{Provider: providers.ProviderGCP, Id: "en-US-Wavenet-D", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderMale, SupportedEngines: []string{"Wavenet"}, NaturalSampleRate: 24000},
+	{Provider: providers.ProviderGCP, Id: "en-US-Neural2-J", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderMale, SupportedEngines: []string{"Neural2"}, NaturalSampleRate: 24000},
+	{Provider: providers.ProviderGCP, Id: "en-GB-Studio-B", LanguageCodes: []string{"en-GB"}, Gender: VoiceGenderMale, SupportedEngines: []string{"Studio"}, NaturalSampleRate: 24000},
+	{Provider: providers.ProviderAWS, Id: "Matthew", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderMale, SupportedEngines: []string{"standard", "neural"}},
+	{Provider: providers.ProviderAWS, Id: "Joanna", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderFemale, SupportedEngines: []string{"standard", "neural"}},
+}
+
+func rankedIds(ranked []RankedVoice) string {
+	ids := make([]string, len(ranked))
+	for i, voice := range ranked {
+		ids[i] = voice.Voice.Id
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestRankVoicesPrefersEngines(t *testing.T) {
+	params := VoiceParamsConfig{LanguageCode: "en-US", Gender: VoiceGenderMale}
+	ranked := RankVoices(rankingTestVoices, params, GetQualityVoicePreferences())
+	want := "Matthew,en-US-Neural2-J,en-US-Wavenet-D,en-US-Standard-A"
+	if got := rankedIds(ranked); got != want {
+		t.Fatalf("Voices were not ranked correctly.\nWanted:\t%s\nGot:\t%s", want, got)
+	}
+	if ranked[0].Engine != "neural" {
+		t.Errorf("Wrong engine for best voice. Wanted: neural, Got: %s", ranked[0].Engine)
+	}
+	if len(ranked[0].Reasons) != 2 {
+		t.Errorf("Wrong reasons for best voice: %v", ranked[0].Reasons)
+	}
+}
+
+func TestRankVoicesWithEngine(t *testing.T) {
+	params := VoiceParamsConfig{LanguageCode: "en-US", Gender: VoiceGenderMale, Engine: "standard"}
+	ranked := RankVoices(rankingTestVoices, params, GetQualityVoicePreferences())
+	// voices with the same score keep their order
+	want := "en-US-Standard-A,Matthew"
+	if got := rankedIds(ranked); got != want {
+		t.Fatalf("Voices were not ranked correctly.\nWanted:\t%s\nGot:\t%s", want, got)
+	}
+	if ranked[1].Engine != "standard" {
+		t.Errorf("Requested engine was not kept. Got: %s", ranked[1].Engine)
+	}
+}
+
+func TestRankVoicesLocaleFallback(t *testing.T) {
+	params := VoiceParamsConfig{LanguageCode: "en-AU", Gender: VoiceGenderMale}
+	preferences := GetQualityVoicePreferences()
+	if ranked := RankVoices(rankingTestVoices, params, preferences); len(ranked) != 0 {
+		t.Errorf("Voices were found without locale fallback: %s", rankedIds(ranked))
+	}
+
+	preferences.AllowLocaleFallback = true
+	ranked := RankVoices(rankingTestVoices, params, preferences)
+	if (len(ranked) != 5) || (ranked[0].Voice.Id != "Matthew") {
+		t.Errorf("Voices were not ranked correctly with locale fallback: %s", rankedIds(ranked))
+	}
+
+	// the requested locale is preferred over a better engine
+	params.LanguageCode = "en-US"
+	ranked = RankVoices(rankingTestVoices, params, VoicePreferences{PreferredEngines: []string{"Studio"}, AllowLocaleFallback: true})
+	if ranked[len(ranked)-1].Voice.Id != "en-GB-Studio-B" {
+		t.Errorf("Voice of other locale was preferred: %s", rankedIds(ranked))
+	}
+}
+
+func TestRankVoicesFamilyAndExclusion(t *testing.T) {
+	params := VoiceParamsConfig{LanguageCode: "en-US", Gender: VoiceGenderMale}
+	preferences := GetQualityVoicePreferences()
+	preferences.PreferredFamily = "wavenet"
+	preferences.ExcludedVoices = []string{"matthew"}
+	preferences.PreferredSampleRate = 24000
+	ranked := RankVoices(rankingTestVoices, params, preferences)
+	want := "en-US-Wavenet-D,en-US-Neural2-J,en-US-Standard-A"
+	if got := rankedIds(ranked); got != want {
+		t.Fatalf("Voices were not ranked correctly.\nWanted:\t%s\nGot:\t%s", want, got)
+	}
+	if !strings.Contains(strings.Join(ranked[0].Reasons, ";"), "preferred family wavenet") {
+		t.Errorf("Family was not given as reason: %v", ranked[0].Reasons)
+	}
+}
+
+func TestRankVoicesDefaultPreferencesKeepOrder(t *testing.T) {
+	params := VoiceParamsConfig{LanguageCode: "en-US", Gender: VoiceGenderMale}
+	ranked := RankVoices(rankingTestVoices, params, GetDefaultVoicePreferences())
+	want := "en-US-Standard-A,en-US-Wavenet-D,en-US-Neural2-J,Matthew"
+	if got := rankedIds(ranked); got != want {
+		t.Fatalf("Voices were not ranked correctly.\nWanted:\t%s\nGot:\t%s", want, got)
+	}
+	if ranked[3].Engine != "" {
+		t.Errorf("Engine was chosen without preferences: %s", ranked[3].Engine)
+	}
+}
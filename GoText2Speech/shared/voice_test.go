@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	"testing"
+)
+
+var testVoices = []Voice{
+	{Provider: providers.ProviderAWS, Id: "Aditi", LanguageCodes: []string{"en-IN", "hi-IN"}, Gender: VoiceGenderFemale, SupportedEngines: []string{"standard"}},
+	{Provider: providers.ProviderAWS, Id: "Joanna", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderFemale, SupportedEngines: []string{"standard", "neural"}},
+	{Provider: providers.ProviderAWS, Id: "Matthew", LanguageCodes: []string{"en-US"}, Gender: VoiceGenderMale, SupportedEngines: []string{"neural"}},
+	{Provider: providers.ProviderGCP, Id: "de-DE-Wavenet-A", LanguageCodes: []string{"de-DE"}, Gender: VoiceGenderFemale, SupportedEngines: []string{"Wavenet"}, NaturalSampleRate: 24000},
+}
+
+func TestSupportsLanguage(t *testing.T) {
+	voice := testVoices[0]
+	tests := map[string]bool{
+		"en-IN": true,
+		"hi-in": true,
+		"en":    true,
+		"hi":    true,
+		"en-US": false,
+		"e":     false,
+		"de":    false,
+	}
+	for languageCode, want := range tests {
+		if got := voice.SupportsLanguage(languageCode); got != want {
+			t.Errorf("SupportsLanguage(%s) returned %t, wanted %t", languageCode, got, want)
+		}
+	}
+}
+
+func TestFilterVoices(t *testing.T) {
+	tests := []struct {
+		filter VoiceFilter
+		want   []string
+	}{
+		{VoiceFilter{}, []string{"Aditi", "Joanna", "Matthew", "de-DE-Wavenet-A"}},
+		{VoiceFilter{Provider: providers.ProviderGCP}, []string{"de-DE-Wavenet-A"}},
+		{VoiceFilter{LanguageCode: "en"}, []string{"Aditi", "Joanna", "Matthew"}},
+		{VoiceFilter{LanguageCode: "en-US", Gender: VoiceGenderFemale}, []string{"Joanna"}},
+		{VoiceFilter{Engine: "NEURAL"}, []string{"Joanna", "Matthew"}},
+		{VoiceFilter{LanguageCode: "hi-IN"}, []string{"Aditi"}},
+		{VoiceFilter{LanguageCode: "fr-FR"}, []string{}},
+	}
+	for _, test := range tests {
+		voices := FilterVoices(testVoices, test.filter)
+		if len(voices) != len(test.want) {
+			t.Errorf("Wrong number of voices for filter %+v. Wanted: %d, Got: %d", test.filter, len(test.want), len(voices))
+			continue
+		}
+		for i, voice := range voices {
+			if voice.Id != test.want[i] {
+				t.Errorf("Wrong voice for filter %+v at index %d. Wanted: %s, Got: %s", test.filter, i, test.want[i], voice.Id)
+			}
+		}
+	}
+}
+
+func TestFindVoiceInListPrefersPrimaryLanguage(t *testing.T) {
+	voices := []Voice{
+		{Id: "Kajal", LanguageCodes: []string{"hi-IN", "en-IN"}, Gender: VoiceGenderFemale},
+		{Id: "Raveena", LanguageCodes: []string{"en-IN"}, Gender: VoiceGenderFemale},
+	}
+	options := GetDefaultTextToSpeechOptions()
+	options.VoiceConfig.VoiceParamsConfig = VoiceParamsConfig{LanguageCode: "en-IN", Gender: VoiceGenderFemale}
+	voiceIdConfig, err := FindVoiceInList(voices, *options)
+	if err != nil {
+		t.Fatalf("FindVoiceInList returned error: %s", err.Error())
+	}
+	if voiceIdConfig.VoiceId != "Raveena" {
+		t.Errorf("Wrong voice was chosen. Wanted: Raveena, Got: %s", voiceIdConfig.VoiceId)
+	}
+
+	options.VoiceConfig.VoiceParamsConfig.Gender = VoiceGenderMale
+	if _, err = FindVoiceInList(voices, *options); err == nil {
+		t.Error("No error was returned although no voice matches")
+	}
+}
@@ -0,0 +1,191 @@
+package ssml
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder creates SSML documents without string concatenation. All text is escaped automatically and the result is
+// always a well-formed <speak> document.
+// Elements that contain other elements take a function that adds their content, e.g.:
+//
+//	ssml.New().
+//		Sentence("Hi").
+//		Break(500 * time.Millisecond).
+//		SayAs("date", "2024-01-01").
+//		Prosody(ssml.ProsodyAttributes{Rate: "90%"}, func(b *ssml.Builder) {
+//			b.Text("Slowly & calmly")
+//		}).
+//		String()
+//
+// A Builder is not safe for concurrent use.
+type Builder struct {
+	root    *Element
+	current *Element
+}
+
+// ProsodyAttributes The attributes of a <prosody> element. Empty attributes are omitted.
+type ProsodyAttributes struct {
+	// Rate e.g. "slow", "90%"
+	Rate string
+	// Pitch e.g. "high", "+5%"
+	Pitch string
+	// Volume e.g. "loud", "+6dB"
+	Volume string
+}
+
+// New creates a Builder with an empty <speak> root element.
+func New() *Builder {
+	root := NewElement(string(ElementTypeSpeak))
+	return &Builder{root: root, current: root}
+}
+
+// Text adds the given text. The text is escaped.
+func (b *Builder) Text(text string) *Builder {
+	b.current.AppendChild(NewText(text))
+	return b
+}
+
+// Sentence adds the given text as sentence, i.e. <s>text</s>.
+func (b *Builder) Sentence(text string) *Builder {
+	return b.container(NewElement(string(ElementTypeSentence)), func(b *Builder) { b.Text(text) })
+}
+
+// Paragraph adds a paragraph (<p>) with the content that is added by the given function.
+func (b *Builder) Paragraph(content func(b *Builder)) *Builder {
+	return b.container(NewElement(string(ElementTypeParagraph)), content)
+}
+
+// Break adds a pause with the given duration, e.g. <break time="500ms"/>.
+func (b *Builder) Break(duration time.Duration) *Builder {
+	return b.empty(NewElement(string(ElementTypeBreak)).SetAttribute("time", formatBreakTime(duration)))
+}
+
+// BreakWithStrength adds a pause with the given strength (none, x-weak, weak, medium, strong or x-strong),
+// e.g. <break strength="strong"/>.
+func (b *Builder) BreakWithStrength(strength string) *Builder {
+	return b.empty(NewElement(string(ElementTypeBreak)).SetAttribute("strength", strength))
+}
+
+// SayAs adds the given text with instructions on how to interpret it,
+// e.g. <say-as interpret-as="date">2024-01-01</say-as>.
+// The supported values of interpretAs differ between the providers (see the SSML profiles of the providers).
+func (b *Builder) SayAs(interpretAs string, text string) *Builder {
+	element := NewElement(string(ElementTypeSayAs)).SetAttribute("interpret-as", interpretAs)
+	return b.container(element, func(b *Builder) { b.Text(text) })
+}
+
+// SayAsWithFormat is the same as SayAs, but additionally specifies the format, e.g. "ymd" for dates.
+func (b *Builder) SayAsWithFormat(interpretAs string, format string, text string) *Builder {
+	element := NewElement(string(ElementTypeSayAs)).SetAttribute("interpret-as", interpretAs).SetAttribute("format", format)
+	return b.container(element, func(b *Builder) { b.Text(text) })
+}
+
+// Sub adds the given text, which is pronounced as the given alias, e.g. <sub alias="World Wide Web">WWW</sub>.
+func (b *Builder) Sub(alias string, text string) *Builder {
+	element := NewElement(string(ElementTypeSub)).SetAttribute("alias", alias)
+	return b.container(element, func(b *Builder) { b.Text(text) })
+}
+
+// Phoneme adds the given text with the given phonetic pronunciation,
+// e.g. <phoneme alphabet="ipa" ph="pɪˈkɑːn">pecan</phoneme>.
+func (b *Builder) Phoneme(alphabet string, ph string, text string) *Builder {
+	element := NewElement(string(ElementTypePhoneme)).SetAttribute("alphabet", alphabet).SetAttribute("ph", ph)
+	return b.container(element, func(b *Builder) { b.Text(text) })
+}
+
+// Mark adds a mark with the given name, e.g. <mark name="chapter1"/>.
+// The time at which the mark is reached can be requested with speech marks of type ssml.
+func (b *Builder) Mark(name string) *Builder {
+	return b.empty(NewElement(string(ElementTypeMark)).SetAttribute("name", name))
+}
+
+// Audio adds an audio file. The given fallback text is spoken if the audio file can't be played.
+// Only supported by GCP. On AWS, only the fallback text is used (see SSMLValidationTranslate).
+func (b *Builder) Audio(src string, fallbackText string) *Builder {
+	element := NewElement(string(ElementTypeAudio)).SetAttribute("src", src)
+	if fallbackText == "" {
+		return b.empty(element)
+	}
+	return b.container(element, func(b *Builder) { b.Text(fallbackText) })
+}
+
+// Prosody adds the content that is added by the given function with the given rate, pitch and volume.
+func (b *Builder) Prosody(attributes ProsodyAttributes, content func(b *Builder)) *Builder {
+	element := NewElement(string(ElementTypeProsody))
+	if attributes.Volume != "" {
+		element.SetAttribute("volume", attributes.Volume)
+	}
+	if attributes.Pitch != "" {
+		element.SetAttribute("pitch", attributes.Pitch)
+	}
+	if attributes.Rate != "" {
+		element.SetAttribute("rate", attributes.Rate)
+	}
+	return b.container(element, content)
+}
+
+// Emphasis adds the content that is added by the given function with the given emphasis level
+// (strong, moderate or reduced).
+func (b *Builder) Emphasis(level string, content func(b *Builder)) *Builder {
+	return b.container(NewElement(string(ElementTypeEmphasis)).SetAttribute("level", level), content)
+}
+
+// Lang adds the content that is added by the given function in the given language, e.g. "de-DE".
+func (b *Builder) Lang(language string, content func(b *Builder)) *Builder {
+	return b.container(NewElement(string(ElementTypeLang)).SetAttribute("xml:lang", language), content)
+}
+
+// Voice adds the content that is added by the given function, spoken by the voice with the given name.
+// Only supported by GCP.
+func (b *Builder) Voice(name string, content func(b *Builder)) *Builder {
+	return b.container(NewElement(string(ElementTypeVoice)).SetAttribute("name", name), content)
+}
+
+// Element adds an arbitrary element, e.g. a vendor-specific element like <amazon:effect name="whispered">.
+// If content is nil, a self-closing element is added.
+func (b *Builder) Element(name string, attributes []Attribute, content func(b *Builder)) *Builder {
+	element := &Element{Name: name, Attributes: append([]Attribute{}, attributes...)}
+	if content == nil {
+		return b.empty(element)
+	}
+	return b.container(element, content)
+}
+
+// Document returns the SSML document that was built so far.
+// Further calls of the builder also modify the returned document.
+func (b *Builder) Document() *Document {
+	return NewDocument(b.root)
+}
+
+// String returns the SSML text that was built so far.
+func (b *Builder) String() string {
+	return b.Document().String()
+}
+
+// container adds the given element and makes it the target of the calls in the given content function.
+func (b *Builder) container(element *Element, content func(b *Builder)) *Builder {
+	b.current.AppendChild(element)
+	parent := b.current
+	b.current = element
+	if content != nil {
+		content(b)
+	}
+	b.current = parent
+	return b
+}
+
+// empty adds the given element as self-closing element.
+func (b *Builder) empty(element *Element) *Builder {
+	element.SelfClosing = true
+	b.current.AppendChild(element)
+	return b
+}
+
+// formatBreakTime formats the given duration in milliseconds, or in seconds if it is a whole number of seconds.
+func formatBreakTime(duration time.Duration) string {
+	if (duration%time.Second == 0) && (duration > 0) {
+		return fmt.Sprintf("%ds", duration/time.Second)
+	}
+	return fmt.Sprintf("%dms", duration.Milliseconds())
+}
@@ -0,0 +1,39 @@
+package ssml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	result := New().
+		Sentence("Hi & welcome").
+		Break(500*time.Millisecond).
+		SayAs("date", "2024-01-01").
+		Prosody(ProsodyAttributes{Rate: "90%", Volume: "+6dB"}, func(b *Builder) {
+			b.Text("Tom's <show>").Mark("m1")
+		}).
+		Paragraph(func(b *Builder) {
+			b.Lang("de-DE", func(b *Builder) { b.Text("Hallo") }).Break(2 * time.Second)
+		}).
+		Element("amazon:effect", []Attribute{{Name: "name", Value: "whispered"}}, func(b *Builder) { b.Text("psst") }).
+		String()
+
+	want := "<speak><s>Hi &amp; welcome</s><break time=\"500ms\"/><say-as interpret-as=\"date\">2024-01-01</say-as>" +
+		"<prosody volume=\"+6dB\" rate=\"90%\">Tom&apos;s &lt;show&gt;<mark name=\"m1\"/></prosody>" +
+		"<p><lang xml:lang=\"de-DE\">Hallo</lang><break time=\"2s\"/></p>" +
+		"<amazon:effect name=\"whispered\">psst</amazon:effect></speak>"
+	if result != want {
+		t.Errorf("Wrong SSML text was built.\nWanted:\t%s\nGot:\t%s", want, result)
+	}
+
+	if _, err := Parse(result); err != nil {
+		t.Errorf("Built SSML text can't be parsed: %s", err.Error())
+	}
+}
+
+func TestBuilderEmpty(t *testing.T) {
+	if result := New().String(); result != "<speak></speak>" {
+		t.Errorf("Wrong SSML text for empty builder. Got: %s", result)
+	}
+}
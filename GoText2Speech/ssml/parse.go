@@ -0,0 +1,137 @@
+package ssml
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Parse parses the given SSML text into a Document.
+// Returns an error if the text doesn't contain exactly one root element, if an element is never closed or if a
+// closing tag doesn't match the open element. The root element doesn't need to be a <speak> element.
+func Parse(text string) (*Document, error) {
+	document := &Document{}
+	var openElements []*Element
+
+	appendNode := func(node Node) {
+		if len(openElements) > 0 {
+			parent := openElements[len(openElements)-1]
+			parent.Children = append(parent.Children, node)
+		} else if document.Root == nil {
+			document.Prolog = append(document.Prolog, node)
+		} else {
+			document.Epilog = append(document.Epilog, node)
+		}
+	}
+	appendElement := func(element *Element) error {
+		if len(openElements) == 0 {
+			if document.Root != nil {
+				return errors.New(fmt.Sprintf("invalid SSML text: multiple root elements, found <%s> after </%s>",
+					element.Name, document.Root.Name))
+			}
+			document.Root = element
+			return nil
+		}
+		appendNode(element)
+		return nil
+	}
+
+	for _, token := range Tokenize(text) {
+		switch token.Kind {
+		case TokenText:
+			if (len(openElements) == 0) && (strings.TrimSpace(token.Raw) != "") {
+				return nil, errors.New(fmt.Sprintf("invalid SSML text: text '%s' outside of root element", strings.TrimSpace(token.Raw)))
+			}
+			appendNode(&Text{Raw: token.Raw})
+		case TokenOther:
+			appendNode(&Markup{Raw: token.Raw})
+		case TokenStartTag, TokenEmptyTag:
+			element, err := ParseStartTag(token.Raw)
+			if err != nil {
+				return nil, err
+			}
+			if err = appendElement(element); err != nil {
+				return nil, err
+			}
+			if token.Kind == TokenStartTag {
+				openElements = append(openElements, element)
+			}
+		case TokenEndTag:
+			if len(openElements) == 0 {
+				return nil, errors.New(fmt.Sprintf("invalid SSML text: closing tag %s without opening tag", token.Raw))
+			}
+			current := openElements[len(openElements)-1]
+			if current.Name != token.Name {
+				return nil, errors.New(fmt.Sprintf("invalid SSML text: expected closing tag </%s>, but found %s", current.Name, token.Raw))
+			}
+			openElements = openElements[:len(openElements)-1]
+		}
+	}
+
+	if len(openElements) > 0 {
+		return nil, errors.New(fmt.Sprintf("invalid SSML text: the element <%s> is never closed", openElements[len(openElements)-1].Name))
+	}
+	if document.Root == nil {
+		return nil, errors.New("invalid SSML text: no root element found")
+	}
+	return document, nil
+}
+
+// ParseStartTag parses a single opening or self-closing tag (e.g. <prosody rate="90%">) into an element without
+// children. Attribute values may be quoted with double or single quotes, or unquoted.
+// Attributes without a value (e.g. <audio controls>) get an empty value.
+func ParseStartTag(tag string) (*Element, error) {
+	if !strings.HasPrefix(tag, "<") || strings.HasPrefix(tag, "</") || !strings.HasSuffix(tag, ">") {
+		return nil, errors.New(fmt.Sprintf("invalid SSML tag: '%s' is not an opening tag", tag))
+	}
+	content := strings.TrimSuffix(tag[1:], ">")
+	element := &Element{Name: tagName(tag)}
+	if strings.HasSuffix(content, "/") {
+		element.SelfClosing = true
+		content = strings.TrimSuffix(content, "/")
+	}
+	if element.Name == "" {
+		return nil, errors.New(fmt.Sprintf("invalid SSML tag: '%s' doesn't have an element name", tag))
+	}
+	content = content[len(element.Name):]
+
+	for {
+		content = strings.TrimLeftFunc(content, unicode.IsSpace)
+		if content == "" {
+			return element, nil
+		}
+		nameEnd := strings.IndexFunc(content, isNameTerminator)
+		if nameEnd < 0 {
+			nameEnd = len(content)
+		}
+		if nameEnd == 0 {
+			return nil, errors.New(fmt.Sprintf("invalid SSML tag: unexpected character '%c' in tag '%s'", content[0], tag))
+		}
+		attribute := Attribute{Name: content[:nameEnd]}
+		content = strings.TrimLeftFunc(content[nameEnd:], unicode.IsSpace)
+
+		if strings.HasPrefix(content, "=") {
+			content = strings.TrimLeftFunc(content[1:], unicode.IsSpace)
+			var rawValue string
+			if strings.HasPrefix(content, "\"") || strings.HasPrefix(content, "'") {
+				valueEnd := strings.IndexByte(content[1:], content[0])
+				if valueEnd < 0 {
+					return nil, errors.New(fmt.Sprintf("invalid SSML tag: unterminated value of attribute '%s' in tag '%s'", attribute.Name, tag))
+				}
+				rawValue = content[1 : valueEnd+1]
+				content = content[valueEnd+2:]
+			} else {
+				valueEnd := strings.IndexFunc(content, unicode.IsSpace)
+				if valueEnd < 0 {
+					valueEnd = len(content)
+				}
+				rawValue = content[:valueEnd]
+				content = content[valueEnd:]
+			}
+			attribute.Value = html.UnescapeString(rawValue)
+		}
+		element.Attributes = append(element.Attributes, attribute)
+	}
+}
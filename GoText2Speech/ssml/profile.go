@@ -0,0 +1,198 @@
+package ssml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ElementProfile describes how an element may be used within a Profile.
+type ElementProfile struct {
+	// Attributes Maps the names of the supported attributes to their allowed values.
+	// If the allowed values of an attribute are nil, every value is allowed.
+	// Namespace declarations (i.e. attributes starting with "xmlns") are always allowed.
+	Attributes map[string][]string
+	// RequiredAttributes The attributes that the element must have.
+	RequiredAttributes []string
+	// ValueTranslations Maps attribute names to a map of unsupported values and the supported values that should be
+	// used instead when translating (see Profile.Translate).
+	ValueTranslations map[string]map[string]string
+}
+
+// Profile describes the subset of SSML that a provider supports.
+type Profile struct {
+	Name string
+	// Elements Maps the qualified names of the supported elements to their ElementProfile.
+	// If Elements is nil, the profile doesn't restrict the SSML text at all.
+	Elements map[string]ElementProfile
+	// ElementTranslations Maps the qualified names of unsupported elements to a function that creates the nodes that
+	// replace the element when translating (see Profile.Translate). The given element is not translated yet, the
+	// returned nodes are translated afterwards. Therefore, the returned nodes must not contain the given element.
+	// Unsupported elements without translation function are replaced by their children.
+	ElementTranslations map[string]func(element *Element) []Node
+}
+
+// Violation is a part of an SSML document that is not supported by a Profile.
+type Violation struct {
+	// Element The name of the element that is not supported or whose attribute is not supported.
+	Element string
+	// Attribute The name of the attribute that is not supported. Empty if the element itself is not supported.
+	Attribute string
+	// Value The value of the attribute that is not supported. Empty if the attribute itself is not supported.
+	Value  string
+	Reason string
+}
+
+func (v Violation) String() string {
+	if v.Attribute == "" {
+		return fmt.Sprintf("<%s>: %s", v.Element, v.Reason)
+	}
+	if v.Value == "" {
+		return fmt.Sprintf("<%s %s>: %s", v.Element, v.Attribute, v.Reason)
+	}
+	return fmt.Sprintf("<%s %s=\"%s\">: %s", v.Element, v.Attribute, v.Value, v.Reason)
+}
+
+// ValidationError is returned by Profile.Validate if the SSML document is not supported by the profile.
+type ValidationError struct {
+	Profile    string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("the SSML text is not supported by %s: %s", e.Profile, strings.Join(messages, "; "))
+}
+
+// Validate checks if all elements and attributes of the given document are supported by the profile.
+// Returns a *ValidationError with all violations, or nil if the document is supported.
+func (p Profile) Validate(document *Document) error {
+	violations := p.check(document, false)
+	if len(violations) > 0 {
+		return &ValidationError{Profile: p.Name, Violations: violations}
+	}
+	return nil
+}
+
+// Translate rewrites the given document in place, so that it is supported by the profile:
+// * Unsupported elements are replaced using Profile.ElementTranslations or, if there is no translation, by their
+// children (e.g. <amazon:effect name="whispered">Hello</amazon:effect> becomes Hello).
+// * Unsupported attribute values are replaced using ElementProfile.ValueTranslations. Unsupported attributes and
+// attribute values without translation are removed.
+// * Elements that are missing required attributes afterwards are replaced by their children.
+// * If the root element is not <speak>, it is wrapped into a <speak> element.
+// Returns the violations that were fixed. If no violations are returned, the document was not changed.
+func (p Profile) Translate(document *Document) []Violation {
+	return p.check(document, true)
+}
+
+// check collects all violations of the given document. If fix is true, the violations are fixed as well.
+func (p Profile) check(document *Document, fix bool) []Violation {
+	if p.Elements == nil {
+		return nil
+	}
+	var violations []Violation
+	if document.Root.Type() != ElementTypeSpeak {
+		violations = append(violations, Violation{Element: document.Root.Name, Reason: "the root element must be <speak>"})
+		if fix {
+			document.Root = NewElement(string(ElementTypeSpeak), document.Root)
+		}
+	}
+	p.checkChildren(document.Root, fix, &violations)
+	p.checkAttributes(document.Root, fix, &violations)
+	return violations
+}
+
+func (p Profile) checkChildren(parent *Element, fix bool, violations *[]Violation) {
+	var children []Node
+	for _, child := range parent.Children {
+		element, ok := child.(*Element)
+		if !ok {
+			children = append(children, child)
+			continue
+		}
+		children = append(children, p.checkElement(element, fix, violations)...)
+	}
+	if fix {
+		parent.Children = children
+	}
+}
+
+// checkElement checks the given (non-root) element and its descendants.
+// If fix is true, returns the nodes that replace the element.
+func (p Profile) checkElement(element *Element, fix bool, violations *[]Violation) []Node {
+	_, supported := p.Elements[element.Name]
+	if !supported || (element.Type() == ElementTypeSpeak) {
+		reason := "unsupported element"
+		if supported {
+			reason = "<speak> is only allowed as root element"
+		}
+		*violations = append(*violations, Violation{Element: element.Name, Reason: reason})
+		if !fix {
+			p.checkChildren(element, fix, violations)
+			return nil
+		}
+
+		replacement := &Element{Children: element.Children}
+		if translate, ok := p.ElementTranslations[element.Name]; ok {
+			replacement.Children = translate(element)
+		}
+		p.checkChildren(replacement, fix, violations)
+		return replacement.Children
+	}
+
+	p.checkChildren(element, fix, violations)
+	if !p.checkAttributes(element, fix, violations) {
+		return element.Children
+	}
+	return []Node{element}
+}
+
+// checkAttributes checks the attributes of the given supported element.
+// Returns false if the element is missing a required attribute after fixing.
+func (p Profile) checkAttributes(element *Element, fix bool, violations *[]Violation) bool {
+	rules := p.Elements[element.Name]
+	var attributes []Attribute
+	for _, attribute := range element.Attributes {
+		if strings.HasPrefix(attribute.Name, "xmlns") {
+			attributes = append(attributes, attribute)
+			continue
+		}
+		allowedValues, supported := rules.Attributes[attribute.Name]
+		if !supported {
+			*violations = append(*violations, Violation{Element: element.Name, Attribute: attribute.Name, Reason: "unsupported attribute"})
+			continue
+		}
+		if (allowedValues != nil) && !containsValue(allowedValues, attribute.Value) {
+			*violations = append(*violations, Violation{Element: element.Name, Attribute: attribute.Name, Value: attribute.Value, Reason: "unsupported value"})
+			translatedValue, ok := rules.ValueTranslations[attribute.Name][attribute.Value]
+			if !ok {
+				continue
+			}
+			attribute.Value = translatedValue
+		}
+		attributes = append(attributes, attribute)
+	}
+	if fix {
+		element.Attributes = attributes
+	}
+
+	for _, required := range rules.RequiredAttributes {
+		if _, ok := element.Attribute(required); !ok {
+			*violations = append(*violations, Violation{Element: element.Name, Attribute: required, Reason: "missing required attribute"})
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
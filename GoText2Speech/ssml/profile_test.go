@@ -0,0 +1,77 @@
+package ssml
+
+import (
+	"errors"
+	"testing"
+)
+
+var testProfile = Profile{
+	Name: "test",
+	Elements: map[string]ElementProfile{
+		"speak": {},
+		"break": {Attributes: map[string][]string{"time": nil}},
+		"say-as": {
+			Attributes:         map[string][]string{"interpret-as": {"characters", "cardinal"}},
+			RequiredAttributes: []string{"interpret-as"},
+			ValueTranslations:  map[string]map[string]string{"interpret-as": {"spell-out": "characters"}},
+		},
+	},
+	ElementTranslations: map[string]func(element *Element) []Node{
+		"pause": func(element *Element) []Node {
+			breakElement := NewElement("break").SetAttribute("time", "1s")
+			breakElement.SelfClosing = true
+			return []Node{breakElement}
+		},
+	},
+}
+
+func TestProfileValidate(t *testing.T) {
+	valid, _ := Parse("<speak xmlns:x=\"y\">Hi<break time=\"1s\"/><say-as interpret-as=\"cardinal\">1</say-as></speak>")
+	if err := testProfile.Validate(valid); err != nil {
+		t.Errorf("Validate returned error for supported document: %s", err.Error())
+	}
+
+	invalid, _ := Parse("<speak><x:effect>Hi</x:effect><break strength=\"weak\"/><say-as interpret-as=\"date\">1</say-as><speak>a</speak></speak>")
+	err := testProfile.Validate(invalid)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate didn't return a ValidationError for unsupported document. Got: %v", err)
+	}
+	if len(validationErr.Violations) != 4 {
+		t.Errorf("Expected 4 violations, got %d: %s", len(validationErr.Violations), err.Error())
+	}
+	if invalid.String() != "<speak><x:effect>Hi</x:effect><break strength=\"weak\"/><say-as interpret-as=\"date\">1</say-as><speak>a</speak></speak>" {
+		t.Errorf("Validate changed the document: %s", invalid.String())
+	}
+}
+
+func TestProfileTranslate(t *testing.T) {
+	document, _ := Parse("<speak><x:effect>Hi <pause/></x:effect><break strength=\"weak\"/>" +
+		"<say-as interpret-as=\"spell-out\">abc</say-as><say-as interpret-as=\"date\">1.1.</say-as></speak>")
+	violations := testProfile.Translate(document)
+	if len(violations) == 0 {
+		t.Error("Translate didn't return the fixed violations")
+	}
+	want := "<speak>Hi <break time=\"1s\"/><break/><say-as interpret-as=\"characters\">abc</say-as>1.1.</speak>"
+	if result := document.String(); result != want {
+		t.Errorf("Document was not translated correctly.\nWanted:\t%s\nGot:\t%s", want, result)
+	}
+	if err := testProfile.Validate(document); err != nil {
+		t.Errorf("Translated document is not valid: %s", err.Error())
+	}
+}
+
+func TestProfileTranslateRoot(t *testing.T) {
+	document, _ := Parse("<p>Hello</p>")
+	testProfile.Translate(document)
+	if result := document.String(); result != "<speak>Hello</speak>" {
+		t.Errorf("Root element was not translated correctly. Got: %s", result)
+	}
+}
+
+func TestEmptyProfile(t *testing.T) {
+	document, _ := Parse("<anything><goes/></anything>")
+	if err := (Profile{}).Validate(document); err != nil {
+		t.Errorf("Empty profile rejected document: %s", err.Error())
+	}
+}
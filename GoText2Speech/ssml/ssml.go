@@ -0,0 +1,320 @@
+// Package ssml parses SSML texts into a tree of elements that can be modified and serialized again.
+//
+// The parser is lenient, so that texts that are accepted by the providers are accepted as well: unescaped '&'
+// characters in text, unquoted attribute values and unknown elements (e.g. <amazon:effect>) are allowed.
+// Text, comments and other markup are preserved exactly as they appear in the original text, so parsing and
+// serializing an SSML text without modifying it only normalizes the formatting of tags and attributes.
+package ssml
+
+import (
+	"html"
+	"strings"
+)
+
+type ElementType string
+
+// The element types that are supported by AWS and GCP (see GetElementType).
+// Elements of other types (e.g. vendor-specific elements like <amazon:effect>) are still parsed,
+// but their ElementType is ElementTypeUnknown.
+const (
+	ElementTypeUnknown   ElementType = ""
+	ElementTypeSpeak     ElementType = "speak"
+	ElementTypeProsody   ElementType = "prosody"
+	ElementTypeBreak     ElementType = "break"
+	ElementTypeSayAs     ElementType = "say-as"
+	ElementTypePhoneme   ElementType = "phoneme"
+	ElementTypeMark      ElementType = "mark"
+	ElementTypeSub       ElementType = "sub"
+	ElementTypeLang      ElementType = "lang"
+	ElementTypeVoice     ElementType = "voice"
+	ElementTypeAudio     ElementType = "audio"
+	ElementTypeParagraph ElementType = "p"
+	ElementTypeSentence  ElementType = "s"
+	ElementTypeEmphasis  ElementType = "emphasis"
+)
+
+var knownElementTypes = []ElementType{
+	ElementTypeSpeak,
+	ElementTypeProsody,
+	ElementTypeBreak,
+	ElementTypeSayAs,
+	ElementTypePhoneme,
+	ElementTypeMark,
+	ElementTypeSub,
+	ElementTypeLang,
+	ElementTypeVoice,
+	ElementTypeAudio,
+	ElementTypeParagraph,
+	ElementTypeSentence,
+	ElementTypeEmphasis,
+}
+
+// GetElementType returns the ElementType of the element with the given name, or ElementTypeUnknown if the element
+// is not one of the known types. Names with a namespace prefix (e.g. "amazon:effect") are always unknown.
+func GetElementType(name string) ElementType {
+	for _, elementType := range knownElementTypes {
+		if string(elementType) == name {
+			return elementType
+		}
+	}
+	return ElementTypeUnknown
+}
+
+// Node is a part of an SSML document. It is either an *Element, a *Text or a *Markup.
+type Node interface {
+	// String serializes the node and all of its children.
+	String() string
+	writeTo(builder *strings.Builder)
+}
+
+// Text is text content of an element.
+type Text struct {
+	// Raw The text exactly as it appears in the SSML document, i.e. escaped.
+	// Raw may contain unescaped '&' characters, if the parsed document contained them.
+	Raw string
+}
+
+// NewText creates a text node from unescaped text.
+func NewText(value string) *Text {
+	return &Text{Raw: EscapeText(value)}
+}
+
+// Value returns the unescaped text.
+func (t *Text) Value() string {
+	return html.UnescapeString(t.Raw)
+}
+
+func (t *Text) String() string {
+	return t.Raw
+}
+
+func (t *Text) writeTo(builder *strings.Builder) {
+	builder.WriteString(t.Raw)
+}
+
+// Markup is a comment, processing instruction, CDATA section or declaration. It is preserved verbatim.
+type Markup struct {
+	Raw string
+}
+
+func (m *Markup) String() string {
+	return m.Raw
+}
+
+func (m *Markup) writeTo(builder *strings.Builder) {
+	builder.WriteString(m.Raw)
+}
+
+// Attribute is an attribute of an element. The Value is unescaped.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Element is an SSML element with its attributes and children.
+type Element struct {
+	// Name The qualified name of the element, e.g. "prosody" or "amazon:effect".
+	Name string
+	// Attributes The attributes of the element in the order in which they appear in the tag.
+	Attributes []Attribute
+	Children   []Node
+	// SelfClosing If true and the element doesn't have any children, the element is serialized as a
+	// self-closing tag (e.g. <break time="1s"/>).
+	SelfClosing bool
+}
+
+// NewElement creates an element with the given name and children.
+func NewElement(name string, children ...Node) *Element {
+	return &Element{Name: name, Children: children}
+}
+
+// Type returns the ElementType of the element (see GetElementType).
+func (e *Element) Type() ElementType {
+	return GetElementType(e.Name)
+}
+
+// Prefix returns the namespace prefix of the element name, e.g. "amazon" for "amazon:effect".
+// Returns an empty string if the name doesn't have a prefix.
+func (e *Element) Prefix() string {
+	prefix, _, found := strings.Cut(e.Name, ":")
+	if !found {
+		return ""
+	}
+	return prefix
+}
+
+// LocalName returns the element name without namespace prefix, e.g. "effect" for "amazon:effect".
+func (e *Element) LocalName() string {
+	_, localName, found := strings.Cut(e.Name, ":")
+	if !found {
+		return e.Name
+	}
+	return localName
+}
+
+// Attribute returns the value of the attribute with the given name.
+// The second return value is false if the element doesn't have the attribute.
+func (e *Element) Attribute(name string) (string, bool) {
+	for _, attribute := range e.Attributes {
+		if attribute.Name == name {
+			return attribute.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttribute sets the value of the attribute with the given name.
+// If the element doesn't have the attribute yet, it is added after all other attributes.
+func (e *Element) SetAttribute(name string, value string) *Element {
+	for i, attribute := range e.Attributes {
+		if attribute.Name == name {
+			e.Attributes[i].Value = value
+			return e
+		}
+	}
+	e.Attributes = append(e.Attributes, Attribute{Name: name, Value: value})
+	return e
+}
+
+// RemoveAttribute removes the attribute with the given name, if it exists.
+func (e *Element) RemoveAttribute(name string) *Element {
+	for i, attribute := range e.Attributes {
+		if attribute.Name == name {
+			e.Attributes = append(e.Attributes[:i], e.Attributes[i+1:]...)
+			return e
+		}
+	}
+	return e
+}
+
+// AppendChild adds the given nodes at the end of the children of the element.
+func (e *Element) AppendChild(nodes ...Node) *Element {
+	e.Children = append(e.Children, nodes...)
+	return e
+}
+
+// WrapChildren moves all children of the element into the given wrapper element and makes the wrapper the only
+// child of the element. Example: wrapping the children of <speak>Hello</speak> into a <prosody> element results in
+// <speak><prosody>Hello</prosody></speak>.
+func (e *Element) WrapChildren(wrapper *Element) *Element {
+	wrapper.Children = append(wrapper.Children, e.Children...)
+	e.Children = []Node{wrapper}
+	return e
+}
+
+// Elements returns all descendant elements of the given type in document order.
+func (e *Element) Elements(elementType ElementType) []*Element {
+	var elements []*Element
+	for _, child := range e.Children {
+		if childElement, ok := child.(*Element); ok {
+			if childElement.Type() == elementType {
+				elements = append(elements, childElement)
+			}
+			elements = append(elements, childElement.Elements(elementType)...)
+		}
+	}
+	return elements
+}
+
+// Text returns the unescaped text content of the element and all of its descendants.
+func (e *Element) Text() string {
+	var builder strings.Builder
+	for _, child := range e.Children {
+		switch node := child.(type) {
+		case *Text:
+			builder.WriteString(node.Value())
+		case *Element:
+			builder.WriteString(node.Text())
+		}
+	}
+	return builder.String()
+}
+
+// StartTag returns the serialized opening tag of the element, e.g. <prosody rate="90%">.
+// For self-closing elements without children, the self-closing tag is returned, e.g. <break time="1s"/>.
+func (e *Element) StartTag() string {
+	var builder strings.Builder
+	e.writeStartTag(&builder)
+	return builder.String()
+}
+
+// EndTag returns the closing tag of the element, e.g. </prosody>.
+// For self-closing elements without children, an empty string is returned.
+func (e *Element) EndTag() string {
+	if e.isSelfClosing() {
+		return ""
+	}
+	return "</" + e.Name + ">"
+}
+
+func (e *Element) isSelfClosing() bool {
+	return e.SelfClosing && (len(e.Children) == 0)
+}
+
+func (e *Element) writeStartTag(builder *strings.Builder) {
+	builder.WriteString("<" + e.Name)
+	for _, attribute := range e.Attributes {
+		builder.WriteString(" " + attribute.Name + "=\"" + EscapeText(attribute.Value) + "\"")
+	}
+	if e.isSelfClosing() {
+		builder.WriteString("/>")
+	} else {
+		builder.WriteString(">")
+	}
+}
+
+func (e *Element) String() string {
+	var builder strings.Builder
+	e.writeTo(&builder)
+	return builder.String()
+}
+
+func (e *Element) writeTo(builder *strings.Builder) {
+	e.writeStartTag(builder)
+	for _, child := range e.Children {
+		child.writeTo(builder)
+	}
+	builder.WriteString(e.EndTag())
+}
+
+// Document is a parsed SSML text.
+type Document struct {
+	// Prolog Everything in front of the root element, e.g. an XML declaration, comments or whitespace.
+	Prolog []Node
+	// Root The root element, usually <speak>.
+	Root *Element
+	// Epilog Everything after the root element, e.g. comments or whitespace.
+	Epilog []Node
+}
+
+// NewDocument creates a document with the given root element.
+func NewDocument(root *Element) *Document {
+	return &Document{Root: root}
+}
+
+func (d *Document) String() string {
+	var builder strings.Builder
+	for _, node := range d.Prolog {
+		node.writeTo(&builder)
+	}
+	if d.Root != nil {
+		d.Root.writeTo(&builder)
+	}
+	for _, node := range d.Epilog {
+		node.writeTo(&builder)
+	}
+	return builder.String()
+}
+
+// EscapeText escapes the reserved characters of SSML. These reserved characters are the same for GCP and AWS.
+func EscapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+var textEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"\"", "&quot;",
+	"'", "&apos;",
+	"<", "&lt;",
+	">", "&gt;",
+)
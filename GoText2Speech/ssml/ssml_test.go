@@ -0,0 +1,110 @@
+package ssml
+
+import (
+	"testing"
+)
+
+func TestParseAndSerializeRoundTrip(t *testing.T) {
+	inputs := []string{
+		"<speak>Hello World!</speak>",
+		"<speak>Hello & World!</speak>",
+		"<?xml version=\"1.0\"?>\n<speak><!-- a > comment --><p><s>First.</s><s>Second.</s></p></speak>\n",
+		"<speak><break time=\"1s\"/><say-as interpret-as=\"characters\">abc</say-as></speak>",
+		"<speak xmlns:amazon=\"https://amazon.com\"><amazon:effect name=\"whispered\">psst</amazon:effect></speak>",
+		"<speak><audio src=\"https://example.com/a.mp3?x=1&amp;y=2\">fallback</audio><![CDATA[<raw>]]></speak>",
+	}
+	for _, input := range inputs {
+		document, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse returned error for '%s': %s", input, err.Error())
+			continue
+		}
+		if result := document.String(); result != input {
+			t.Errorf("Document was not serialized correctly.\nWanted:\t%s\nGot:\t%s", input, result)
+		}
+	}
+}
+
+func TestParseAttributeWithGreaterThan(t *testing.T) {
+	document, err := Parse("<speak><sub alias=\"a > b\">a&gt;b</sub></speak>")
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+	subs := document.Root.Elements(ElementTypeSub)
+	if len(subs) != 1 {
+		t.Fatalf("Expected 1 <sub> element, got %d", len(subs))
+	}
+	if alias, _ := subs[0].Attribute("alias"); alias != "a > b" {
+		t.Errorf("Wrong attribute value. Wanted: 'a > b', Got: '%s'", alias)
+	}
+	if text := subs[0].Text(); text != "a>b" {
+		t.Errorf("Wrong text. Wanted: 'a>b', Got: '%s'", text)
+	}
+}
+
+func TestParseNormalizesTags(t *testing.T) {
+	document, err := Parse("<speak><prosody rate='90%'   volume=loud>Hi</prosody ><break time=\"1s\" /></speak>")
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+	want := "<speak><prosody rate=\"90%\" volume=\"loud\">Hi</prosody><break time=\"1s\"/></speak>"
+	if result := document.String(); result != want {
+		t.Errorf("Document was not serialized correctly.\nWanted:\t%s\nGot:\t%s", want, result)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	inputs := []string{
+		"Hello World",
+		"<speak>Hello",
+		"<speak><p>Hello</s></speak>",
+		"<speak>Hello</speak><speak>World</speak>",
+		"<speak>Hello</speak> World",
+		"<speak>Hello</speak></speak>",
+	}
+	for _, input := range inputs {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("No error was returned for invalid SSML text '%s'", input)
+		}
+	}
+}
+
+func TestElementTypes(t *testing.T) {
+	document, err := Parse("<speak><lang xml:lang=\"de-DE\">Hallo</lang><amazon:breath/><mark name=\"m1\"/></speak>")
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+	if document.Root.Type() != ElementTypeSpeak {
+		t.Errorf("Wrong type of root element: %s", document.Root.Type())
+	}
+	children := document.Root.Children
+	if children[0].(*Element).Type() != ElementTypeLang {
+		t.Errorf("Wrong type of <lang> element: %s", children[0].(*Element).Type())
+	}
+	breath := children[1].(*Element)
+	if (breath.Type() != ElementTypeUnknown) || (breath.Prefix() != "amazon") || (breath.LocalName() != "breath") {
+		t.Errorf("Namespaced element was not parsed correctly: %+v", breath)
+	}
+	if len(document.Root.Elements(ElementTypeMark)) != 1 {
+		t.Error("<mark> element was not found")
+	}
+}
+
+func TestWrapChildren(t *testing.T) {
+	document, _ := Parse("<speak>Hello <emphasis>World</emphasis></speak>")
+	document.Root.WrapChildren(NewElement("prosody").SetAttribute("rate", "110%"))
+	want := "<speak><prosody rate=\"110%\">Hello <emphasis>World</emphasis></prosody></speak>"
+	if result := document.String(); result != want {
+		t.Errorf("Children were not wrapped correctly.\nWanted:\t%s\nGot:\t%s", want, result)
+	}
+}
+
+func TestNewText(t *testing.T) {
+	text := NewText("Tom & Jerry's \"<show>\"")
+	if text.Raw != "Tom &amp; Jerry&apos;s &quot;&lt;show&gt;&quot;" {
+		t.Errorf("Text was not escaped correctly: %s", text.Raw)
+	}
+	if text.Value() != "Tom & Jerry's \"<show>\"" {
+		t.Errorf("Text was not unescaped correctly: %s", text.Value())
+	}
+}
@@ -0,0 +1,121 @@
+package ssml
+
+import (
+	"strings"
+	"unicode"
+)
+
+type TokenKind int
+
+const (
+	// TokenText is text between tags. The text is not unescaped.
+	TokenText TokenKind = iota
+	// TokenStartTag is an opening tag, e.g. <prosody rate="90%">
+	TokenStartTag
+	// TokenEndTag is a closing tag, e.g. </prosody>
+	TokenEndTag
+	// TokenEmptyTag is a self-closing tag, e.g. <break time="1s"/>
+	TokenEmptyTag
+	// TokenOther is a comment, processing instruction, CDATA section or declaration
+	TokenOther
+)
+
+// Token is a part of an SSML text as returned by Tokenize.
+type Token struct {
+	Kind TokenKind
+	// Raw The exact text of the token. Joining the raw texts of all tokens results in the original text.
+	Raw string
+	// Name The qualified element name (only for start, end and empty tags), e.g. "prosody" or "amazon:effect".
+	Name string
+}
+
+// Tokenize splits the given SSML text into tags and text.
+// Unlike splitting the text at '>' characters, quoted attribute values may contain '>' characters, and comments,
+// CDATA sections and processing instructions are recognized as a whole.
+// Tokenize never fails. Malformed tags at the end of the text (e.g. a missing '>') extend to the end of the text.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '<')
+		if start != 0 {
+			if start < 0 {
+				start = len(text)
+			}
+			tokens = append(tokens, Token{Kind: TokenText, Raw: text[:start]})
+			text = text[start:]
+			continue
+		}
+
+		var end int
+		kind := TokenOther
+		switch {
+		case strings.HasPrefix(text, "<!--"):
+			end = indexEnd(text, "-->")
+		case strings.HasPrefix(text, "<![CDATA["):
+			end = indexEnd(text, "]]>")
+		case strings.HasPrefix(text, "<?"):
+			end = indexEnd(text, "?>")
+		default:
+			end = indexTagEnd(text)
+			switch {
+			case strings.HasPrefix(text, "<!"):
+				kind = TokenOther
+			case strings.HasPrefix(text, "</"):
+				kind = TokenEndTag
+			case strings.HasSuffix(text[:end], "/>"):
+				kind = TokenEmptyTag
+			default:
+				kind = TokenStartTag
+			}
+		}
+		token := Token{Kind: kind, Raw: text[:end]}
+		if kind != TokenOther {
+			token.Name = tagName(token.Raw)
+		}
+		tokens = append(tokens, token)
+		text = text[end:]
+	}
+	return tokens
+}
+
+// indexEnd returns the index after the first occurrence of terminator, or the length of text if it doesn't occur.
+func indexEnd(text string, terminator string) int {
+	index := strings.Index(text, terminator)
+	if index < 0 {
+		return len(text)
+	}
+	return index + len(terminator)
+}
+
+// indexTagEnd returns the index after the '>' that closes the tag at the beginning of text.
+// '>' characters inside quoted attribute values are ignored.
+func indexTagEnd(text string) int {
+	var quote byte = 0
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+		} else if (c == '"') || (c == '\'') {
+			quote = c
+		} else if c == '>' {
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// tagName returns the element name of the given tag, e.g. "<prosody rate="90%">" -> "prosody".
+func tagName(tag string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	end := strings.IndexFunc(name, isNameTerminator)
+	if end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
+
+func isNameTerminator(r rune) bool {
+	return unicode.IsSpace(r) || (r == '/') || (r == '>') || (r == '=')
+}
@@ -0,0 +1,80 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+)
+
+// T2SDirectToWriter is the same as T2SDirectWithResult, but instead of storing the audio at a destination, the audio
+// is written to the given writer (e.g. an http.ResponseWriter) while it is received from the provider. The voice is
+// chosen and the options are transformed the same way as in T2SDirect.
+// The audio is only buffered completely if the provider returns it as a whole (e.g. GCP), if the text is split into
+// chunks (see TextToSpeechOptions.SplitLongText), if a WAV container needs to be added or removed
+// (see TextToSpeechOptions.RawAudioOutput) or if the client has a synthesis cache (see SetSynthesisCache).
+// Otherwise, it is streamed (e.g. the AudioStream of AWS Polly).
+// Subtitles are not supported, because there is no destination to store them at. Speech marks are returned as part
+// of the result. If the options don't specify a provider, the provider is chosen like in T2SDirect, except that there is
+// no destination whose storage service could be preferred.
+// If an error occurs after the first bytes were written, the writer contains incomplete audio.
+func (a *GoT2SClient) T2SDirectToWriter(ctx context.Context, text string, w io.Writer, options TextToSpeechOptions) (*T2SResult, error) {
+	ctx = a.contextWithLogger(ctx)
+	tel := a.getTelemetry()
+	ctx, span := tel.tracer.Start(ctx, "T2SDirectToWriter")
+	defer span.End()
+	result, err := a.t2sDirectToWriter(ctx, text, w, options)
+	if err != nil {
+		tel.recordError(ctx, span, err, options)
+		return nil, err
+	}
+	return result, nil
+}
+
+// t2sDirectToWriter implements T2SDirectToWriter. The context must carry the logger of the client.
+func (a *GoT2SClient) t2sDirectToWriter(ctx context.Context, text string, w io.Writer, options TextToSpeechOptions) (*T2SResult, error) {
+	if options.Subtitles.Format != SubtitleFormatNone {
+		return nil, errors.Join(ErrInvalidOptions, errors.New("subtitles are not supported when the audio is written to a writer"))
+	}
+	options, err := inferTextType(text, options)
+	if err != nil {
+		return nil, err
+	}
+
+	failoverOptions := options
+	ctx, usage := contextWithBillingUsage(ctx)
+	if options.Provider == providers.ProviderUnspecified {
+		options, err = a.determineProvider(ctx, options, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider, audioData, speechMarks, options, failedProviders, err := a.synthesizeWithFailover(ctx, text, "", options, failoverOptions)
+	if err != nil {
+		return nil, err
+	}
+	if closer, isCloser := audioData.(io.Closer); isCloser {
+		defer closer.Close()
+	}
+	trace.SpanFromContext(ctx).SetAttributes(voiceAttributes(options)...)
+	billing := a.billingRecord(usage, provider, options)
+	a.recordBillingMetrics(ctx, billing)
+	written, err := io.Copy(w, audioData)
+	a.getTelemetry().audioBytes.Add(ctx, written, metric.WithAttributes(voiceAttributes(options)...))
+	if err != nil {
+		return nil, errors.Join(errors.New(fmt.Sprintf("error while writing audio synthesized on provider %s", options.Provider)), ErrUploadFailed, err)
+	}
+
+	return &T2SResult{
+		Provider:        options.Provider,
+		VoiceIdConfig:   options.VoiceConfig.VoiceIdConfig,
+		SpeechMarks:     speechMarks,
+		FailedProviders: failedProviders,
+		Billing:         billing,
+	}, nil
+}
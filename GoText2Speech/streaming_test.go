@@ -0,0 +1,41 @@
+package GoText2Speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"testing"
+)
+
+// failingWriter is a writer that always fails.
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("fake write error")
+}
+
+func TestT2SDirectToWriter(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	var buffer bytes.Buffer
+	result, err := client.T2SDirectToWriter(context.Background(), "Hello", &buffer, testOptions())
+	if err != nil {
+		t.Fatalf("T2SDirectToWriter returned error: %s", err.Error())
+	}
+	if (buffer.String() != "Hello") || (result.Provider != fake.name) || (result.VoiceIdConfig.VoiceId != "FAKE-voice") {
+		t.Errorf("Wrong audio data or result: %s, %+v", buffer.String(), result)
+	}
+
+	if _, err = client.T2SDirectToWriter(context.Background(), "Hello", failingWriter{}, testOptions()); err == nil {
+		t.Errorf("Error of writer was not returned")
+	}
+
+	options := testOptions()
+	options.Subtitles.Format = SubtitleFormatSrt
+	if _, err = client.T2SDirectToWriter(context.Background(), "Hello", &buffer, options); err == nil {
+		t.Errorf("Subtitles were accepted without destination")
+	}
+}
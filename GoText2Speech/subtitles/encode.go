@@ -0,0 +1,77 @@
+package subtitles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"io"
+	"strings"
+	"time"
+)
+
+// Encode encodes the given cues in the given subtitle format.
+func Encode(subtitleFormat SubtitleFormat, cues []Cue) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var err error
+	switch subtitleFormat {
+	case SubtitleFormatSrt:
+		err = WriteSRT(buf, cues)
+	case SubtitleFormatVtt:
+		err = WriteVTT(buf, cues)
+	default:
+		err = errors.New(fmt.Sprintf("the subtitle format '%s' is not supported", subtitleFormat))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteSRT writes the given cues as SubRip (.srt) subtitles into the given writer.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	for i, cue := range cues {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.Text())
+		if err != nil {
+			return errors.Join(errors.New("error while writing SRT subtitles"), err)
+		}
+	}
+	return nil
+}
+
+// vttEscaper escapes the characters that have a special meaning in the text of WebVTT cues. Escaping ">" prevents
+// that words like "-->" are taken for the timing of a cue.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteVTT writes the given cues as WebVTT (.vtt) subtitles into the given writer.
+// The text of the cues is escaped (e.g. "&" as "&amp;").
+func WriteVTT(w io.Writer, cues []Cue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return errors.Join(errors.New("error while writing WebVTT subtitles"), err)
+	}
+	for i, cue := range cues {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), vttEscaper.Replace(cue.Text()))
+		if err != nil {
+			return errors.Join(errors.New("error while writing WebVTT subtitles"), err)
+		}
+	}
+	return nil
+}
+
+// formatTimestamp formats the given duration as hh:mm:ss<separator>mmm.
+// SRT uses a comma as separator for the milliseconds, WebVTT uses a dot.
+func formatTimestamp(d time.Duration, separator string) string {
+	if d < 0 {
+		d = 0
+	}
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+	milliseconds := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, seconds, separator, milliseconds)
+}
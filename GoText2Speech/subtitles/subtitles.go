@@ -0,0 +1,232 @@
+// Package subtitles creates SRT and WebVTT subtitles from the speech marks of synthesized audio.
+package subtitles
+
+import (
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"path"
+	"strings"
+	"time"
+)
+
+// Cue is a piece of text that is displayed from Start to End.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Text returns the lines of the cue separated by line breaks.
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+const (
+	// durationPerCharacter is used to estimate how long the last words of the audio are spoken,
+	// since speech marks only contain the point in time at which a word starts.
+	durationPerCharacter = 70 * time.Millisecond
+	// minEstimatedDuration is the minimum estimated duration of the last cue.
+	minEstimatedDuration = time.Second
+)
+
+// withDefaults replaces all unset properties of the given options with the values from GetDefaultSubtitleOptions.
+func withDefaults(options SubtitleOptions) SubtitleOptions {
+	defaults := GetDefaultSubtitleOptions()
+	if options.MaxLineLength <= 0 {
+		options.MaxLineLength = defaults.MaxLineLength
+	}
+	if options.MaxLinesPerCue <= 0 {
+		options.MaxLinesPerCue = defaults.MaxLinesPerCue
+	}
+	if options.MaxCueDuration <= 0 {
+		options.MaxCueDuration = defaults.MaxCueDuration
+	}
+	return options
+}
+
+// Generate creates cues from the given speech marks and encodes them in the format given by SubtitleOptions.Format.
+func Generate(speechMarks []SpeechMark, options SubtitleOptions) ([]byte, error) {
+	cues, err := CreateCues(speechMarks, options)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(options.Format, cues)
+}
+
+// CreateCues groups the given speech marks into cues that respect the line length, number of lines and cue duration
+// of the given options.
+// If word speech marks are available, they are used for the timing of the cues. A new cue is started for every
+// sentence (determined by the sentence speech marks if available, otherwise by the punctuation of the words).
+// If only sentence speech marks are available, every sentence is turned into one or more cues. If a sentence needs
+// multiple cues, the time of the sentence is distributed according to the number of characters in each cue.
+func CreateCues(speechMarks []SpeechMark, options SubtitleOptions) ([]Cue, error) {
+	options = withDefaults(options)
+
+	var words []SpeechMark
+	var sentences []SpeechMark
+	for _, mark := range speechMarks {
+		if strings.TrimSpace(mark.Value) == "" {
+			continue
+		}
+		switch mark.Type {
+		case SpeechMarkTypeWord:
+			words = append(words, mark)
+		case SpeechMarkTypeSentence:
+			sentences = append(sentences, mark)
+		}
+	}
+	SortSpeechMarks(words)
+	SortSpeechMarks(sentences)
+
+	if len(words) > 0 {
+		return createCuesFromWords(words, sentences, options), nil
+	}
+	if len(sentences) > 0 {
+		return createCuesFromSentences(sentences, options), nil
+	}
+	return nil, errors.New("unable to create subtitles: no word or sentence speech marks available")
+}
+
+func createCuesFromWords(words []SpeechMark, sentences []SpeechMark, options SubtitleOptions) []Cue {
+	sentenceStarts := make(map[int]bool)
+	for _, sentence := range sentences {
+		if sentence.Start >= 0 {
+			sentenceStarts[sentence.Start] = true
+		}
+	}
+	startsSentence := func(i int) bool {
+		if i == 0 {
+			return false
+		}
+		if len(sentenceStarts) > 0 {
+			return sentenceStarts[words[i].Start]
+		}
+		previous := words[i-1].Value
+		return (len(previous) > 0) && strings.ContainsAny(previous[len(previous)-1:], ".!?")
+	}
+
+	var cues []Cue
+	var cueWords []string
+	var cueStart time.Duration
+	flush := func(end time.Duration) {
+		if len(cueWords) == 0 {
+			return
+		}
+		cues = append(cues, Cue{
+			Start: cueStart,
+			End:   minDuration(end, cueStart+options.MaxCueDuration),
+			Lines: wrapWords(cueWords, options.MaxLineLength),
+		})
+		cueWords = nil
+	}
+
+	for i, word := range words {
+		value := strings.TrimSpace(word.Value)
+		if len(cueWords) > 0 {
+			candidate := append(append([]string{}, cueWords...), value)
+			if startsSentence(i) ||
+				(len(wrapWords(candidate, options.MaxLineLength)) > options.MaxLinesPerCue) ||
+				(word.Time-cueStart >= options.MaxCueDuration) {
+				flush(word.Time)
+			}
+		}
+		if len(cueWords) == 0 {
+			cueStart = word.Time
+		}
+		cueWords = append(cueWords, value)
+	}
+	lastWord := words[len(words)-1]
+	flush(lastWord.Time + estimateDuration(lastWord.Value))
+	return cues
+}
+
+func createCuesFromSentences(sentences []SpeechMark, options SubtitleOptions) []Cue {
+	var cues []Cue
+	for i, sentence := range sentences {
+		var end time.Duration
+		if i+1 < len(sentences) {
+			end = sentences[i+1].Time
+		} else {
+			end = sentence.Time + estimateDuration(sentence.Value)
+		}
+
+		lines := wrapWords(strings.Fields(sentence.Value), options.MaxLineLength)
+		totalLength := 0
+		for _, line := range lines {
+			totalLength += len(line)
+		}
+
+		start := sentence.Time
+		processedLength := 0
+		for len(lines) > 0 {
+			cueLines := lines[:minInt(options.MaxLinesPerCue, len(lines))]
+			lines = lines[len(cueLines):]
+			for _, line := range cueLines {
+				processedLength += len(line)
+			}
+			cueEnd := sentence.Time + time.Duration(float64(end-sentence.Time)*float64(processedLength)/float64(totalLength))
+			cues = append(cues, Cue{
+				Start: start,
+				End:   minDuration(cueEnd, start+options.MaxCueDuration),
+				Lines: cueLines,
+			})
+			start = cueEnd
+		}
+	}
+	return cues
+}
+
+// wrapWords distributes the given words on lines with at most maxLineLength characters.
+// Words that are longer than maxLineLength are put on their own line.
+func wrapWords(words []string, maxLineLength int) []string {
+	var lines []string
+	line := ""
+	for _, word := range words {
+		if line == "" {
+			line = word
+		} else if len([]rune(line))+1+len([]rune(word)) <= maxLineLength {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// estimateDuration estimates how long it takes to speak the given text.
+func estimateDuration(text string) time.Duration {
+	estimated := time.Duration(len([]rune(text))) * durationPerCharacter
+	if estimated < minEstimatedDuration {
+		return minEstimatedDuration
+	}
+	return estimated
+}
+
+func minDuration(a time.Duration, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// DestinationFor returns the destination of the subtitle file for the given audio destination, i.e. the audio
+// destination with the file extension of the given subtitle format instead of the audio file extension.
+// For example, the subtitle destination of "s3://bucket/speech.mp3" is "s3://bucket/speech.srt".
+func DestinationFor(audioDestination string, subtitleFormat SubtitleFormat) string {
+	extension := path.Ext(audioDestination)
+	if strings.Contains(extension, "\\") { // local Windows path without file extension
+		extension = ""
+	}
+	return strings.TrimSuffix(audioDestination, extension) + SubtitleFormatToFileExtension(subtitleFormat)
+}
@@ -0,0 +1,151 @@
+package subtitles
+
+import (
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func word(ms int, start int, value string) shared.SpeechMark {
+	return shared.SpeechMark{
+		Type:  shared.SpeechMarkTypeWord,
+		Time:  time.Duration(ms) * time.Millisecond,
+		Start: start,
+		End:   start + len(value),
+		Value: value,
+	}
+}
+
+func TestCreateCuesFromWords(t *testing.T) {
+	// "Hello there. How are you doing today?"
+	marks := []shared.SpeechMark{
+		word(0, 0, "Hello"),
+		word(400, 6, "there."),
+		word(1200, 13, "How"),
+		word(1400, 17, "are"),
+		word(1600, 21, "you"),
+		word(1800, 25, "doing"),
+		word(2200, 31, "today?"),
+	}
+	cues, err := CreateCues(marks, shared.SubtitleOptions{MaxLineLength: 10, MaxLinesPerCue: 2, MaxCueDuration: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("CreateCues returned error: %s", err.Error())
+	}
+
+	expected := []Cue{
+		{Start: 0, End: 1200 * time.Millisecond, Lines: []string{"Hello", "there."}},
+		{Start: 1200 * time.Millisecond, End: 2200 * time.Millisecond, Lines: []string{"How are", "you doing"}},
+		{Start: 2200 * time.Millisecond, End: 3200 * time.Millisecond, Lines: []string{"today?"}},
+	}
+	if !reflect.DeepEqual(cues, expected) {
+		t.Errorf("Wrong cues.\nWanted:\t%+v\nGot:\t%+v", expected, cues)
+	}
+}
+
+func TestCreateCuesMaxCueDuration(t *testing.T) {
+	marks := []shared.SpeechMark{
+		word(0, 0, "Slow"),
+		word(3000, 5, "speech"),
+	}
+	cues, err := CreateCues(marks, shared.SubtitleOptions{MaxCueDuration: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("CreateCues returned error: %s", err.Error())
+	}
+	if len(cues) != 2 {
+		t.Fatalf("Expected 2 cues, got %d: %+v", len(cues), cues)
+	}
+	if cues[0].End != 2*time.Second {
+		t.Errorf("Cue was not limited to the maximum cue duration. End: %s", cues[0].End)
+	}
+}
+
+func TestCreateCuesFromSentences(t *testing.T) {
+	marks := []shared.SpeechMark{
+		{Type: shared.SpeechMarkTypeSentence, Time: 0, Start: 0, End: 22, Value: "One two three four five"},
+		{Type: shared.SpeechMarkTypeSentence, Time: 2 * time.Second, Start: 23, End: 27, Value: "Six."},
+	}
+	cues, err := CreateCues(marks, shared.SubtitleOptions{MaxLineLength: 9, MaxLinesPerCue: 1})
+	if err != nil {
+		t.Fatalf("CreateCues returned error: %s", err.Error())
+	}
+	if len(cues) != 4 {
+		t.Fatalf("Expected 4 cues, got %d: %+v", len(cues), cues)
+	}
+	if cues[0].Text() != "One two" || cues[2].Text() != "four five" || cues[3].Text() != "Six." {
+		t.Errorf("Sentences were not split into cues correctly: %+v", cues)
+	}
+	if cues[2].End != 2*time.Second || cues[3].Start != 2*time.Second {
+		t.Errorf("Time of sentence was not distributed correctly: %+v", cues)
+	}
+}
+
+func TestCreateCuesWithoutSpeechMarks(t *testing.T) {
+	marks := []shared.SpeechMark{{Type: shared.SpeechMarkTypeViseme, Value: "p"}}
+	if _, err := CreateCues(marks, shared.SubtitleOptions{}); err == nil {
+		t.Error("No error was returned for speech marks without words and sentences")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 1500 * time.Millisecond, Lines: []string{"Hello", "World"}},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, End: time.Hour + 3*time.Minute, Lines: []string{"Bye"}},
+	}
+
+	srt, err := Encode(shared.SubtitleFormatSrt, cues)
+	if err != nil {
+		t.Fatalf("Encode returned error: %s", err.Error())
+	}
+	expectedSrt := "1\n00:00:00,000 --> 00:00:01,500\nHello\nWorld\n\n2\n01:02:03,004 --> 01:03:00,000\nBye\n\n"
+	if string(srt) != expectedSrt {
+		t.Errorf("Wrong SRT subtitles.\nWanted:\t%q\nGot:\t%q", expectedSrt, string(srt))
+	}
+
+	vtt, err := Encode(shared.SubtitleFormatVtt, cues)
+	if err != nil {
+		t.Fatalf("Encode returned error: %s", err.Error())
+	}
+	if !strings.HasPrefix(string(vtt), "WEBVTT\n\n1\n00:00:00.000 --> 00:00:01.500\n") {
+		t.Errorf("Wrong WebVTT subtitles: %q", string(vtt))
+	}
+
+	if _, err = Encode(shared.SubtitleFormatNone, cues); err == nil {
+		t.Error("No error was returned for unsupported subtitle format")
+	}
+}
+
+func TestDestinationFor(t *testing.T) {
+	tests := map[string]string{
+		"s3://bucket/speech.mp3":                   "s3://bucket/speech.srt",
+		"https://storage.cloud.google.com/b/a.b/c": "https://storage.cloud.google.com/b/a.b/c.srt",
+		"speech": "speech.srt",
+	}
+	for audioDestination, expected := range tests {
+		if actual := DestinationFor(audioDestination, shared.SubtitleFormatSrt); actual != expected {
+			t.Errorf("Wrong subtitle destination for '%s'. Wanted: %s, Got: %s", audioDestination, expected, actual)
+		}
+	}
+}
+
+func TestEncodeVTTEscapesText(t *testing.T) {
+	cues := []Cue{{Start: 0, End: time.Second, Lines: []string{"Q&A <b>", "a --> b"}}}
+	vtt, err := Encode(shared.SubtitleFormatVtt, cues)
+	if err != nil {
+		t.Fatalf("Encode returned error: %s", err.Error())
+	}
+	if expected := "Q&amp;A &lt;b&gt;\na --&gt; b\n"; !strings.Contains(string(vtt), expected) {
+		t.Errorf("Cue text was not escaped. Wanted %q in %q", expected, string(vtt))
+	}
+}
+
+func TestCreateCuesWithEmptyWord(t *testing.T) {
+	marks := []shared.SpeechMark{
+		word(0, 0, ""),
+		word(400, 1, "Hello"),
+	}
+	if _, err := CreateCues(marks, shared.SubtitleOptions{}); err != nil {
+		t.Fatalf("CreateCues returned error: %s", err.Error())
+	}
+}
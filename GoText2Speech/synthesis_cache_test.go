@@ -0,0 +1,41 @@
+package GoText2Speech
+
+import (
+	"bytes"
+	"context"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSynthesisCache(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	client.SetSynthesisCache(NewMemorySynthesisCache(10, 0))
+	options := testOptions()
+	options.Provider = fake.name
+
+	for i := 0; i < 3; i++ {
+		var buffer bytes.Buffer
+		if _, err := client.T2SDirectToWriter(context.Background(), "Hello", &buffer, options); err != nil {
+			t.Fatalf("T2SDirectToWriter returned error: %s", err.Error())
+		}
+		if buffer.String() != "Hello" {
+			t.Errorf("Wrong audio data: %s", buffer.String())
+		}
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 1 {
+		t.Errorf("Text was synthesized %d times, wanted 1", syntheses)
+	}
+
+	// different options need a new synthesis
+	options.SpeakingRate = 1.5
+	var buffer bytes.Buffer
+	if _, err := client.T2SDirectToWriter(context.Background(), "Hello", &buffer, options); err != nil {
+		t.Fatalf("T2SDirectToWriter returned error: %s", err.Error())
+	}
+	if syntheses := atomic.LoadInt32(fake.syntheses); syntheses != 2 {
+		t.Errorf("Text was synthesized %d times, wanted 2", syntheses)
+	}
+}
@@ -0,0 +1,149 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FaaSTools/GoText2Speech/GoText2Speech/providers"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"time"
+)
+
+// StartT2SJob starts an asynchronous synthesis job that writes the audio file directly to the given destination,
+// which must be on the storage service of the chosen provider (i.e. S3 on AWS or Cloud Storage on GCP).
+// Unlike T2SDirect, the text is not split into chunks and the audio doesn't pass through the local memory, so that
+// long texts (e.g. books) can be synthesized (see T2SJobProvider.GetT2SJobTextLengthLimit).
+// Returns ErrT2SJobsNotSupported if the provider doesn't implement T2SJobProvider.
+// If the options don't specify a provider, a provider is chosen like in T2SDirect.
+// Speech marks, subtitles and failover are not supported for jobs, and no file extension is added to the destination
+// (see TextToSpeechOptions.AddFileExtension), because some providers name the file themselves (see T2SJob.Destination).
+// The returned job handle can be used to request the status of the job (GetT2SJob), to wait for it (WaitForT2SJob)
+// or to cancel it (CancelT2SJob).
+func (a *GoT2SClient) StartT2SJob(ctx context.Context, text string, destination string, options TextToSpeechOptions) (*T2SJob, error) {
+	if (len(options.SpeechMarkTypes) > 0) || (options.Subtitles.Format != SubtitleFormatNone) {
+		return nil, errors.Join(ErrInvalidOptions, errors.New("speech marks and subtitles are not supported for asynchronous synthesis jobs"))
+	}
+	ctx = a.contextWithLogger(ctx)
+	options, err := inferTextType(text, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.Provider == providers.ProviderUnspecified {
+		options, err = a.determineProvider(ctx, options, destination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider, jobProvider, err := a.getJobProviderInstance(options.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.IsURLonOwnStorage(destination) {
+		return nil, errors.Join(ErrInvalidDestination, errors.New(fmt.Sprintf("the destination %s of an asynchronous synthesis job must be on the storage of provider %s", destination, options.Provider)))
+	}
+	text, options, err = a.prepareSynthesis(ctx, provider, text, options)
+	if err != nil {
+		return nil, err
+	}
+	if limit := jobProvider.GetT2SJobTextLengthLimit(); !limit.Fits(text, options.TextType) {
+		return nil, errors.Join(ErrTextTooLong, errors.New(fmt.Sprintf("the text is too long for an asynchronous synthesis job on provider %s (maximum length: %d)", options.Provider, limit.MaxLength)))
+	}
+
+	var job *T2SJob
+	err = Retry(ctx, a.RetryPolicy(), RetryableErrors(provider), func() error {
+		release, acquireErr := a.rateLimiters.Acquire(ctx, options.Provider)
+		if acquireErr != nil {
+			return acquireErr
+		}
+		defer release()
+		var startErr error
+		job, startErr = jobProvider.StartT2SJob(ctx, text, destination, options)
+		return startErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	LoggerFromContext(ctx).Info("Started asynchronous synthesis job", LogKeyProvider, job.Provider, "job", job.Id,
+		LogKeyDestination, job.Destination)
+	return job, nil
+}
+
+// GetT2SJob requests the current status of the given job from its provider and returns the updated job.
+func (a *GoT2SClient) GetT2SJob(ctx context.Context, job T2SJob) (*T2SJob, error) {
+	ctx = a.contextWithLogger(ctx)
+	provider, jobProvider, err := a.getJobProviderInstance(job.Provider)
+	if err != nil {
+		return nil, err
+	}
+	var updated *T2SJob
+	err = Retry(ctx, a.RetryPolicy(), RetryableErrors(provider), func() error {
+		var getErr error
+		updated, getErr = jobProvider.GetT2SJob(ctx, job)
+		return getErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// WaitForT2SJob requests the status of the given job in the given interval until the job is finished, and returns the
+// finished job. If pollInterval is 0 or negative, DefaultT2SJobPollInterval is used.
+// If the job failed or was canceled, the finished job is returned together with an error that wraps ErrT2SJobFailed.
+// If the context is canceled while waiting, the last known state of the job and the context's error are returned.
+// The job itself keeps running in that case.
+func (a *GoT2SClient) WaitForT2SJob(ctx context.Context, job T2SJob, pollInterval time.Duration) (*T2SJob, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultT2SJobPollInterval
+	}
+	current := &job
+	for {
+		updated, err := a.GetT2SJob(ctx, *current)
+		if err != nil {
+			return current, err
+		}
+		current = updated
+		if current.Status.IsFinished() {
+			break
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return current, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if current.Status != T2SJobStatusSucceeded {
+		return current, errors.Join(ErrT2SJobFailed,
+			errors.New(fmt.Sprintf("job %s on provider %s: %s (%s)", current.Id, current.Provider, current.Status, current.StatusReason)))
+	}
+	return current, nil
+}
+
+// CancelT2SJob cancels the given job. Not all providers support this (see ErrT2SJobCancelNotSupported).
+func (a *GoT2SClient) CancelT2SJob(ctx context.Context, job T2SJob) error {
+	ctx = a.contextWithLogger(ctx)
+	_, jobProvider, err := a.getJobProviderInstance(job.Provider)
+	if err != nil {
+		return err
+	}
+	return jobProvider.CancelT2SJob(ctx, job)
+}
+
+// getJobProviderInstance returns the instance of the given provider (see getProviderInstance) and its T2SJobProvider.
+// Returns ErrT2SJobsNotSupported if the provider doesn't support asynchronous synthesis jobs.
+func (a *GoT2SClient) getJobProviderInstance(provider providers.Provider) (T2SProvider, T2SJobProvider, error) {
+	instance, err := a.getProviderInstance(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	jobProvider, ok := instance.(T2SJobProvider)
+	if !ok {
+		return nil, nil, errors.Join(ErrT2SJobsNotSupported, errors.New(fmt.Sprintf("provider %s doesn't support asynchronous synthesis jobs", provider)))
+	}
+	return instance, jobProvider, nil
+}
@@ -0,0 +1,82 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestT2SJob(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	failing := newFakeProvider("FAKE_FAILING")
+	failing.failWith = errFakeSynthesis
+	registerFakeProviders(t, fake, failing)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	options := testOptions()
+	options.Provider = fake.name
+
+	if _, err := client.StartT2SJob(context.Background(), "Hello", "local/audio.mp3", options); err == nil {
+		t.Errorf("Job with destination outside of the provider's storage was started")
+	}
+
+	job, err := client.StartT2SJob(context.Background(), "Hello", "fake://bucket/audio.mp3", options)
+	if err != nil {
+		t.Fatalf("StartT2SJob returned error: %s", err.Error())
+	}
+	if (job.Id != "job-Hello") || (job.VoiceIdConfig.VoiceId != "FAKE-voice") || (job.Status != T2SJobStatusPending) {
+		t.Errorf("Wrong job: %+v", job)
+	}
+	job, err = client.WaitForT2SJob(context.Background(), *job, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForT2SJob returned error: %s", err.Error())
+	}
+	if (job.Status != T2SJobStatusSucceeded) || (job.Progress != 1) || (job.Destination != "fake://bucket/audio.mp3") {
+		t.Errorf("Wrong finished job: %+v", job)
+	}
+	if err = client.CancelT2SJob(context.Background(), *job); !errors.Is(err, ErrT2SJobCancelNotSupported) {
+		t.Errorf("Wrong error when canceling job: %v", err)
+	}
+
+	options.Provider = failing.name
+	job, err = client.StartT2SJob(context.Background(), "Hello", "fake://bucket/audio.mp3", options)
+	if err != nil {
+		t.Fatalf("StartT2SJob returned error: %s", err.Error())
+	}
+	job, err = client.WaitForT2SJob(context.Background(), *job, time.Millisecond)
+	if !errors.Is(err, ErrT2SJobFailed) || (job.Status != T2SJobStatusFailed) || !strings.Contains(err.Error(), errFakeSynthesis.Error()) {
+		t.Errorf("Failed job was not reported: %+v, %v", job, err)
+	}
+}
+
+func TestWaitForT2SJobCanceled(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t, fake)
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	job, err := client.WaitForT2SJob(ctx, T2SJob{Provider: fake.name, Id: "job"}, time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) || (job.Status != T2SJobStatusRunning) {
+		t.Errorf("Waiting was not canceled: %+v, %v", job, err)
+	}
+}
+
+func TestT2SJobNotSupported(t *testing.T) {
+	fake := newFakeProvider("FAKE")
+	registerFakeProviders(t)
+	_ = RegisterProvider(fake.name, func() T2SProvider { return minimalProvider{fake} })
+	t.Cleanup(func() { UnregisterProvider(fake.name) })
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	options := testOptions()
+	options.Provider = fake.name
+
+	if _, err := client.StartT2SJob(context.Background(), "Hello", "fake://bucket/audio.mp3", options); !errors.Is(err, ErrT2SJobsNotSupported) {
+		t.Errorf("Wrong error when starting job on provider without jobs: %v", err)
+	}
+	if err := client.CancelT2SJob(context.Background(), T2SJob{Provider: fake.name}); !errors.Is(err, ErrT2SJobsNotSupported) {
+		t.Errorf("Wrong error when canceling job on provider without jobs: %v", err)
+	}
+}
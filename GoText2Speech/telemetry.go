@@ -0,0 +1,170 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+	"unicode/utf8"
+)
+
+// instrumentationName The name of the tracer and meter of GoT2SClient.
+const instrumentationName = "github.com/FaaSTools/GoText2Speech"
+
+// Names of the metrics that GoT2SClient records (see GoT2SClient.SetMeterProvider).
+const (
+	// MetricCharacters Counts the characters that were sent to the providers (cached audio is not counted).
+	MetricCharacters = "gotext2speech.characters"
+	// MetricAudioBytes Counts the bytes of the synthesized audio that were stored or written.
+	MetricAudioBytes = "gotext2speech.audio.bytes"
+	// MetricSynthesisDuration The duration of the synthesis requests (including retries), in seconds.
+	MetricSynthesisDuration = "gotext2speech.synthesis.duration"
+	// MetricUploadDuration The duration of storing the audio at the destination, in seconds.
+	MetricUploadDuration = "gotext2speech.upload.duration"
+	// MetricErrors Counts the failed syntheses by provider and error type (see ErrorType).
+	MetricErrors = "gotext2speech.errors"
+	// MetricBilledCharacters Counts the characters billed by the providers by tier and tenant (see BillingRecord).
+	MetricBilledCharacters = "gotext2speech.billed.characters"
+	// MetricEstimatedCost Sums up the estimated cost of the syntheses by tier, tenant and currency (see BillingRecord).
+	MetricEstimatedCost = "gotext2speech.estimated.cost"
+)
+
+// Keys of the attributes of spans and metrics, in addition to the keys of the structured log fields (e.g. LogKeyProvider).
+const (
+	AttributeEngine     = "engine"
+	AttributeErrorType  = "error.type"
+	AttributeStorage    = "storage"
+	AttributeCharacters = "characters"
+	AttributeCached     = "cached"
+	AttributeChunks     = "chunks"
+	AttributeTier       = "tier"
+	AttributeTenant     = "tenant"
+	AttributeCurrency   = "currency"
+)
+
+// telemetry contains the tracer and the metric instruments of a GoT2SClient.
+type telemetry struct {
+	tracer            trace.Tracer
+	characters        metric.Int64Counter
+	audioBytes        metric.Int64Counter
+	synthesisDuration metric.Float64Histogram
+	uploadDuration    metric.Float64Histogram
+	errors            metric.Int64Counter
+	billedCharacters  metric.Int64Counter
+	estimatedCost     metric.Float64Counter
+}
+
+// newTelemetry creates the tracer and the metric instruments of the given providers.
+// If an instrument can't be created, the error is returned together with telemetry that uses no-op instruments.
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	meter := meterProvider.Meter(instrumentationName)
+	characters, charactersErr := meter.Int64Counter(MetricCharacters,
+		metric.WithDescription("Characters sent to the providers for synthesis"), metric.WithUnit("{character}"))
+	audioBytes, audioBytesErr := meter.Int64Counter(MetricAudioBytes,
+		metric.WithDescription("Bytes of synthesized audio"), metric.WithUnit("By"))
+	synthesisDuration, synthesisErr := meter.Float64Histogram(MetricSynthesisDuration,
+		metric.WithDescription("Duration of synthesis requests including retries"), metric.WithUnit("s"))
+	uploadDuration, uploadErr := meter.Float64Histogram(MetricUploadDuration,
+		metric.WithDescription("Duration of storing synthesized audio"), metric.WithUnit("s"))
+	errorCount, errorsErr := meter.Int64Counter(MetricErrors,
+		metric.WithDescription("Failed syntheses by error type"), metric.WithUnit("{error}"))
+	billedCharacters, billedErr := meter.Int64Counter(MetricBilledCharacters,
+		metric.WithDescription("Characters billed by the providers"), metric.WithUnit("{character}"))
+	estimatedCost, costErr := meter.Float64Counter(MetricEstimatedCost,
+		metric.WithDescription("Estimated cost of the syntheses according to the price table"))
+
+	err := errors.Join(charactersErr, audioBytesErr, synthesisErr, uploadErr, errorsErr, billedErr, costErr)
+	if err != nil {
+		noopTelemetry, _ := newTelemetry(tracerProvider, noop.NewMeterProvider())
+		return noopTelemetry, errors.Join(errors.New("error while creating metric instruments"), err)
+	}
+	return &telemetry{
+		tracer:            tracerProvider.Tracer(instrumentationName),
+		characters:        characters,
+		audioBytes:        audioBytes,
+		synthesisDuration: synthesisDuration,
+		uploadDuration:    uploadDuration,
+		errors:            errorCount,
+		billedCharacters:  billedCharacters,
+		estimatedCost:     estimatedCost,
+	}, nil
+}
+
+// newNoopTelemetry creates telemetry that neither traces nor records metrics.
+func newNoopTelemetry() *telemetry {
+	noopTelemetry, _ := newTelemetry(trace.NewNoopTracerProvider(), noop.NewMeterProvider())
+	return noopTelemetry
+}
+
+// voiceAttributes returns the provider, voice and engine of the given options as attributes.
+func voiceAttributes(options TextToSpeechOptions) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String(LogKeyProvider, string(options.Provider)),
+		attribute.String(LogKeyVoice, options.VoiceConfig.VoiceIdConfig.VoiceId),
+		attribute.String(AttributeEngine, options.VoiceConfig.VoiceIdConfig.Engine),
+	}
+}
+
+// recordSynthesis records the characters and the duration of a synthesis of the given text.
+func (t *telemetry) recordSynthesis(ctx context.Context, text string, duration time.Duration, options TextToSpeechOptions) {
+	attributes := metric.WithAttributes(voiceAttributes(options)...)
+	t.characters.Add(ctx, int64(utf8.RuneCountInString(text)), attributes)
+	t.synthesisDuration.Record(ctx, duration.Seconds(), attributes)
+}
+
+// recordError records the given error of a synthesis on the given span and in the error count.
+func (t *telemetry) recordError(ctx context.Context, span trace.Span, err error, options TextToSpeechOptions) {
+	endSpanWithError(span, err)
+	t.errors.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(LogKeyProvider, string(options.Provider)),
+		attribute.String(AttributeErrorType, ErrorType(err))))
+}
+
+// endSpanWithError marks the given span as failed, if err is not nil.
+// The span itself is not ended, so that this can be used together with "defer span.End()".
+func endSpanWithError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// errorTypes The error types of ErrorType. The first error that matches determines the type, so more specific errors
+// (e.g. ErrThrottled) come before more general ones (e.g. ErrUploadFailed).
+var errorTypes = []struct {
+	err       error
+	errorType string
+}{
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+	{ErrRateLimitExceeded, "rate_limited"},
+	{ErrThrottled, "throttled"},
+	{ErrAuthentication, "authentication"},
+	{ErrVoiceNotFound, "voice_not_found"},
+	{ErrUnsupportedFormat, "unsupported_format"},
+	{ErrInvalidSSML, "invalid_ssml"},
+	{ErrTextTooLong, "text_too_long"},
+	{ErrInvalidOptions, "invalid_options"},
+	{ErrInvalidDestination, "invalid_destination"},
+	{ErrSourceUnavailable, "source_unavailable"},
+	{ErrProviderUnavailable, "provider_unavailable"},
+	{ErrUploadFailed, "upload_failed"},
+	{ErrSynthesisFailed, "synthesis_failed"},
+}
+
+// ErrorType returns the type of the given error for metrics (e.g. "throttled" for ErrThrottled), based on the
+// errors it wraps. Returns "other" if the error doesn't wrap any of the errors of the error taxonomy.
+func ErrorType(err error) string {
+	for _, t := range errorTypes {
+		if errors.Is(err, t.err) {
+			return t.errorType
+		}
+	}
+	return "other"
+}
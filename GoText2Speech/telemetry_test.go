@@ -0,0 +1,157 @@
+package GoText2Speech
+
+import (
+	"context"
+	"errors"
+	. "github.com/FaaSTools/GoText2Speech/GoText2Speech/shared"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"path/filepath"
+	"testing"
+)
+
+// newTelemetryTestClient creates a client whose spans and metrics are recorded in memory.
+func newTelemetryTestClient(t *testing.T) (*GoT2SClient, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	client := CreateGoT2SClient(&CredentialsHolder{}, "us-east-1")
+	client.SetRetryPolicy(NoRetryPolicy())
+	if err := client.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))); err != nil {
+		t.Fatalf("SetTracerProvider returned error: %s", err.Error())
+	}
+	if err := client.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))); err != nil {
+		t.Fatalf("SetMeterProvider returned error: %s", err.Error())
+	}
+	return client, exporter, reader
+}
+
+// collectMetrics returns the metrics of the given reader by name.
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	var resourceMetrics metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &resourceMetrics); err != nil {
+		t.Fatalf("Collect returned error: %s", err.Error())
+	}
+	metrics := make(map[string]metricdata.Metrics)
+	for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+// sumValue returns the sum of the data points of the given counter that have the given attribute.
+func sumValue(m metricdata.Metrics, want attribute.KeyValue) int64 {
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if !ok {
+		return 0
+	}
+	var value int64
+	for _, dataPoint := range sum.DataPoints {
+		if v, ok := dataPoint.Attributes.Value(want.Key); ok && (v == want.Value) {
+			value += dataPoint.Value
+		}
+	}
+	return value
+}
+
+func TestTelemetryOfT2SDirect(t *testing.T) {
+	registerFakeProviders(t, newFakeProvider("FAKE"))
+	client, exporter, reader := newTelemetryTestClient(t)
+	destination := filepath.Join(t.TempDir(), "audio.mp3")
+
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", destination, testOptions()); err != nil {
+		t.Fatalf("T2SDirectWithResult returned error: %s", err.Error())
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	for _, name := range []string{"T2SDirect", "SelectProvider", "FindVoice", "TransformOptions", "Synthesize", "Upload"} {
+		if _, ok := spans[name]; !ok {
+			t.Errorf("Span %s was not recorded: %v", name, spans)
+		}
+	}
+	root := spans["T2SDirect"]
+	if synthesize := spans["Synthesize"]; synthesize.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Errorf("Synthesize span is not a child of the T2SDirect span")
+	}
+	if !hasAttribute(root.Attributes, attribute.String(LogKeyVoice, "FAKE-voice")) {
+		t.Errorf("T2SDirect span doesn't have the voice: %v", root.Attributes)
+	}
+
+	metrics := collectMetrics(t, reader)
+	provider := attribute.String(LogKeyProvider, "FAKE")
+	if characters := sumValue(metrics[MetricCharacters], provider); characters != 5 {
+		t.Errorf("Recorded %d characters, wanted 5", characters)
+	}
+	if audioBytes := sumValue(metrics[MetricAudioBytes], provider); audioBytes != 5 {
+		t.Errorf("Recorded %d audio bytes, wanted 5", audioBytes)
+	}
+	for _, name := range []string{MetricSynthesisDuration, MetricUploadDuration} {
+		histogram, ok := metrics[name].Data.(metricdata.Histogram[float64])
+		if !ok || (len(histogram.DataPoints) != 1) || (histogram.DataPoints[0].Count != 1) {
+			t.Errorf("Histogram %s doesn't contain one measurement: %+v", name, metrics[name].Data)
+		}
+	}
+	if _, ok := metrics[MetricErrors]; ok {
+		t.Errorf("Errors were recorded for a successful synthesis")
+	}
+}
+
+func TestTelemetryOfFailedSynthesis(t *testing.T) {
+	failing := newFakeProvider("FAKE")
+	failing.failWith = errors.Join(ErrThrottled, errFakeThrottling)
+	registerFakeProviders(t, failing)
+	client, exporter, reader := newTelemetryTestClient(t)
+
+	options := testOptions()
+	options.Provider = failing.name
+	if _, err := client.T2SDirectWithResult(context.Background(), "Hello", filepath.Join(t.TempDir(), "audio.mp3"), options); err == nil {
+		t.Fatalf("T2SDirectWithResult didn't return the error of the provider")
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if (span.Name == "T2SDirect") && (span.Status.Code != codes.Error) {
+			t.Errorf("T2SDirect span doesn't have error status: %v", span.Status)
+		}
+	}
+	metrics := collectMetrics(t, reader)
+	if errorCount := sumValue(metrics[MetricErrors], attribute.String(AttributeErrorType, "throttled")); errorCount != 1 {
+		t.Errorf("Recorded %d throttling errors, wanted 1", errorCount)
+	}
+	if characters := sumValue(metrics[MetricCharacters], attribute.String(LogKeyProvider, "FAKE")); characters != 0 {
+		t.Errorf("Recorded %d characters for a failed synthesis, wanted 0", characters)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.Join(errors.New("error while uploading"), ErrUploadFailed, ErrThrottled), "throttled"},
+		{errors.Join(errors.New("error while synthesizing"), context.DeadlineExceeded), "deadline_exceeded"},
+		{ErrVoiceNotFound, "voice_not_found"},
+		{errors.New("unknown"), "other"},
+	}
+	for _, test := range tests {
+		if got := ErrorType(test.err); got != test.want {
+			t.Errorf("ErrorType(%v) returned %s, wanted %s", test.err, got, test.want)
+		}
+	}
+}
+
+func hasAttribute(attributes []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, a := range attributes {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
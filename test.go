@@ -26,12 +26,12 @@ func main() {
 	bucket := "YOUR_BUCKET_HERE"
 
 	var err error = nil
-	t2sClient, err = t2sClient.T2SDirect("<speak><prosody volume=\"10.000dB\">Hello World, how are you today? Lovely day, isn't it?</prosody></speak>", "s3://"+bucket+"/testfile.mp3", *options)
-	t2sClient, err = t2sClient.T2SDirect("Test", "s3://"+bucket+"/testfile_02.mp3", *options)
-	t2sClient, err = t2sClient.T2S("https://www.davemeyer.io/GoSpeechLess/T2S_Test_file_01.txt", "s3://"+bucket+"/testfile_03.mp3", *options)
+	err = t2sClient.T2SDirect("<speak><prosody volume=\"10.000dB\">Hello World, how are you today? Lovely day, isn't it?</prosody></speak>", "s3://"+bucket+"/testfile.mp3", *options)
+	err = t2sClient.T2SDirect("Test", "s3://"+bucket+"/testfile_02.mp3", *options)
+	err = t2sClient.T2S("https://www.davemeyer.io/GoSpeechLess/T2S_Test_file_01.txt", "s3://"+bucket+"/testfile_03.mp3", *options)
 
 	t2sClient.SetTempBucket(providers.ProviderAWS, bucket)
-	t2sClient, err = t2sClient.T2S("https://"+bucket+".s3.amazonaws.com/T2S_Test_file_01.txt", "D:\\testfile_04.mp3", *options)
+	err = t2sClient.T2S("https://"+bucket+".s3.amazonaws.com/T2S_Test_file_01.txt", "D:\\testfile_04.mp3", *options)
 
 	err = t2sClient.CloseAllProviderClients()
 